@@ -30,10 +30,16 @@ import (
 	authTypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	"github.com/cosmos/go-bip39"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
 
 	"github.com/hyperledger-labs/yui-relayer/core"
 	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"github.com/hyperledger-labs/yui-relayer/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
 )
 
 var (
@@ -54,6 +60,7 @@ type Chain struct {
 	Client   rpcclient.Client `yaml:"-" json:"-"`
 
 	codec            codec.ProtoCodecMarshaler `yaml:"-" json:"-"`
+	grpcConn         *grpc.ClientConn
 	msgEventListener core.MsgEventListener
 
 	timeout time.Duration
@@ -81,6 +88,13 @@ func (c *Chain) Codec() codec.ProtoCodecMarshaler {
 	return c.codec
 }
 
+var _ core.AccountPrefixer = (*Chain)(nil)
+
+// AccountPrefix returns the bech32 prefix of the account addresses on the chain
+func (c *Chain) AccountPrefix() string {
+	return c.config.AccountPrefix
+}
+
 // GetAddress returns the sdk.AccAddress associated with the configred key
 func (c *Chain) GetAddress() (sdk.AccAddress, error) {
 	defer c.UseSDKContext()()
@@ -118,9 +132,28 @@ func (c *Chain) Init(homePath string, timeout time.Duration, codec codec.ProtoCo
 		return err
 	}
 
-	client, err := newRPCClient(c.config.RpcAddr, timeout)
-	if err != nil {
-		return err
+	var client rpcclient.Client
+	if len(c.config.RpcAddrs) == 0 {
+		if client, err = newRPCClient(c.config.RpcAddr, timeout); err != nil {
+			return err
+		}
+	} else {
+		addrs := append([]string{c.config.RpcAddr}, c.config.RpcAddrs...)
+		clients := make([]rpcclient.Client, len(addrs))
+		for i, addr := range addrs {
+			if clients[i], err = newRPCClient(addr, timeout); err != nil {
+				return err
+			}
+		}
+		// an endpoint is stale if no block has been produced for 10 times the average block time
+		client = newFailoverRPCClient(c.config.ChainId, addrs, clients, c.config.SpreadQueries, 10*c.AverageBlockTime())
+	}
+
+	var grpcConn *grpc.ClientConn
+	if len(c.config.GrpcAddr) > 0 {
+		if grpcConn, err = newGRPCConn(c.config.GrpcAddr, c.config.ChainId, codec.InterfaceRegistry()); err != nil {
+			return err
+		}
 	}
 
 	_, err = sdk.ParseDecCoins(c.config.GasPrices)
@@ -129,7 +162,8 @@ func (c *Chain) Init(homePath string, timeout time.Duration, codec codec.ProtoCo
 	}
 
 	c.Keybase = keybase
-	c.Client = client
+	c.Client = newMetricsRPCClient(client, c.config.ChainId)
+	c.grpcConn = grpcConn
 	c.HomePath = homePath
 	c.codec = codec
 	c.timeout = timeout
@@ -148,7 +182,7 @@ func (c *Chain) LatestHeight(ctx context.Context) (ibcexported.Height, error) {
 	if err != nil {
 		return nil, err
 	} else if res.SyncInfo.CatchingUp {
-		return nil, fmt.Errorf("node at %s running chain %s not caught up", c.config.RpcAddr, c.ChainID())
+		return nil, fmt.Errorf("node at %s running chain %s not caught up", c.RPCAddr(), c.ChainID())
 	}
 	version := clienttypes.ParseChainID(c.ChainID())
 	return clienttypes.NewHeight(version, uint64(res.SyncInfo.LatestBlockHeight)), nil
@@ -172,74 +206,126 @@ func (c *Chain) RegisterMsgEventListener(listener core.MsgEventListener) {
 	c.msgEventListener = listener
 }
 
-func (c *Chain) sendMsgs(ctx context.Context, msgs []sdk.Msg) (*sdk.TxResponse, error) {
-	logger := GetChainLogger()
+// sendMsgs sends `msgs` in a tx and waits for it to be committed.
+// It returns the cost of the tx even with an error if the tx was committed.
+func (c *Chain) sendMsgs(ctx context.Context, msgs []sdk.Msg) (*sdk.TxResponse, *core.TxCost, error) {
+	logger := GetChainLogger().WithSpanContext(ctx)
 	// broadcast tx
 	res, _, err := c.rawSendMsgs(ctx, msgs)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	} else if res.Code != 0 {
 		// CheckTx failed
-		return nil, fmt.Errorf("CheckTx failed: %v", errors.ABCIError(res.Codespace, res.Code, res.RawLog))
+		return nil, nil, core.NewTxError(core.TxFailureReasonCheckTx, fmt.Errorf("CheckTx failed: %v", errors.ABCIError(res.Codespace, res.Code, res.RawLog)))
 	}
 
 	// wait for tx being committed
-	if resTx, err := c.waitForCommit(ctx, res.TxHash); err != nil {
-		return nil, err
-	} else if resTx.TxResult.IsErr() {
+	resTx, err := c.waitForCommit(ctx, res.TxHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	// gas and fees are consumed even if DeliverTx fails
+	cost := c.txCost(resTx)
+	c.updateTxResultMetrics(ctx, cost)
+	if resTx.TxResult.IsErr() {
 		// DeliverTx failed
-		return nil, fmt.Errorf("DeliverTx failed: %v", errors.ABCIError(res.Codespace, res.Code, res.RawLog))
+		return nil, &cost, core.NewTxError(core.TxFailureReasonDeliverTx, fmt.Errorf("DeliverTx failed: %v", errors.ABCIError(resTx.TxResult.Codespace, resTx.TxResult.Code, resTx.TxResult.Log)))
 	}
+	c.recordRedundantRelays(ctx, resTx, msgs)
 
 	// call msgEventListener if needed
 	if c.msgEventListener != nil {
 		if err := c.msgEventListener.OnSentMsg(ctx, msgs); err != nil {
 			logger.Error("failed to OnSendMsg call", err)
-			return res, nil
+			return res, &cost, nil
 		}
 	}
 
-	return res, nil
+	return res, &cost, nil
 }
 
-func (c *Chain) rawSendMsgs(ctx context.Context, msgs []sdk.Msg) (*sdk.TxResponse, bool, error) {
-	// Instantiate the client context
-	// NOTE: Although cosmos-sdk does not currently use CmdContext in Context.QueryWithData,
-	//   set ctx to clientCtx in case cosmos-sdk uses it in the future.
-	//   (cf. https://github.com/cosmos/cosmos-sdk/blob/v0.50.5/client/query.go#L98, https://github.com/cosmos/cosmos-sdk/blob/v0.50.5/x/auth/types/account_retriever.go#L39, etc.)
-	clientCtx := c.CLIContext(0).WithCmdContext(ctx)
-
-	// Query account details
-	txf, err := prepareFactory(clientCtx, c.TxFactory(0))
-	if err != nil {
-		return nil, false, err
+// recordRedundantRelays counts the packet msgs in the committed tx that were no-ops because the packets had already been relayed
+func (c *Chain) recordRedundantRelays(ctx context.Context, resTx *coretypes.ResultTx, msgs []sdk.Msg) {
+	var txMsgData sdk.TxMsgData
+	if err := txMsgData.Unmarshal(resTx.TxResult.Data); err != nil {
+		GetChainLogger().Error("failed to unmarshal the tx msg data", err, "tx_hash", resTx.Hash.String())
+		return
 	}
-
-	// TODO: Make this work with new CalculateGas method
-	// https://github.com/cosmos/cosmos-sdk/blob/5725659684fc93790a63981c653feee33ecf3225/client/tx/tx.go#L297
-	// If users pass gas adjustment, then calculate gas
-	_, adjusted, err := CalculateGas(clientCtx.QueryWithData, txf, msgs...)
-	if err != nil {
-		return nil, false, err
+	var redundant []sdk.Msg
+	for i, res := range txMsgData.MsgResponses {
+		if i >= len(msgs) {
+			break
+		}
+		var result chantypes.ResponseResultType
+		switch res.TypeUrl {
+		case sdk.MsgTypeURL(&chantypes.MsgRecvPacketResponse{}):
+			var r chantypes.MsgRecvPacketResponse
+			if err := r.Unmarshal(res.Value); err != nil {
+				continue
+			}
+			result = r.Result
+		case sdk.MsgTypeURL(&chantypes.MsgAcknowledgementResponse{}):
+			var r chantypes.MsgAcknowledgementResponse
+			if err := r.Unmarshal(res.Value); err != nil {
+				continue
+			}
+			result = r.Result
+		case sdk.MsgTypeURL(&chantypes.MsgTimeoutResponse{}):
+			var r chantypes.MsgTimeoutResponse
+			if err := r.Unmarshal(res.Value); err != nil {
+				continue
+			}
+			result = r.Result
+		default:
+			continue
+		}
+		if result == chantypes.NOOP {
+			redundant = append(redundant, msgs[i])
+		}
 	}
+	if len(redundant) > 0 {
+		GetChainLogger().WithSpanContext(ctx).Info("packet msgs were no-ops because the packets had already been relayed", "num_msgs", len(redundant), "tx_hash", resTx.Hash.String())
+		core.RecordRedundantRelays(ctx, c, redundant)
+	}
+}
 
-	// Set the gas amount on the transaction factory
-	txf = txf.WithGas(adjusted)
-
-	// Build the transaction builder
-	txb, err := txf.BuildUnsignedTx(msgs...)
-	if err != nil {
-		return nil, false, err
+// txCost returns the gas used and the fees paid by the committed tx
+func (c *Chain) txCost(resTx *coretypes.ResultTx) core.TxCost {
+	cost := core.TxCost{GasUsed: uint64(resTx.TxResult.GasUsed)}
+	for _, event := range resTx.TxResult.Events {
+		if event.Type != sdk.EventTypeTx {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if attr.Key != sdk.AttributeKeyFee || attr.Value == "" {
+				continue
+			}
+			fees, err := sdk.ParseCoinsNormalized(attr.Value)
+			if err != nil {
+				GetChainLogger().Error("failed to parse fees", err, "fee", attr.Value)
+				continue
+			}
+			cost.FeesPaid = cost.FeesPaid.Add(fees...)
+		}
 	}
+	return cost
+}
 
-	// Attach the signature to the transaction
-	err = tx.Sign(ctx, txf, c.config.Key, txb, false)
-	if err != nil {
-		return nil, false, err
+// updateTxResultMetrics records the gas used and the fees paid by the committed tx
+func (c *Chain) updateTxResultMetrics(ctx context.Context, cost core.TxCost) {
+	chainIDAttr := attribute.Key("chain_id").String(c.ChainID())
+
+	metrics.GasUsedCounter.Add(ctx, int64(cost.GasUsed), api.WithAttributes(chainIDAttr))
+	for _, fee := range cost.FeesPaid {
+		if !fee.Amount.IsInt64() {
+			continue
+		}
+		metrics.FeesPaidCounter.Add(ctx, fee.Amount.Int64(), api.WithAttributes(chainIDAttr, attribute.Key("denom").String(fee.Denom)))
 	}
+}
 
-	// Generate the transaction bytes
-	txBytes, err := clientCtx.TxConfig.TxEncoder()(txb.GetTx())
+func (c *Chain) rawSendMsgs(ctx context.Context, msgs []sdk.Msg) (*sdk.TxResponse, bool, error) {
+	clientCtx, txBytes, err := c.buildTx(ctx, msgs)
 	if err != nil {
 		return nil, false, err
 	}
@@ -262,8 +348,18 @@ func (c *Chain) rawSendMsgs(ctx context.Context, msgs []sdk.Msg) (*sdk.TxRespons
 	return res, true, nil
 }
 
-func (c *Chain) waitForCommit(ctx context.Context, txHash string) (*coretypes.ResultTx, error) {
-	var resTx *coretypes.ResultTx
+func (c *Chain) waitForCommit(ctx context.Context, txHash string) (_ *coretypes.ResultTx, err error) {
+	ctx, span := tracing.StartSpan(ctx, "waitForCommit",
+		attribute.String("chain_id", c.ChainID()),
+		attribute.String("tx_hash", txHash),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	var (
+		resTx *coretypes.ResultTx
+		// rpcFailed is set if the last attempt failed due to an RPC error rather than the tx not being committed yet
+		rpcFailed bool
+	)
 
 	retryInterval := c.AverageBlockTime()
 	maxRetry := uint(c.config.MaxRetryForCommit)
@@ -273,6 +369,7 @@ func (c *Chain) waitForCommit(ctx context.Context, txHash string) (*coretypes.Re
 		var recoverable bool
 		resTx, recoverable, err = c.rawQueryTx(ctx, txHash)
 		if err != nil {
+			rpcFailed = !isTxNotFoundError(err)
 			if recoverable {
 				return err
 			} else {
@@ -284,18 +381,38 @@ func (c *Chain) waitForCommit(ctx context.Context, txHash string) (*coretypes.Re
 		// In order to make the proof of the state updated by a tx available just after `sendMsgs`,
 		// `waitForCommit` must wait until the latest height is greater than the tx height.
 		if height, err := c.LatestHeight(ctx); err != nil {
+			rpcFailed = true
 			return fmt.Errorf("failed to obtain latest height: %v", err)
 		} else if height.GetRevisionHeight() <= uint64(resTx.Height) {
+			rpcFailed = false
 			return fmt.Errorf("latest_height(%v) is less than or equal to tx_height(%v) yet", height, resTx.Height)
 		}
 		return nil
 	}, retry.Context(ctx), retry.Attempts(maxRetry), retry.Delay(retryInterval), rtyErr); err != nil {
-		return resTx, fmt.Errorf("failed to make sure that tx is committed: %v", err)
+		return resTx, newCommitError(ctx, rpcFailed, fmt.Errorf("failed to make sure that tx is committed: %v", err))
 	}
 
 	return resTx, nil
 }
 
+// newCommitError returns a TxError of `err` returned while waiting for a tx to be committed.
+// The reason is "canceled" if `ctx` is done, "rpc_error" if the last attempt failed due to an RPC error, and "commit_timeout" otherwise.
+func newCommitError(ctx context.Context, rpcFailed bool, err error) *core.TxError {
+	switch {
+	case ctx.Err() != nil:
+		return core.NewTxError(core.TxFailureReasonCanceled, err)
+	case rpcFailed:
+		return core.NewTxError(core.TxFailureReasonRPC, err)
+	default:
+		return core.NewTxError(core.TxFailureReasonCommitTimeout, err)
+	}
+}
+
+// isTxNotFoundError returns true if `err` is returned by the `tx` RPC because the tx has not been committed yet
+func isTxNotFoundError(err error) bool {
+	return strings.Contains(err.Error(), "not found")
+}
+
 // rawQueryTx returns a tx of which hash equals to `hexTxHash`.
 // If the RPC is successful but the tx is not found, this returns nil with nil error.
 func (c *Chain) rawQueryTx(ctx context.Context, hexTxHash string) (*coretypes.ResultTx, bool, error) {
@@ -320,30 +437,97 @@ func (c *Chain) rawQueryTx(ctx context.Context, hexTxHash string) (*coretypes.Re
 	return resTx, false, nil
 }
 
-func prepareFactory(clientCtx sdkCtx.Context, txf tx.Factory) (tx.Factory, error) {
-	from := clientCtx.GetFromAddress()
+// prepareFactory sets the account number and the sequence of `from` to `txf`.
+// `from` is the Bech32 address of the sender, which is encoded beforehand
+// so that the account is queried without holding the process-global SDK context.
+func prepareFactory(clientCtx sdkCtx.Context, txf tx.Factory, from string) (tx.Factory, error) {
+	initNum, initSeq := txf.AccountNumber(), txf.Sequence()
+	if initNum != 0 && initSeq != 0 {
+		return txf, nil
+	}
+
+	res, err := authTypes.NewQueryClient(clientCtx).Account(clientCtx.CmdContext, &authTypes.QueryAccountRequest{Address: from})
+	if err != nil {
+		return txf, fmt.Errorf("failed to query the account %s: %v", from, err)
+	}
+	var account sdk.AccountI
+	if err := clientCtx.InterfaceRegistry.UnpackAny(res.Account, &account); err != nil {
+		return txf, fmt.Errorf("failed to unpack the account %s: %v", from, err)
+	}
+
+	if initNum == 0 {
+		txf = txf.WithAccountNumber(account.GetAccountNumber())
+	}
+	if initSeq == 0 {
+		txf = txf.WithSequence(account.GetSequence())
+	}
+	return txf, nil
+}
+
+// fromAddress returns the Bech32 address of the relayer account, which is encoded with the prefix of the chain
+func (c *Chain) fromAddress(clientCtx sdkCtx.Context) string {
+	defer c.UseSDKContext()()
+	return clientCtx.GetFromAddress().String()
+}
+
+// buildSimTx builds the tx to simulate `msgs` while holding the SDK context
+func (c *Chain) buildSimTx(txf tx.Factory, msgs []sdk.Msg) ([]byte, error) {
+	defer c.UseSDKContext()()
+	return BuildSimTx(txf, msgs...)
+}
+
+// buildTx builds and signs a tx including `msgs`, and returns it with the client context to broadcast it.
+// The SDK context with the Bech32 prefix of the chain is process-global, so it is held only while encoding addresses
+// and building and signing the tx, and never across the queries of the account and the simulation,
+// so that the txs to the chains with different prefixes are built concurrently.
+func (c *Chain) buildTx(ctx context.Context, msgs []sdk.Msg) (sdkCtx.Context, []byte, error) {
+	// Instantiate the client context
+	// NOTE: Although cosmos-sdk does not currently use CmdContext in Context.QueryWithData,
+	//   set ctx to clientCtx in case cosmos-sdk uses it in the future.
+	//   (cf. https://github.com/cosmos/cosmos-sdk/blob/v0.50.5/client/query.go#L98, https://github.com/cosmos/cosmos-sdk/blob/v0.50.5/x/auth/types/account_retriever.go#L39, etc.)
+	clientCtx := c.CLIContext(0).WithCmdContext(ctx)
+	txf := c.TxFactory(0)
 
-	if err := txf.AccountRetriever().EnsureExists(clientCtx, from); err != nil {
-		return txf, err
+	// Query account details
+	txf, err := prepareFactory(clientCtx, txf, c.fromAddress(clientCtx))
+	if err != nil {
+		return clientCtx, nil, err
 	}
 
-	initNum, initSeq := txf.AccountNumber(), txf.Sequence()
-	if initNum == 0 || initSeq == 0 {
-		num, seq, err := txf.AccountRetriever().GetAccountNumberSequence(clientCtx, from)
-		if err != nil {
-			return txf, err
-		}
+	// TODO: Make this work with new CalculateGas method
+	// https://github.com/cosmos/cosmos-sdk/blob/5725659684fc93790a63981c653feee33ecf3225/client/tx/tx.go#L297
+	// If users pass gas adjustment, then calculate gas
+	simTx, err := c.buildSimTx(txf, msgs)
+	if err != nil {
+		return clientCtx, nil, err
+	}
+	_, adjusted, err := simulateGas(clientCtx.QueryWithData, txf, simTx)
+	if err != nil {
+		return clientCtx, nil, err
+	}
 
-		if initNum == 0 {
-			txf = txf.WithAccountNumber(num)
-		}
+	// Set the gas amount on the transaction factory
+	txf = txf.WithGas(adjusted)
 
-		if initSeq == 0 {
-			txf = txf.WithSequence(seq)
-		}
+	defer c.UseSDKContext()()
+
+	// Build the transaction builder
+	txb, err := txf.BuildUnsignedTx(msgs...)
+	if err != nil {
+		return clientCtx, nil, err
 	}
 
-	return txf, nil
+	// Attach the signature to the transaction
+	if err := tx.Sign(ctx, txf, c.config.Key, txb, false); err != nil {
+		return clientCtx, nil, err
+	}
+
+	// Generate the transaction bytes
+	txBytes, err := clientCtx.TxConfig.TxEncoder()(txb.GetTx())
+	if err != nil {
+		return clientCtx, nil, err
+	}
+	return clientCtx, txBytes, nil
 }
 
 // protoTxProvider is a type which can provide a proto transaction. It is a
@@ -392,7 +576,13 @@ func CalculateGas(
 	if err != nil {
 		return txtypes.SimulateResponse{}, 0, err
 	}
+	return simulateGas(queryFunc, txf, txBytes)
+}
 
+// simulateGas simulates the tx built by BuildSimTx and returns the simulation response and the adjusted gas amount
+func simulateGas(
+	queryFunc func(string, []byte) ([]byte, int64, error), txf tx.Factory, txBytes []byte,
+) (txtypes.SimulateResponse, uint64, error) {
 	bz, _, err := queryFunc("/cosmos.tx.v1beta1.Service/Simulate", txBytes)
 	if err != nil {
 		return txtypes.SimulateResponse{}, 0, err
@@ -407,11 +597,42 @@ func CalculateGas(
 	return simRes, uint64(txf.GasAdjustment() * float64(simRes.GasInfo.GasUsed)), nil
 }
 
+var _ core.GasEstimator = (*Chain)(nil)
+
+// EstimateGas implements core.GasEstimator
+// The tx is simulated as it is built by SendMsgs, without holding the SDK context across the queries.
+func (c *Chain) EstimateGas(ctx context.Context, msgs []sdk.Msg) (uint64, error) {
+	clientCtx := c.CLIContext(0).WithCmdContext(ctx)
+	txf := c.TxFactory(0)
+
+	txf, err := prepareFactory(clientCtx, txf, c.fromAddress(clientCtx))
+	if err != nil {
+		return 0, err
+	}
+	simTx, err := c.buildSimTx(txf, msgs)
+	if err != nil {
+		return 0, err
+	}
+	_, adjusted, err := simulateGas(clientCtx.QueryWithData, txf, simTx)
+	if err != nil {
+		return 0, err
+	}
+	return adjusted, nil
+}
+
 func (c *Chain) SendMsgs(ctx context.Context, msgs []sdk.Msg) ([]core.MsgID, error) {
+	msgIDs, _, err := c.SendMsgsWithCost(ctx, msgs)
+	return msgIDs, err
+}
+
+var _ core.TxCostReporter = (*Chain)(nil)
+
+// SendMsgsWithCost implements core.TxCostReporter
+func (c *Chain) SendMsgsWithCost(ctx context.Context, msgs []sdk.Msg) ([]core.MsgID, *core.TxCost, error) {
 	// Broadcast those bytes
-	res, err := c.sendMsgs(ctx, msgs)
+	res, cost, err := c.sendMsgs(ctx, msgs)
 	if err != nil {
-		return nil, err
+		return nil, cost, err
 	}
 	var msgIDs []core.MsgID
 	for msgIndex := range msgs {
@@ -420,7 +641,7 @@ func (c *Chain) SendMsgs(ctx context.Context, msgs []sdk.Msg) ([]core.MsgID, err
 			MsgIndex: uint32(msgIndex),
 		})
 	}
-	return msgIDs, nil
+	return msgIDs, cost, nil
 }
 
 func (c *Chain) GetMsgResult(ctx context.Context, id core.MsgID) (core.MsgResult, error) {
@@ -509,6 +730,16 @@ func (c *Chain) UseSDKContext() func() {
 	return sdkContextMutex.Unlock
 }
 
+// RPCAddr returns the address of the RPC endpoint in use, which may be one of `rpc_addrs` after a failover
+func (c *Chain) RPCAddr() string {
+	if client, ok := c.Client.(*metricsRPCClient); ok {
+		if failover, ok := client.Client.(*failoverRPCClient); ok {
+			return failover.Addr()
+		}
+	}
+	return c.config.RpcAddr
+}
+
 // CLIContext returns an instance of client.Context derived from Chain
 func (c *Chain) CLIContext(height int64) sdkCtx.Context {
 	unlock := c.UseSDKContext()
@@ -520,7 +751,7 @@ func (c *Chain) CLIContext(height int64) sdkCtx.Context {
 		WithInterfaceRegistry(c.codec.InterfaceRegistry()).
 		WithTxConfig(txConfig).
 		WithInput(os.Stdin).
-		WithNodeURI(c.config.RpcAddr).
+		WithNodeURI(c.RPCAddr()).
 		WithClient(c.Client).
 		WithAccountRetriever(authTypes.AccountRetriever{}).
 		WithBroadcastMode(flags.BroadcastSync).
@@ -530,7 +761,7 @@ func (c *Chain) CLIContext(height int64) sdkCtx.Context {
 		WithFromName(c.config.Key).
 		WithFromAddress(c.MustGetAddress()).
 		WithSkipConfirmation(true).
-		WithNodeURI(c.config.RpcAddr).
+		WithNodeURI(c.RPCAddr()).
 		WithHeight(height)
 }
 
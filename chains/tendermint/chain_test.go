@@ -0,0 +1,207 @@
+package tendermint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	keys "github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+func TestNewCommitError(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	cases := []struct {
+		name      string
+		ctx       context.Context
+		rpcFailed bool
+		expected  core.TxFailureReason
+	}{
+		{"not committed", context.TODO(), false, core.TxFailureReasonCommitTimeout},
+		{"rpc error", context.TODO(), true, core.TxFailureReasonRPC},
+		{"canceled", canceled, true, core.TxFailureReasonCanceled},
+	}
+	for _, c := range cases {
+		err := newCommitError(c.ctx, c.rpcFailed, fmt.Errorf("failed to make sure that tx is committed"))
+		if actual := core.GetTxFailureReason(err); actual != c.expected {
+			t.Errorf("%s: unexpected reason: actual=%s, expected=%s", c.name, actual, c.expected)
+		}
+	}
+}
+
+func TestIsTxNotFoundError(t *testing.T) {
+	if !isTxNotFoundError(fmt.Errorf("failed to retrieve tx: RPC error -32603 - Internal error: tx (0A1B) not found")) {
+		t.Error("a tx not found is expected to be detected")
+	}
+	if isTxNotFoundError(fmt.Errorf("failed to retrieve tx: post failed: connection refused")) {
+		t.Error("a connection error is not expected to be detected as a tx not found")
+	}
+}
+
+// txRPCStub is a stub of the RPC client that serves the queries to build txs and accepts any tx broadcast.
+// It rejects the account queries with the addresses not encoded with `prefix`.
+// If `simulating` is not nil, the simulations are notified to it and blocked until `release` is closed.
+type txRPCStub struct {
+	rpcclient.Client
+	prefix     string
+	simulating chan struct{}
+	release    chan struct{}
+}
+
+func (s *txRPCStub) ABCIQueryWithOptions(ctx context.Context, path string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (*coretypes.ResultABCIQuery, error) {
+	var (
+		value []byte
+		err   error
+	)
+	switch path {
+	case "/cosmos.auth.v1beta1.Query/Account":
+		var req authtypes.QueryAccountRequest
+		if err := req.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(req.Address, s.prefix+"1") {
+			return &coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Code: 1, Log: fmt.Sprintf("unexpected address for %s: %s", s.prefix, req.Address)}}, nil
+		}
+		account, err := codectypes.NewAnyWithValue(&authtypes.BaseAccount{Address: req.Address, AccountNumber: 1, Sequence: 1})
+		if err != nil {
+			return nil, err
+		}
+		value, err = (&authtypes.QueryAccountResponse{Account: account}).Marshal()
+	case "/cosmos.tx.v1beta1.Service/Simulate":
+		if s.simulating != nil {
+			s.simulating <- struct{}{}
+			<-s.release
+		}
+		value, err = (&txtypes.SimulateResponse{GasInfo: &sdk.GasInfo{GasUsed: 100000}}).Marshal()
+	default:
+		return nil, fmt.Errorf("unexpected query: %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: value, Height: 1}}, nil
+}
+
+func (s *txRPCStub) BroadcastTxSync(ctx context.Context, tx tmtypes.Tx) (*coretypes.ResultBroadcastTx, error) {
+	return &coretypes.ResultBroadcastTx{Hash: tx.Hash()}, nil
+}
+
+func newTxTestChain(t *testing.T, chainID, prefix string) *Chain {
+	cdc := core.MakeCodec()
+	kr := keys.NewInMemory(cdc)
+	if _, _, err := kr.NewMnemonic("relayer", keys.English, sdk.FullFundraiserPath, keys.DefaultBIP39Passphrase, hd.Secp256k1); err != nil {
+		t.Fatal(err)
+	}
+	return &Chain{
+		config:  ChainConfig{ChainId: chainID, AccountPrefix: prefix, Key: "relayer", GasAdjustment: 1.0, GasPrices: "0stake"},
+		Keybase: kr,
+		Client:  &txRPCStub{prefix: prefix},
+		codec:   cdc,
+	}
+}
+
+// TestBuildTxConcurrently builds txs to the chains with different Bech32 prefixes concurrently as RelayMsgs.Send does.
+// It is expected to be run with -race since the prefix is process-global.
+func TestBuildTxConcurrently(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	// the address strings are not cached so that they are encoded with the current prefix every time
+	sdk.SetAddrCacheEnabled(false)
+	t.Cleanup(func() { sdk.SetAddrCacheEnabled(true) })
+
+	chains := []*Chain{newTxTestChain(t, "ibc0", "cosmos"), newTxTestChain(t, "ibc1", "osmo")}
+	errs := make(chan error, 2*len(chains))
+	var wg sync.WaitGroup
+	for _, chain := range chains {
+		addr := chain.MustGetAddress()
+		signer, err := sdk.Bech32ifyAddressBytes(chain.config.AccountPrefix, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				msg := &clienttypes.MsgUpdateClient{ClientId: "07-tendermint-0", Signer: signer}
+				if _, ok, err := chain.rawSendMsgs(context.TODO(), []sdk.Msg{msg}); err != nil {
+					errs <- fmt.Errorf("%s: %v", chain.ChainID(), err)
+					return
+				} else if !ok {
+					errs <- fmt.Errorf("%s: tx failed", chain.ChainID())
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// TestBuildTxWithoutLockingQueries builds a tx while the simulation for another chain is blocked,
+// which is expected not to wait for it since the SDK context is not held across the queries.
+func TestBuildTxWithoutLockingQueries(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	sdk.SetAddrCacheEnabled(false)
+	t.Cleanup(func() { sdk.SetAddrCacheEnabled(true) })
+
+	slow, fast := newTxTestChain(t, "ibc0", "cosmos"), newTxTestChain(t, "ibc1", "osmo")
+	stub := slow.Client.(*txRPCStub)
+	stub.simulating, stub.release = make(chan struct{}, 1), make(chan struct{})
+	defer close(stub.release)
+
+	msg := func(chain *Chain) sdk.Msg {
+		signer, err := sdk.Bech32ifyAddressBytes(chain.config.AccountPrefix, chain.MustGetAddress())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &clienttypes.MsgUpdateClient{ClientId: "07-tendermint-0", Signer: signer}
+	}
+	slowMsg, fastMsg := msg(slow), msg(fast)
+	slowErr := make(chan error, 1)
+	go func() {
+		_, _, err := slow.buildTx(context.TODO(), []sdk.Msg{slowMsg})
+		slowErr <- err
+	}()
+	select {
+	case <-stub.simulating:
+	case err := <-slowErr:
+		t.Fatalf("the tx is built without the simulation: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := fast.buildTx(context.TODO(), []sdk.Msg{fastMsg})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("building a tx is blocked by the simulation for another chain")
+	}
+}
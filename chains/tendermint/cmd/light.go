@@ -68,7 +68,7 @@ func initLightCmd(ctx *config.Context) *cobra.Command {
 				if err != nil {
 					return err
 				}
-				fmt.Printf("successfully created light client for %s by trusting endpoint %s...\n", chain.ChainID(), chain.Config().RpcAddr)
+				fmt.Printf("successfully created light client for %s by trusting endpoint %s...\n", chain.ChainID(), chain.RPCAddr())
 			case height > 0 && len(hash) > 0: // height and hash are given
 				_, err = prover.LightClientWithTrust(cmd.Context(), db, prover.TrustOptions(height, hash))
 				if err != nil {
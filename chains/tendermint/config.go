@@ -32,6 +32,11 @@ func (c ChainConfig) Validate() error {
 	if isEmpty(c.RpcAddr) {
 		errs = append(errs, fmt.Errorf("config attribute \"rpc_addr\" is empty"))
 	}
+	for i, addr := range c.RpcAddrs {
+		if isEmpty(addr) {
+			errs = append(errs, fmt.Errorf("config attribute \"rpc_addrs[%d]\" is empty", i))
+		}
+	}
 	if isEmpty(c.AccountPrefix) {
 		errs = append(errs, fmt.Errorf("config attribute \"account_prefix\" is empty"))
 	}
@@ -25,14 +25,17 @@ var _ = math.Inf
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
 type ChainConfig struct {
-	Key                  string  `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	ChainId              string  `protobuf:"bytes,2,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
-	RpcAddr              string  `protobuf:"bytes,3,opt,name=rpc_addr,json=rpcAddr,proto3" json:"rpc_addr,omitempty"`
-	AccountPrefix        string  `protobuf:"bytes,4,opt,name=account_prefix,json=accountPrefix,proto3" json:"account_prefix,omitempty"`
-	GasAdjustment        float64 `protobuf:"fixed64,5,opt,name=gas_adjustment,json=gasAdjustment,proto3" json:"gas_adjustment,omitempty"`
-	GasPrices            string  `protobuf:"bytes,6,opt,name=gas_prices,json=gasPrices,proto3" json:"gas_prices,omitempty"`
-	AverageBlockTimeMsec uint64  `protobuf:"varint,7,opt,name=average_block_time_msec,json=averageBlockTimeMsec,proto3" json:"average_block_time_msec,omitempty"`
-	MaxRetryForCommit    uint64  `protobuf:"varint,8,opt,name=max_retry_for_commit,json=maxRetryForCommit,proto3" json:"max_retry_for_commit,omitempty"`
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	ChainId              string   `protobuf:"bytes,2,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	RpcAddr              string   `protobuf:"bytes,3,opt,name=rpc_addr,json=rpcAddr,proto3" json:"rpc_addr,omitempty"`
+	AccountPrefix        string   `protobuf:"bytes,4,opt,name=account_prefix,json=accountPrefix,proto3" json:"account_prefix,omitempty"`
+	GasAdjustment        float64  `protobuf:"fixed64,5,opt,name=gas_adjustment,json=gasAdjustment,proto3" json:"gas_adjustment,omitempty"`
+	GasPrices            string   `protobuf:"bytes,6,opt,name=gas_prices,json=gasPrices,proto3" json:"gas_prices,omitempty"`
+	AverageBlockTimeMsec uint64   `protobuf:"varint,7,opt,name=average_block_time_msec,json=averageBlockTimeMsec,proto3" json:"average_block_time_msec,omitempty"`
+	MaxRetryForCommit    uint64   `protobuf:"varint,8,opt,name=max_retry_for_commit,json=maxRetryForCommit,proto3" json:"max_retry_for_commit,omitempty"`
+	RpcAddrs             []string `protobuf:"bytes,9,rep,name=rpc_addrs,json=rpcAddrs,proto3" json:"rpc_addrs,omitempty"`
+	SpreadQueries        bool     `protobuf:"varint,10,opt,name=spread_queries,json=spreadQueries,proto3" json:"spread_queries,omitempty"`
+	GrpcAddr             string   `protobuf:"bytes,11,opt,name=grpc_addr,json=grpcAddr,proto3" json:"grpc_addr,omitempty"`
 }
 
 func (m *ChainConfig) Reset()         { *m = ChainConfig{} }
@@ -155,38 +158,41 @@ func init() {
 }
 
 var fileDescriptor_d67cd47cbc86ecb1 = []byte{
-	// 482 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x92, 0x41, 0x6b, 0x13, 0x41,
-	0x14, 0xc7, 0xb3, 0x6d, 0x6c, 0x93, 0x89, 0xad, 0xba, 0x04, 0x5d, 0x45, 0x97, 0x10, 0x10, 0x83,
-	0xd0, 0x5d, 0x50, 0x3c, 0x78, 0x6c, 0x03, 0x05, 0x05, 0x21, 0x2c, 0x05, 0xc1, 0xcb, 0x38, 0x99,
-	0x79, 0x99, 0x8c, 0xcd, 0xcc, 0x2c, 0x6f, 0x66, 0x4b, 0xf2, 0x2d, 0xbc, 0xfa, 0x15, 0xfc, 0x24,
-	0x3d, 0xf6, 0xe8, 0x51, 0x93, 0x2f, 0x22, 0x3b, 0xd9, 0x58, 0x2f, 0xe2, 0x69, 0x66, 0x7f, 0xff,
-	0xdf, 0x7b, 0x2c, 0x6f, 0x1e, 0x39, 0x41, 0x58, 0xb0, 0x15, 0x60, 0xce, 0xe7, 0x4c, 0x19, 0x97,
-	0x7b, 0x30, 0x02, 0x50, 0x2b, 0xe3, 0x73, 0x6e, 0xcd, 0x4c, 0xc9, 0xe6, 0xc8, 0x4a, 0xb4, 0xde,
-	0xc6, 0x83, 0x46, 0xcf, 0xb6, 0x7a, 0x76, 0xab, 0x67, 0x5b, 0xef, 0x49, 0x5f, 0x5a, 0x69, 0x83,
-	0x9c, 0xd7, 0xb7, 0x6d, 0xdd, 0xf0, 0xfb, 0x1e, 0xe9, 0x8d, 0xeb, 0x92, 0x71, 0xb0, 0xe2, 0xfb,
-	0x64, 0xff, 0x12, 0x56, 0x49, 0x34, 0x88, 0x46, 0xdd, 0xa2, 0xbe, 0xc6, 0x8f, 0x49, 0x27, 0xf4,
-	0xa4, 0x4a, 0x24, 0x7b, 0x01, 0x1f, 0x86, 0xef, 0x77, 0xa2, 0x8e, 0xb0, 0xe4, 0x94, 0x09, 0x81,
-	0xc9, 0xfe, 0x36, 0xc2, 0x92, 0x9f, 0x0a, 0x81, 0xf1, 0x73, 0x72, 0xcc, 0x38, 0xb7, 0x95, 0xf1,
-	0xb4, 0x44, 0x98, 0xa9, 0x65, 0xd2, 0x0e, 0xc2, 0x51, 0x43, 0x27, 0x01, 0xd6, 0x9a, 0x64, 0x8e,
-	0x32, 0xf1, 0xa5, 0x72, 0x5e, 0x83, 0xf1, 0xc9, 0x9d, 0x41, 0x34, 0x8a, 0x8a, 0x23, 0xc9, 0xdc,
-	0xe9, 0x1f, 0x18, 0x3f, 0x23, 0xa4, 0xd6, 0x4a, 0x54, 0x1c, 0x5c, 0x72, 0x10, 0x3a, 0x75, 0x25,
-	0x73, 0x93, 0x00, 0xe2, 0x37, 0xe4, 0x11, 0xbb, 0x02, 0x64, 0x12, 0xe8, 0x74, 0x61, 0xf9, 0x25,
-	0xf5, 0x4a, 0x03, 0xd5, 0x0e, 0x78, 0x72, 0x38, 0x88, 0x46, 0xed, 0xa2, 0xdf, 0xc4, 0x67, 0x75,
-	0x7a, 0xa1, 0x34, 0x7c, 0x70, 0xc0, 0xe3, 0x9c, 0xf4, 0x35, 0x5b, 0x52, 0x04, 0x8f, 0x2b, 0x3a,
-	0xb3, 0x48, 0xb9, 0xd5, 0x5a, 0xf9, 0xa4, 0x13, 0x6a, 0x1e, 0x68, 0xb6, 0x2c, 0xea, 0xe8, 0xdc,
-	0xe2, 0x38, 0x04, 0xc3, 0x6f, 0x11, 0xb9, 0x3b, 0x41, 0x7b, 0x05, 0xd8, 0x4c, 0xeb, 0x05, 0xb9,
-	0xe7, 0xb1, 0x72, 0x5e, 0x19, 0x49, 0x4b, 0x40, 0x65, 0x45, 0x33, 0xb9, 0xe3, 0x1d, 0x9e, 0x04,
-	0x1a, 0x7f, 0x26, 0x0f, 0x11, 0x66, 0x08, 0x6e, 0x4e, 0xfd, 0xbc, 0x3e, 0xec, 0x42, 0x50, 0x64,
-	0x1e, 0xc2, 0x48, 0x7b, 0xaf, 0x5e, 0x66, 0xff, 0x7b, 0xbf, 0xec, 0x1c, 0x19, 0xf7, 0xca, 0x9a,
-	0xa2, 0xdf, 0x74, 0xba, 0xd8, 0x35, 0x2a, 0x98, 0x87, 0xe1, 0x7b, 0xd2, 0xd9, 0x19, 0xf1, 0x53,
-	0xd2, 0x35, 0x95, 0x06, 0x64, 0xde, 0x62, 0xf8, 0xa1, 0x76, 0x71, 0x0b, 0xe2, 0x01, 0xe9, 0x09,
-	0x30, 0x56, 0x2b, 0x13, 0xf2, 0xbd, 0x90, 0xff, 0x8d, 0xce, 0x3e, 0x5e, 0xff, 0x4a, 0x5b, 0xd7,
-	0xeb, 0x34, 0xba, 0x59, 0xa7, 0xd1, 0xcf, 0x75, 0x1a, 0x7d, 0xdd, 0xa4, 0xad, 0x9b, 0x4d, 0xda,
-	0xfa, 0xb1, 0x49, 0x5b, 0x9f, 0xde, 0x4a, 0xe5, 0xe7, 0xd5, 0x34, 0xe3, 0x56, 0xe7, 0xf3, 0x55,
-	0x09, 0xb8, 0x00, 0x21, 0x01, 0x4f, 0x16, 0x6c, 0xea, 0xf2, 0x55, 0xa5, 0xfe, 0xbd, 0xb9, 0xd3,
-	0x83, 0xb0, 0x74, 0xaf, 0x7f, 0x07, 0x00, 0x00, 0xff, 0xff, 0x58, 0x5a, 0x44, 0xe1, 0xdd, 0x02,
-	0x00, 0x00,
+	// 529 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x93, 0xcf, 0x6b, 0xd4, 0x40,
+	0x14, 0xc7, 0x37, 0xdd, 0xda, 0x6e, 0x66, 0x6d, 0xd5, 0xb0, 0x68, 0xfc, 0x15, 0x42, 0x41, 0x5c,
+	0x84, 0x26, 0xa0, 0x78, 0xf0, 0xd8, 0x16, 0x0a, 0x0a, 0xc2, 0x1a, 0x0a, 0x82, 0x97, 0x71, 0x76,
+	0xe6, 0xed, 0xec, 0xd8, 0x9d, 0x4c, 0x7c, 0x33, 0x29, 0xdd, 0xbf, 0xc1, 0x8b, 0x57, 0xff, 0xa3,
+	0x1e, 0x7b, 0xf4, 0xa8, 0xdd, 0x7f, 0x44, 0x32, 0xc9, 0xb6, 0x5e, 0xc4, 0xd3, 0x4c, 0x3e, 0xdf,
+	0xef, 0xfb, 0x92, 0x97, 0xf7, 0x42, 0xf6, 0x11, 0x16, 0x6c, 0x09, 0x98, 0xf3, 0x39, 0x53, 0xa5,
+	0xcd, 0x1d, 0x94, 0x02, 0x50, 0xab, 0xd2, 0xe5, 0xdc, 0x94, 0x33, 0x25, 0xbb, 0x23, 0xab, 0xd0,
+	0x38, 0x13, 0xa5, 0x9d, 0x3d, 0x6b, 0xed, 0xd9, 0x8d, 0x3d, 0x6b, 0x7d, 0x8f, 0x46, 0xd2, 0x48,
+	0xe3, 0xcd, 0x79, 0x73, 0x6b, 0xeb, 0xf6, 0xbe, 0xf5, 0xc9, 0xf0, 0xa8, 0x29, 0x39, 0xf2, 0xae,
+	0xe8, 0x2e, 0xe9, 0x9f, 0xc2, 0x32, 0x0e, 0xd2, 0x60, 0x1c, 0x16, 0xcd, 0x35, 0x7a, 0x48, 0x06,
+	0x3e, 0x93, 0x2a, 0x11, 0x6f, 0x78, 0xbc, 0xed, 0x9f, 0xdf, 0x8a, 0x46, 0xc2, 0x8a, 0x53, 0x26,
+	0x04, 0xc6, 0xfd, 0x56, 0xc2, 0x8a, 0x1f, 0x08, 0x81, 0xd1, 0x33, 0xb2, 0xcb, 0x38, 0x37, 0x75,
+	0xe9, 0x68, 0x85, 0x30, 0x53, 0xe7, 0xf1, 0xa6, 0x37, 0xec, 0x74, 0x74, 0xe2, 0x61, 0x63, 0x93,
+	0xcc, 0x52, 0x26, 0xbe, 0xd4, 0xd6, 0x69, 0x28, 0x5d, 0x7c, 0x2b, 0x0d, 0xc6, 0x41, 0xb1, 0x23,
+	0x99, 0x3d, 0xb8, 0x86, 0xd1, 0x53, 0x42, 0x1a, 0x5b, 0x85, 0x8a, 0x83, 0x8d, 0xb7, 0x7c, 0x52,
+	0x28, 0x99, 0x9d, 0x78, 0x10, 0xbd, 0x26, 0x0f, 0xd8, 0x19, 0x20, 0x93, 0x40, 0xa7, 0x0b, 0xc3,
+	0x4f, 0xa9, 0x53, 0x1a, 0xa8, 0xb6, 0xc0, 0xe3, 0xed, 0x34, 0x18, 0x6f, 0x16, 0xa3, 0x4e, 0x3e,
+	0x6c, 0xd4, 0x13, 0xa5, 0xe1, 0xbd, 0x05, 0x1e, 0xe5, 0x64, 0xa4, 0xd9, 0x39, 0x45, 0x70, 0xb8,
+	0xa4, 0x33, 0x83, 0x94, 0x1b, 0xad, 0x95, 0x8b, 0x07, 0xbe, 0xe6, 0x9e, 0x66, 0xe7, 0x45, 0x23,
+	0x1d, 0x1b, 0x3c, 0xf2, 0x42, 0xf4, 0x98, 0x84, 0xeb, 0x7e, 0x6d, 0x1c, 0xa6, 0xfd, 0x71, 0x58,
+	0x0c, 0xba, 0x86, 0x6d, 0xd3, 0x8a, 0xad, 0x10, 0x98, 0xa0, 0x5f, 0x6b, 0x40, 0x05, 0x36, 0x26,
+	0x69, 0x30, 0x1e, 0x14, 0x3b, 0x2d, 0xfd, 0xd0, 0xc2, 0x26, 0x43, 0x5e, 0x7f, 0xb4, 0xa1, 0xef,
+	0x64, 0x20, 0xbb, 0x90, 0xbd, 0x1f, 0x01, 0xb9, 0x3d, 0x41, 0x73, 0x06, 0xd8, 0x8d, 0xe3, 0x39,
+	0xb9, 0xe3, 0xb0, 0xb6, 0x4e, 0x95, 0x92, 0x56, 0x80, 0xca, 0x88, 0x6e, 0x34, 0xbb, 0x6b, 0x3c,
+	0xf1, 0x34, 0xfa, 0x4c, 0xee, 0x23, 0xcc, 0x10, 0xec, 0x9c, 0xba, 0x79, 0x73, 0x98, 0x85, 0xa0,
+	0xc8, 0x1c, 0xf8, 0x99, 0x0d, 0x5f, 0xbe, 0xc8, 0xfe, 0xb7, 0x20, 0xd9, 0x31, 0x32, 0xee, 0x94,
+	0x29, 0x8b, 0x51, 0x97, 0x74, 0xb2, 0x0e, 0x2a, 0x98, 0x83, 0xbd, 0x77, 0x64, 0xb0, 0x76, 0x44,
+	0x4f, 0x48, 0x58, 0xd6, 0x1a, 0x90, 0x39, 0x83, 0xfe, 0x85, 0x36, 0x8b, 0x1b, 0x10, 0xa5, 0x64,
+	0x28, 0xa0, 0x34, 0x5a, 0x95, 0x5e, 0xdf, 0xf0, 0xfa, 0xdf, 0xe8, 0xf0, 0xe3, 0xc5, 0xef, 0xa4,
+	0x77, 0x71, 0x95, 0x04, 0x97, 0x57, 0x49, 0xf0, 0xeb, 0x2a, 0x09, 0xbe, 0xaf, 0x92, 0xde, 0xe5,
+	0x2a, 0xe9, 0xfd, 0x5c, 0x25, 0xbd, 0x4f, 0x6f, 0xa4, 0x72, 0xf3, 0x7a, 0x9a, 0x71, 0xa3, 0xf3,
+	0xf9, 0xb2, 0x02, 0x5c, 0x80, 0x90, 0x80, 0xfb, 0x0b, 0x36, 0xb5, 0xf9, 0xb2, 0x56, 0xff, 0xfe,
+	0x35, 0xa6, 0x5b, 0x7e, 0xab, 0x5f, 0xfd, 0x19, 0x00, 0x3f, 0x46, 0x04, 0x4b, 0x3e, 0x03, 0x00,
+	0x00,
 }
 
 func (m *ChainConfig) Marshal() (dAtA []byte, err error) {
@@ -209,6 +215,32 @@ func (m *ChainConfig) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.GrpcAddr) > 0 {
+		i -= len(m.GrpcAddr)
+		copy(dAtA[i:], m.GrpcAddr)
+		i = encodeVarintConfig(dAtA, i, uint64(len(m.GrpcAddr)))
+		i--
+		dAtA[i] = 0x5a
+	}
+	if m.SpreadQueries {
+		i--
+		if m.SpreadQueries {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x50
+	}
+	if len(m.RpcAddrs) > 0 {
+		for iNdEx := len(m.RpcAddrs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.RpcAddrs[iNdEx])
+			copy(dAtA[i:], m.RpcAddrs[iNdEx])
+			i = encodeVarintConfig(dAtA, i, uint64(len(m.RpcAddrs[iNdEx])))
+			i--
+			dAtA[i] = 0x4a
+		}
+	}
 	if m.MaxRetryForCommit != 0 {
 		i = encodeVarintConfig(dAtA, i, uint64(m.MaxRetryForCommit))
 		i--
@@ -384,6 +416,19 @@ func (m *ChainConfig) Size() (n int) {
 	if m.MaxRetryForCommit != 0 {
 		n += 1 + sovConfig(uint64(m.MaxRetryForCommit))
 	}
+	if len(m.RpcAddrs) > 0 {
+		for _, s := range m.RpcAddrs {
+			l = len(s)
+			n += 1 + l + sovConfig(uint64(l))
+		}
+	}
+	if m.SpreadQueries {
+		n += 2
+	}
+	l = len(m.GrpcAddr)
+	if l > 0 {
+		n += 1 + l + sovConfig(uint64(l))
+	}
 	return n
 }
 
@@ -663,6 +708,90 @@ func (m *ChainConfig) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RpcAddrs", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RpcAddrs = append(m.RpcAddrs, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SpreadQueries", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.SpreadQueries = bool(v != 0)
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GrpcAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConfig
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthConfig
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthConfig
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.GrpcAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipConfig(dAtA[iNdEx:])
@@ -0,0 +1,86 @@
+package tendermint
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	gogogrpc "github.com/cosmos/gogoproto/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// newGRPCConn returns a connection to the gRPC endpoint `addr` of the chain `chainID`, which counts the calls in the RPC metrics.
+// TLS is used if `addr` has the "https://" scheme.
+func newGRPCConn(addr string, chainID string, registry codectypes.InterfaceRegistry) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if target, ok := strings.CutPrefix(addr, "https://"); ok {
+		addr, creds = target, credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	} else {
+		addr = strings.TrimPrefix(addr, "http://")
+	}
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(codec.NewProtoCodec(registry).GRPCCodec())),
+		grpc.WithChainUnaryInterceptor(metricsUnaryInterceptor(chainID)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client: addr=%s, err=%v", addr, err)
+	}
+	return conn, nil
+}
+
+// metricsUnaryInterceptor returns an interceptor that counts the calls and the errors of the gRPC methods (e.g. "/ibc.core.client.v1.Query/ClientState")
+// as metricsRPCClient does for the RPC methods
+func metricsUnaryInterceptor(chainID string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		recordRPCCall(ctx, chainID, method, err)
+		return err
+	}
+}
+
+// heightGRPCConn is a gRPC connection that queries the state at `height`, or at the latest height if `height` is 0
+type heightGRPCConn struct {
+	*grpc.ClientConn
+	height int64
+}
+
+func (c heightGRPCConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	if c.height > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(c.height, 10))
+	}
+	return c.ClientConn.Invoke(ctx, method, args, reply, opts...)
+}
+
+// queryConn returns a connection for the typed query clients to query the state at `height`.
+// The queries are sent to the gRPC endpoint if `grpc_addr` is configured, and are sent as ABCI queries over RPC otherwise.
+// Queries with proofs don't use this connection because they need ABCI queries with `prove=true`.
+func (c *Chain) queryConn(ctx context.Context, height int64) gogogrpc.ClientConn {
+	if c.grpcConn == nil {
+		return c.CLIContext(height).WithCmdContext(ctx)
+	}
+	return heightGRPCConn{ClientConn: c.grpcConn, height: height}
+}
+
+var _ io.Closer = (*Chain)(nil)
+
+// Close closes the connection to the gRPC endpoint if `grpc_addr` is configured
+func (c *Chain) Close() error {
+	if c.grpcConn == nil {
+		return nil
+	}
+	if err := c.grpcConn.Close(); err != nil {
+		return fmt.Errorf("failed to close the gRPC connection: chain_id=%s, err=%v", c.ChainID(), err)
+	}
+	c.grpcConn = nil
+	return nil
+}
@@ -0,0 +1,86 @@
+package tendermint
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/metadata"
+)
+
+// channelQueryStub is a stub of the channel query service that returns all the requested sequences as unreceived
+type channelQueryStub struct {
+	chantypes.UnimplementedQueryServer
+	heights chan []string
+}
+
+func (s *channelQueryStub) UnreceivedPackets(ctx context.Context, req *chantypes.QueryUnreceivedPacketsRequest) (*chantypes.QueryUnreceivedPacketsResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.heights <- md.Get(grpctypes.GRPCBlockHeightHeader)
+	return &chantypes.QueryUnreceivedPacketsResponse{Sequences: req.PacketCommitmentSequences}, nil
+}
+
+func TestQueryOverGRPC(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	metrics.RPCCallsCounter, _ = meter.Int64Counter("relayer.rpc_calls")
+	metrics.RPCErrorsCounter, _ = meter.Int64Counter("relayer.rpc_errors")
+	t.Cleanup(func() {
+		if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	registry := codectypes.NewInterfaceRegistry()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &channelQueryStub{heights: make(chan []string, 1)}
+	srv := grpc.NewServer(grpc.ForceServerCodec(codec.NewProtoCodec(registry).GRPCCodec()))
+	chantypes.RegisterQueryServer(srv, stub)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := newGRPCConn("http://"+lis.Addr().String(), "ibc0", registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := &Chain{
+		PathEnd:  &core.PathEnd{ChainID: "ibc0", PortID: "transfer", ChannelID: "channel-0"},
+		grpcConn: conn,
+	}
+
+	seqs, err := chain.QueryUnreceivedPackets(core.NewQueryContext(context.TODO(), clienttypes.NewHeight(0, 100)), []uint64{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seqs) != 2 {
+		t.Errorf("unexpected sequences: %v", seqs)
+	}
+	if heights := <-stub.heights; len(heights) != 1 || heights[0] != "100" {
+		t.Errorf("the query height is expected to be passed in the metadata: %v", heights)
+	}
+	method := attribute.Key("method").String("/ibc.core.channel.v1.Query/UnreceivedPackets")
+	if v := counterValue(t, reader, "relayer.rpc_calls", attribute.Key("chain_id").String("ibc0"), method); v != 1 {
+		t.Errorf("unexpected gRPC calls: %d", v)
+	}
+
+	if err := chain.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if state := conn.GetState(); state != connectivity.Shutdown {
+		t.Errorf("the gRPC connection is expected to be closed: %v", state)
+	}
+}
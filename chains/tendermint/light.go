@@ -48,7 +48,7 @@ func (pr *Prover) LightClient(db dbm.DB) (*light.Client, error) {
 
 // LightHTTP returns the http client for light clients
 func (pr *Prover) LightHTTP() lightp.Provider {
-	cl, err := lighthttp.New(pr.chain.config.ChainId, pr.chain.config.RpcAddr)
+	cl, err := lighthttp.New(pr.chain.config.ChainId, pr.chain.RPCAddr())
 	if err != nil {
 		panic(err)
 	}
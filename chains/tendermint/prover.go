@@ -6,11 +6,15 @@ import (
 	"fmt"
 	"time"
 
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
 	"github.com/cometbft/cometbft/light"
 	"github.com/cometbft/cometbft/types"
 	tmtypes "github.com/cometbft/cometbft/types"
+	sdkCtx "github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/codec"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
 	ibcclient "github.com/cosmos/ibc-go/v8/modules/core/client"
 	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
 	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
@@ -60,6 +64,88 @@ func (pr *Prover) ProveHostConsensusState(ctx core.QueryContext, height ibcexpor
 	return nil, nil
 }
 
+/* ClientUpgrader implementation */
+
+var _ core.ClientUpgrader = (*Prover)(nil)
+
+// QueryUpgradeHeight returns the height of the planned upgrade if an upgraded client is committed for it
+func (pr *Prover) QueryUpgradeHeight(ctx context.Context) (ibcexported.Height, error) {
+	clientCtx := pr.chain.CLIContext(0).WithCmdContext(ctx)
+	res, err := upgradetypes.NewQueryClient(pr.chain.queryConn(ctx, 0)).CurrentPlan(ctx, &upgradetypes.QueryCurrentPlanRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the current upgrade plan: %v", err)
+	} else if res.Plan == nil {
+		return nil, nil
+	}
+	// the plan doesn't upgrade IBC clients unless the upgraded client is set by MsgIBCSoftwareUpgrade
+	if bz, _, err := clientCtx.QueryStore(upgradetypes.UpgradedClientKey(res.Plan.Height), upgradetypes.StoreKey); err != nil {
+		return nil, fmt.Errorf("failed to query the upgraded client: %v", err)
+	} else if len(bz) == 0 {
+		return nil, nil
+	}
+	return clienttypes.NewHeight(clienttypes.ParseChainID(pr.chain.ChainID()), uint64(res.Plan.Height)), nil
+}
+
+// ProveUpgradedClient returns the upgraded client and consensus states stored in the upgrade store with their proofs at the upgrade height
+func (pr *Prover) ProveUpgradedClient(ctx core.QueryContext) (*core.UpgradedClient, error) {
+	height := int64(ctx.Height().GetRevisionHeight())
+	header, err := pr.UpdateLightClient(ctx.Context(), height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update the local light client and get the header@%d: %v", height, err)
+	}
+
+	clientCtx := pr.chain.CLIContext(height).WithCmdContext(ctx.Context())
+	csBz, csProof, err := queryUpgradeStoreProof(clientCtx, upgradetypes.UpgradedClientKey(height))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the upgraded client: %v", err)
+	}
+	cs, err := clienttypes.UnmarshalClientState(pr.chain.Codec(), csBz)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the upgraded client: %v", err)
+	}
+	consBz, consProof, err := queryUpgradeStoreProof(clientCtx, upgradetypes.UpgradedConsStateKey(height))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the upgraded consensus state: %v", err)
+	}
+	cons, err := clienttypes.UnmarshalConsensusState(pr.chain.Codec(), consBz)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the upgraded consensus state: %v", err)
+	}
+
+	return &core.UpgradedClient{
+		Header:                     header,
+		ClientState:                cs,
+		ConsensusState:             cons,
+		ProofUpgradeClient:         csProof,
+		ProofUpgradeConsensusState: consProof,
+	}, nil
+}
+
+// queryUpgradeStoreProof returns the value and its merkle proof in the upgrade store, which are verifiable with the header at `clientCtx.Height`
+func queryUpgradeStoreProof(clientCtx sdkCtx.Context, key []byte) ([]byte, []byte, error) {
+	// the app hash of the header at height h commits to the state at height h-1
+	res, err := clientCtx.QueryABCI(abcitypes.RequestQuery{
+		Path:   fmt.Sprintf("store/%s/key", upgradetypes.StoreKey),
+		Height: clientCtx.Height - 1,
+		Data:   key,
+		Prove:  true,
+	})
+	if err != nil {
+		return nil, nil, err
+	} else if len(res.Value) == 0 {
+		return nil, nil, fmt.Errorf("key not found: %s", key)
+	}
+	merkleProof, err := commitmenttypes.ConvertProofs(res.ProofOps)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof, err := codec.NewProtoCodec(clientCtx.InterfaceRegistry).Marshal(&merkleProof)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res.Value, proof, nil
+}
+
 /* LightClient implementation */
 
 // CreateInitialLightClientState creates a pair of ClientState and ConsensusState submitted to the counterparty chain as MsgCreateClient
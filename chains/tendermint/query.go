@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 	"time"
@@ -17,6 +18,7 @@ import (
 	querytypes "github.com/cosmos/cosmos-sdk/types/query"
 	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	feetypes "github.com/cosmos/ibc-go/v8/modules/apps/29-fee/types"
 	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
 	clientutils "github.com/cosmos/ibc-go/v8/modules/core/02-client/client/utils"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
@@ -25,7 +27,9 @@ import (
 	chanutils "github.com/cosmos/ibc-go/v8/modules/core/04-channel/client/utils"
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	committypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
 	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
 	"github.com/hyperledger-labs/yui-relayer/core"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -40,6 +44,201 @@ func (c *Chain) queryClientState(ctx context.Context, height int64, _ bool) (*cl
 	return clientutils.QueryClientStateABCI(c.CLIContext(height).WithCmdContext(ctx), c.PathEnd.ClientID)
 }
 
+var _ core.ClientStatusQuerier = (*Chain)(nil)
+
+// QueryClientStatus returns the status (e.g. Active, Expired, Frozen) of the light client of the path end
+func (c *Chain) QueryClientStatus(ctx core.QueryContext) (ibcexported.Status, error) {
+	return c.QueryClientStatusByID(ctx, c.PathEnd.ClientID)
+}
+
+var _ core.LinkQuerier = (*Chain)(nil)
+
+// QueryClientStatusByID returns the status of the light client `clientID`
+func (c *Chain) QueryClientStatusByID(ctx core.QueryContext, clientID string) (ibcexported.Status, error) {
+	qc := clienttypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight())))
+	res, err := qc.ClientStatus(ctx.Context(), &clienttypes.QueryClientStatusRequest{
+		ClientId: clientID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to query client status: error=%w height=%v", err, ctx.Height())
+	}
+	return ibcexported.Status(res.Status), nil
+}
+
+// QueryClientStates returns all the light clients on this chain
+func (c *Chain) QueryClientStates(ctx core.QueryContext) (clienttypes.IdentifiedClientStates, error) {
+	qc := clienttypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight())))
+	var (
+		states clienttypes.IdentifiedClientStates
+		key    []byte
+	)
+	for {
+		res, err := qc.ClientStates(ctx.Context(), &clienttypes.QueryClientStatesRequest{
+			Pagination: &querytypes.PageRequest{Key: key},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query client states: %v", err)
+		}
+		states = append(states, res.ClientStates...)
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			return states, nil
+		}
+		key = res.Pagination.NextKey
+	}
+}
+
+// QueryClientConnections returns the identifiers of the connections associated with the light client `clientID`
+func (c *Chain) QueryClientConnections(ctx core.QueryContext, clientID string) ([]string, error) {
+	qc := conntypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight())))
+	res, err := qc.ClientConnections(ctx.Context(), &conntypes.QueryClientConnectionsRequest{
+		ClientId: clientID,
+	})
+	if err != nil && strings.Contains(err.Error(), "not found") {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query client connections: %v", err)
+	}
+	return res.ConnectionPaths, nil
+}
+
+// QueryConnectionChannels returns the channels associated with the connection `connectionID`
+func (c *Chain) QueryConnectionChannels(ctx core.QueryContext, connectionID string) ([]*chantypes.IdentifiedChannel, error) {
+	qc := chantypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight())))
+	var (
+		channels []*chantypes.IdentifiedChannel
+		key      []byte
+	)
+	for {
+		res, err := qc.ConnectionChannels(ctx.Context(), &chantypes.QueryConnectionChannelsRequest{
+			Connection: connectionID,
+			Pagination: &querytypes.PageRequest{Key: key},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query connection channels: %v", err)
+		}
+		channels = append(channels, res.Channels...)
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			return channels, nil
+		}
+		key = res.Pagination.NextKey
+	}
+}
+
+// QueryClientExpiration returns when the light client of the path end expires and its trusting period
+func (c *Chain) QueryClientExpiration(ctx core.QueryContext) (time.Time, time.Duration, error) {
+	csRes, err := c.QueryClientState(ctx)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	cs, err := clienttypes.UnpackClientState(csRes.ClientState)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("failed to unpack client state: %v", err)
+	}
+	tmCs, ok := cs.(*tmclient.ClientState)
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("unexpected client state type: %T", cs)
+	}
+	consRes, err := c.QueryClientConsensusState(ctx, tmCs.GetLatestHeight())
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	cons, err := clienttypes.UnpackConsensusState(consRes.ConsensusState)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("failed to unpack consensus state: %v", err)
+	}
+	tmCons, ok := cons.(*tmclient.ConsensusState)
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("unexpected consensus state type: %T", cons)
+	}
+	return tmCons.Timestamp.Add(tmCs.TrustingPeriod), tmCs.TrustingPeriod, nil
+}
+
+// QueryConsensusStateHeights returns the heights of the consensus states of the light client of the path end
+func (c *Chain) QueryConsensusStateHeights(ctx core.QueryContext) ([]ibcexported.Height, error) {
+	qc := clienttypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight())))
+	var (
+		heights []ibcexported.Height
+		key     []byte
+	)
+	for {
+		res, err := qc.ConsensusStateHeights(ctx.Context(), &clienttypes.QueryConsensusStateHeightsRequest{
+			ClientId:   c.PathEnd.ClientID,
+			Pagination: &querytypes.PageRequest{Key: key},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query consensus state heights: %v", err)
+		}
+		for _, h := range res.ConsensusStateHeights {
+			heights = append(heights, h)
+		}
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			return heights, nil
+		}
+		key = res.Pagination.NextKey
+	}
+}
+
+// QueryConsensusStateProcessed returns the time and height at which the consensus state at `height` of the light client of the path end was stored
+func (c *Chain) QueryConsensusStateProcessed(ctx core.QueryContext, height ibcexported.Height) (time.Time, ibcexported.Height, error) {
+	clientCtx := c.CLIContext(int64(ctx.Height().GetRevisionHeight())).WithCmdContext(ctx.Context())
+
+	timeBz, _, err := clientCtx.QueryStore(host.FullClientKey(c.PathEnd.ClientID, tmclient.ProcessedTimeKey(height)), ibcexported.StoreKey)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("failed to query processed time: %v", err)
+	} else if len(timeBz) == 0 {
+		return time.Time{}, nil, fmt.Errorf("processed time not found: height=%v", height)
+	}
+	heightBz, _, err := clientCtx.QueryStore(host.FullClientKey(c.PathEnd.ClientID, tmclient.ProcessedHeightKey(height)), ibcexported.StoreKey)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("failed to query processed height: %v", err)
+	} else if len(heightBz) == 0 {
+		return time.Time{}, nil, fmt.Errorf("processed height not found: height=%v", height)
+	}
+	processedHeight, err := clienttypes.ParseHeight(string(heightBz))
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("failed to parse processed height: %v", err)
+	}
+	return time.Unix(0, int64(sdk.BigEndianToUint64(timeBz))), processedHeight, nil
+}
+
+// QueryBlockDelay returns the number of blocks that this chain requires to pass for `delayPeriod` in the same way as ibc-go
+func (c *Chain) QueryBlockDelay(ctx core.QueryContext, delayPeriod time.Duration) (uint64, error) {
+	qc := conntypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight())))
+	res, err := qc.ConnectionParams(ctx.Context(), &conntypes.QueryConnectionParamsRequest{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query connection params: %v", err)
+	}
+	expectedTimePerBlock := res.Params.MaxExpectedTimePerBlock
+	if expectedTimePerBlock == 0 {
+		return 0, nil
+	}
+	return uint64(math.Ceil(float64(delayPeriod) / float64(expectedTimePerBlock))), nil
+}
+
+var _ core.FeeQuerier = (*Chain)(nil)
+
+// QueryIncentivizedPacketFee returns the sum of the fees escrowed for the packet `packetID` sent from this chain, or nil if the packet is not incentivized
+func (c *Chain) QueryIncentivizedPacketFee(ctx core.QueryContext, packetID chantypes.PacketId) (*feetypes.Fee, error) {
+	qc := feetypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight())))
+	res, err := qc.IncentivizedPacket(ctx.Context(), &feetypes.QueryIncentivizedPacketRequest{
+		PacketId:    packetID,
+		QueryHeight: ctx.Height().GetRevisionHeight(),
+	})
+	if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query incentivized packet: %v", err)
+	}
+
+	var fee feetypes.Fee
+	for _, pf := range res.IncentivizedPacket.PacketFees {
+		fee.RecvFee = fee.RecvFee.Add(pf.Fee.RecvFee...)
+		fee.AckFee = fee.AckFee.Add(pf.Fee.AckFee...)
+		fee.TimeoutFee = fee.TimeoutFee.Add(pf.Fee.TimeoutFee...)
+	}
+	return &fee, nil
+}
+
 var emptyConnRes = conntypes.NewQueryConnectionResponse(
 	conntypes.NewConnectionEnd(
 		conntypes.UNINITIALIZED,
@@ -125,7 +324,7 @@ func (c *Chain) QueryBalance(ctx core.QueryContext, addr sdk.AccAddress) (sdk.Co
 		CountTotal: true,
 	}, true)
 
-	queryClient := bankTypes.NewQueryClient(c.CLIContext(0).WithCmdContext(ctx.Context()))
+	queryClient := bankTypes.NewQueryClient(c.queryConn(ctx.Context(), 0))
 
 	res, err := queryClient.AllBalances(ctx.Context(), params)
 	if err != nil {
@@ -137,7 +336,7 @@ func (c *Chain) QueryBalance(ctx core.QueryContext, addr sdk.AccAddress) (sdk.Co
 
 // QueryDenomTraces returns all the denom traces from a given chain
 func (c *Chain) QueryDenomTraces(ctx core.QueryContext, offset, limit uint64) (*transfertypes.QueryDenomTracesResponse, error) {
-	return transfertypes.NewQueryClient(c.CLIContext(int64(ctx.Height().GetRevisionHeight())).WithCmdContext(ctx.Context())).DenomTraces(ctx.Context(), &transfertypes.QueryDenomTracesRequest{
+	return transfertypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight()))).DenomTraces(ctx.Context(), &transfertypes.QueryDenomTracesRequest{
 		Pagination: &querytypes.PageRequest{
 			Key:        []byte(""),
 			Offset:     offset,
@@ -151,7 +350,7 @@ func (c *Chain) QueryDenomTraces(ctx core.QueryContext, offset, limit uint64) (*
 func (c *Chain) queryPacketCommitments(
 	ctx core.QueryContext,
 	offset, limit uint64) (comRes *chantypes.QueryPacketCommitmentsResponse, err error) {
-	qc := chantypes.NewQueryClient(c.CLIContext(int64(ctx.Height().GetRevisionHeight())).WithCmdContext(ctx.Context()))
+	qc := chantypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight())))
 	return qc.PacketCommitments(ctx.Context(), &chantypes.QueryPacketCommitmentsRequest{
 		PortId:    c.PathEnd.PortID,
 		ChannelId: c.PathEnd.ChannelID,
@@ -165,7 +364,7 @@ func (c *Chain) queryPacketCommitments(
 
 // queryPacketAcknowledgementCommitments returns an array of packet acks
 func (c *Chain) queryPacketAcknowledgementCommitments(ctx core.QueryContext, offset, limit uint64) (comRes *chantypes.QueryPacketAcknowledgementsResponse, err error) {
-	qc := chantypes.NewQueryClient(c.CLIContext(int64(ctx.Height().GetRevisionHeight())).WithCmdContext(ctx.Context()))
+	qc := chantypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight())))
 	return qc.PacketAcknowledgements(ctx.Context(), &chantypes.QueryPacketAcknowledgementsRequest{
 		PortId:    c.PathEnd.PortID,
 		ChannelId: c.PathEnd.ChannelID,
@@ -179,7 +378,7 @@ func (c *Chain) queryPacketAcknowledgementCommitments(ctx core.QueryContext, off
 
 // QueryUnreceivedPackets returns a list of unrelayed packet commitments
 func (c *Chain) QueryUnreceivedPackets(ctx core.QueryContext, seqs []uint64) ([]uint64, error) {
-	qc := chantypes.NewQueryClient(c.CLIContext(int64(ctx.Height().GetRevisionHeight())).WithCmdContext(ctx.Context()))
+	qc := chantypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight())))
 	res, err := qc.UnreceivedPackets(ctx.Context(), &chantypes.QueryUnreceivedPacketsRequest{
 		PortId:                    c.PathEnd.PortID,
 		ChannelId:                 c.PathEnd.ChannelID,
@@ -191,6 +390,21 @@ func (c *Chain) QueryUnreceivedPackets(ctx core.QueryContext, seqs []uint64) ([]
 	return res.Sequences, nil
 }
 
+var _ core.NextSequenceReceiveQuerier = (*Chain)(nil)
+
+// QueryNextSequenceReceive returns the next sequence to be received on the channel of the path end
+func (c *Chain) QueryNextSequenceReceive(ctx core.QueryContext) (*chantypes.QueryNextSequenceReceiveResponse, error) {
+	qc := chantypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight())))
+	res, err := qc.NextSequenceReceive(ctx.Context(), &chantypes.QueryNextSequenceReceiveRequest{
+		PortId:    c.PathEnd.PortID,
+		ChannelId: c.PathEnd.ChannelID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query next sequence receive: error=%w height=%v", err, ctx.Height())
+	}
+	return res, nil
+}
+
 func (c *Chain) QueryUnfinalizedRelayPackets(ctx core.QueryContext, counterparty core.LightClientICS04Querier) (core.PacketInfoList, error) {
 	res, err := c.queryPacketCommitments(ctx, 0, 1000)
 	if err != nil {
@@ -199,14 +413,14 @@ func (c *Chain) QueryUnfinalizedRelayPackets(ctx core.QueryContext, counterparty
 
 	var packets core.PacketInfoList
 	for _, ps := range res.Commitments {
-		packet, height, err := c.querySentPacket(ctx, ps.Sequence)
+		packet, tx, err := c.querySentPacket(ctx, ps.Sequence)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query sent packet: error=%w height=%v", err, ctx.Height())
 		}
 		packets = append(packets, &core.PacketInfo{
 			Packet:          *packet,
 			Acknowledgement: nil,
-			EventHeight:     height,
+			EventHeight:     tx.Height,
 		})
 	}
 
@@ -228,7 +442,7 @@ func (c *Chain) QueryUnfinalizedRelayPackets(ctx core.QueryContext, counterparty
 
 // QueryUnreceivedAcknowledgements returns a list of unrelayed packet acks
 func (c *Chain) QueryUnreceivedAcknowledgements(ctx core.QueryContext, seqs []uint64) ([]uint64, error) {
-	qc := chantypes.NewQueryClient(c.CLIContext(int64(ctx.Height().GetRevisionHeight())).WithCmdContext(ctx.Context()))
+	qc := chantypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight())))
 	res, err := qc.UnreceivedAcks(ctx.Context(), &chantypes.QueryUnreceivedAcksRequest{
 		PortId:             c.PathEnd.PortID,
 		ChannelId:          c.PathEnd.ChannelID,
@@ -248,7 +462,7 @@ func (c *Chain) QueryUnfinalizedRelayAcknowledgements(ctx core.QueryContext, cou
 
 	var packets core.PacketInfoList
 	for _, ps := range res.Acknowledgements {
-		packet, rpHeight, err := c.queryReceivedPacket(ctx, ps.Sequence)
+		packet, rpTx, err := c.queryReceivedPacket(ctx, ps.Sequence)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query received packet: error=%w height=%v", err, ctx.Height())
 		}
@@ -259,7 +473,7 @@ func (c *Chain) QueryUnfinalizedRelayAcknowledgements(ctx core.QueryContext, cou
 		packets = append(packets, &core.PacketInfo{
 			Packet:          *packet,
 			Acknowledgement: ack,
-			EventHeight:     rpHeight,
+			EventHeight:     rpTx.Height,
 		})
 	}
 
@@ -279,80 +493,149 @@ func (c *Chain) QueryUnfinalizedRelayAcknowledgements(ctx core.QueryContext, cou
 	return packets, nil
 }
 
-// querySentPacket finds a SendPacket event corresponding to `seq` and returns the packet in it
-func (c *Chain) querySentPacket(ctx core.QueryContext, seq uint64) (*chantypes.Packet, clienttypes.Height, error) {
-	txs, err := c.QueryTxs(ctx.Context(), int64(ctx.Height().GetRevisionHeight()), 1, 1000, sendPacketQuery(c.Path().ChannelID, int(seq)))
+// errPacketTxNotFound is returned if no tx processing the packet is found
+var errPacketTxNotFound = errors.New("no transactions returned with query")
+
+// queryPacketTx returns the only tx that emitted the events matching `events`
+func (c *Chain) queryPacketTx(ctx core.QueryContext, events []string) (*ctypes.ResultTx, error) {
+	txs, err := c.QueryTxs(ctx.Context(), int64(ctx.Height().GetRevisionHeight()), 1, 1000, events)
 	switch {
 	case err != nil:
-		return nil, clienttypes.Height{}, err
+		return nil, err
 	case len(txs) == 0:
-		return nil, clienttypes.Height{}, fmt.Errorf("no transactions returned with query")
+		return nil, errPacketTxNotFound
 	case len(txs) > 1:
-		return nil, clienttypes.Height{}, fmt.Errorf("more than one transaction returned with query")
+		return nil, fmt.Errorf("more than one transaction returned with query")
 	}
+	return txs[0], nil
+}
 
-	packet, err := core.FindPacketFromEventsBySequence(txs[0].TxResult.Events, chantypes.EventTypeSendPacket, seq)
+func (c *Chain) newPacketTx(tx *ctypes.ResultTx) *core.PacketTx {
+	return &core.PacketTx{
+		TxHash: tx.Hash.String(),
+		Height: clienttypes.NewHeight(clienttypes.ParseChainID(c.ChainID()), uint64(tx.Height)),
+	}
+}
+
+// querySentPacket finds a SendPacket event corresponding to `seq` and returns the packet in it
+func (c *Chain) querySentPacket(ctx core.QueryContext, seq uint64) (*chantypes.Packet, *core.PacketTx, error) {
+	tx, err := c.queryPacketTx(ctx, sendPacketQuery(c.Path().ChannelID, int(seq)))
 	if err != nil {
-		return nil, clienttypes.Height{}, err
+		return nil, nil, err
+	}
+
+	packet, err := core.FindPacketFromEventsBySequence(tx.TxResult.Events, chantypes.EventTypeSendPacket, seq)
+	if err != nil {
+		return nil, nil, err
 	}
 	if packet == nil {
-		return nil, clienttypes.Height{}, fmt.Errorf("can't find the packet from events")
+		return nil, nil, fmt.Errorf("can't find the packet from events")
 	}
 
-	height := clienttypes.NewHeight(clienttypes.ParseChainID(c.ChainID()), uint64(txs[0].Height))
-
-	return packet, height, nil
+	return packet, c.newPacketTx(tx), nil
 }
 
 // queryReceivedPacket finds a RecvPacket event corresponding to `seq` and return the packet in it
-func (c *Chain) queryReceivedPacket(ctx core.QueryContext, seq uint64) (*chantypes.Packet, clienttypes.Height, error) {
-	txs, err := c.QueryTxs(ctx.Context(), int64(ctx.Height().GetRevisionHeight()), 1, 1000, recvPacketQuery(c.Path().ChannelID, int(seq)))
-	switch {
-	case err != nil:
-		return nil, clienttypes.Height{}, err
-	case len(txs) == 0:
-		return nil, clienttypes.Height{}, fmt.Errorf("no transactions returned with query")
-	case len(txs) > 1:
-		return nil, clienttypes.Height{}, fmt.Errorf("more than one transaction returned with query")
+func (c *Chain) queryReceivedPacket(ctx core.QueryContext, seq uint64) (*chantypes.Packet, *core.PacketTx, error) {
+	tx, err := c.queryPacketTx(ctx, recvPacketQuery(c.Path().ChannelID, int(seq)))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	packet, err := core.FindPacketFromEventsBySequence(txs[0].TxResult.Events, chantypes.EventTypeRecvPacket, seq)
+	packet, err := core.FindPacketFromEventsBySequence(tx.TxResult.Events, chantypes.EventTypeRecvPacket, seq)
 	if err != nil {
-		return nil, clienttypes.Height{}, err
+		return nil, nil, err
 	}
 	if packet == nil {
-		return nil, clienttypes.Height{}, fmt.Errorf("can't find the packet from events")
+		return nil, nil, fmt.Errorf("can't find the packet from events")
 	}
 
-	height := clienttypes.NewHeight(clienttypes.ParseChainID(c.ChainID()), uint64(txs[0].Height))
-
-	return packet, height, nil
-
+	return packet, c.newPacketTx(tx), nil
 }
 
 // queryWrittenAcknowledgement finds a WriteAcknowledgement event corresponding to `seq` and returns the acknowledgement in it
-func (c *Chain) queryWrittenAcknowledgement(ctx core.QueryContext, seq uint64) ([]byte, clienttypes.Height, error) {
-	txs, err := c.QueryTxs(ctx.Context(), int64(ctx.Height().GetRevisionHeight()), 1, 1000, writeAckQuery(c.Path().ChannelID, int(seq)))
-	switch {
-	case err != nil:
-		return nil, clienttypes.Height{}, err
-	case len(txs) == 0:
-		return nil, clienttypes.Height{}, fmt.Errorf("no transactions returned with query")
-	case len(txs) > 1:
-		return nil, clienttypes.Height{}, fmt.Errorf("more than one transaction returned with query")
+func (c *Chain) queryWrittenAcknowledgement(ctx core.QueryContext, seq uint64) ([]byte, *core.PacketTx, error) {
+	tx, err := c.queryPacketTx(ctx, writeAckQuery(c.Path().ChannelID, int(seq)))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	ack, err := core.FindPacketAcknowledgementFromEventsBySequence(txs[0].TxResult.Events, seq)
+	ack, err := core.FindPacketAcknowledgementFromEventsBySequence(tx.TxResult.Events, seq)
 	if err != nil {
-		return nil, clienttypes.Height{}, err
+		return nil, nil, err
 	}
 	if ack == nil {
-		return nil, clienttypes.Height{}, fmt.Errorf("can't find the packet from events")
+		return nil, nil, fmt.Errorf("can't find the packet from events")
+	}
+
+	return ack.Data(), c.newPacketTx(tx), nil
+}
+
+var _ core.PacketTxQuerier = (*Chain)(nil)
+
+// QuerySentPacketTx returns the packet `seq` sent from the path end and the tx that sent it, or nil if not found
+func (c *Chain) QuerySentPacketTx(ctx core.QueryContext, seq uint64) (*chantypes.Packet, *core.PacketTx, error) {
+	packet, tx, err := c.querySentPacket(ctx, seq)
+	if errors.Is(err, errPacketTxNotFound) {
+		return nil, nil, nil
+	}
+	return packet, tx, err
+}
+
+// QueryReceivedPacketTx returns the tx that received the packet `seq` on the path end, or nil if not found
+func (c *Chain) QueryReceivedPacketTx(ctx core.QueryContext, seq uint64) (*core.PacketTx, error) {
+	_, tx, err := c.queryReceivedPacket(ctx, seq)
+	if errors.Is(err, errPacketTxNotFound) {
+		return nil, nil
+	}
+	return tx, err
+}
+
+// QueryWrittenAcknowledgementTx returns the acknowledgement written for the packet `seq` on the path end and the tx that wrote it, or nil if not found
+func (c *Chain) QueryWrittenAcknowledgementTx(ctx core.QueryContext, seq uint64) ([]byte, *core.PacketTx, error) {
+	ack, tx, err := c.queryWrittenAcknowledgement(ctx, seq)
+	if errors.Is(err, errPacketTxNotFound) {
+		return nil, nil, nil
+	}
+	return ack, tx, err
+}
+
+// QueryAcknowledgedPacketTx returns the tx that acknowledged the packet `seq` sent from the path end, or nil if not found
+func (c *Chain) QueryAcknowledgedPacketTx(ctx core.QueryContext, seq uint64) (*core.PacketTx, error) {
+	tx, err := c.queryPacketTx(ctx, ackPacketQuery(c.Path().ChannelID, int(seq)))
+	if errors.Is(err, errPacketTxNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
 	}
+	return c.newPacketTx(tx), nil
+}
 
-	height := clienttypes.NewHeight(clienttypes.ParseChainID(c.ChainID()), uint64(txs[0].Height))
+// QueryTimedOutPacketTx returns the tx that timed out the packet `seq` sent from the path end, or nil if not found
+func (c *Chain) QueryTimedOutPacketTx(ctx core.QueryContext, seq uint64) (*core.PacketTx, error) {
+	tx, err := c.queryPacketTx(ctx, timeoutPacketQuery(c.Path().ChannelID, int(seq)))
+	if errors.Is(err, errPacketTxNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return c.newPacketTx(tx), nil
+}
 
-	return ack.Data(), height, nil
+// QueryPacketCommitment returns the commitment of the packet `seq` sent from the path end, or nil if it doesn't exist
+func (c *Chain) QueryPacketCommitment(ctx core.QueryContext, seq uint64) ([]byte, error) {
+	qc := chantypes.NewQueryClient(c.queryConn(ctx.Context(), int64(ctx.Height().GetRevisionHeight())))
+	res, err := qc.PacketCommitment(ctx.Context(), &chantypes.QueryPacketCommitmentRequest{
+		PortId:    c.PathEnd.PortID,
+		ChannelId: c.PathEnd.ChannelID,
+		Sequence:  seq,
+	})
+	if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to query packet commitment: %v", err)
+	}
+	return res.Commitment, nil
 }
 
 // QueryTxs returns an array of transactions given a tag
@@ -425,7 +708,7 @@ func (c *Chain) QueryCanTransitionToFlushComplete(ctx core.QueryContext) (bool,
 }
 
 func (c *Chain) queryCanTransitionToFlushComplete(ctx context.Context, height int64) (bool, error) {
-	queryClient := chantypes.NewQueryClient(c.CLIContext(height).WithCmdContext(ctx))
+	queryClient := chantypes.NewQueryClient(c.queryConn(ctx, height))
 	req := chantypes.QueryPacketCommitmentsRequest{
 		PortId:    c.PathEnd.PortID,
 		ChannelId: c.PathEnd.ChannelID,
@@ -444,7 +727,7 @@ func (c *Chain) queryCanTransitionToFlushComplete(ctx context.Context, height in
 // QueryHistoricalInfo returns historical header data
 func (c *Chain) QueryHistoricalInfo(ctx context.Context, height clienttypes.Height) (*stakingtypes.QueryHistoricalInfoResponse, error) {
 	//TODO: use epoch number in query once SDK gets updated
-	qc := stakingtypes.NewQueryClient(c.CLIContext(int64(height.GetRevisionHeight())).WithCmdContext(ctx))
+	qc := stakingtypes.NewQueryClient(c.queryConn(ctx, int64(height.GetRevisionHeight())))
 	return qc.HistoricalInfo(ctx, &stakingtypes.QueryHistoricalInfoRequest{
 		Height: int64(height.GetRevisionHeight()),
 	})
@@ -506,7 +789,7 @@ func (c *Chain) toTmValidator(val stakingtypes.Validator) (*tmtypes.Validator, e
 func (c *Chain) QueryUnbondingPeriod(ctx context.Context) (time.Duration, error) {
 	req := stakingtypes.QueryParamsRequest{}
 
-	queryClient := stakingtypes.NewQueryClient(c.CLIContext(0).WithCmdContext(ctx))
+	queryClient := stakingtypes.NewQueryClient(c.queryConn(ctx, 0))
 
 	res, err := queryClient.Params(ctx, &req)
 	if err != nil {
@@ -520,6 +803,8 @@ const (
 	spTag = "send_packet"
 	rpTag = "recv_packet"
 	waTag = "write_acknowledgement"
+	apTag = "acknowledge_packet"
+	tpTag = "timeout_packet"
 )
 
 func sendPacketQuery(channelID string, seq int) []string {
@@ -534,6 +819,14 @@ func writeAckQuery(channelID string, seq int) []string {
 	return []string{fmt.Sprintf("%s.packet_dst_channel='%s'", waTag, channelID), fmt.Sprintf("%s.packet_sequence='%d'", waTag, seq)}
 }
 
+func ackPacketQuery(channelID string, seq int) []string {
+	return []string{fmt.Sprintf("%s.packet_src_channel='%s'", apTag, channelID), fmt.Sprintf("%s.packet_sequence='%d'", apTag, seq)}
+}
+
+func timeoutPacketQuery(channelID string, seq int) []string {
+	return []string{fmt.Sprintf("%s.packet_src_channel='%s'", tpTag, channelID), fmt.Sprintf("%s.packet_sequence='%d'", tpTag, seq)}
+}
+
 func channelUpgradeErrorQuery(channelID string, upgradeSequence uint64) []string {
 	return []string{
 		fmt.Sprintf("%s.%s='%s'",
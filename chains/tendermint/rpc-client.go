@@ -0,0 +1,108 @@
+package tendermint
+
+import (
+	"context"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// metricsRPCClient is a rpcclient.Client that counts the calls and the errors of the RPC methods used by the relayer.
+// The other methods are delegated to the underlying client without being counted.
+type metricsRPCClient struct {
+	rpcclient.Client
+	chainID string
+}
+
+var _ rpcclient.Client = (*metricsRPCClient)(nil)
+
+func newMetricsRPCClient(client rpcclient.Client, chainID string) *metricsRPCClient {
+	return &metricsRPCClient{Client: client, chainID: chainID}
+}
+
+func (c *metricsRPCClient) record(ctx context.Context, method string, err error) {
+	recordRPCCall(ctx, c.chainID, method, err)
+}
+
+// recordRPCCall counts a call of `method` to the chain `chainID`, and counts it as an error if `err` is not nil
+func recordRPCCall(ctx context.Context, chainID, method string, err error) {
+	attrs := api.WithAttributes(
+		attribute.Key("chain_id").String(chainID),
+		attribute.Key("method").String(method),
+	)
+	metrics.RPCCallsCounter.Add(ctx, 1, attrs)
+	if err != nil {
+		metrics.RPCErrorsCounter.Add(ctx, 1, attrs)
+	}
+}
+
+func (c *metricsRPCClient) Status(ctx context.Context) (*coretypes.ResultStatus, error) {
+	res, err := c.Client.Status(ctx)
+	c.record(ctx, "status", err)
+	return res, err
+}
+
+func (c *metricsRPCClient) ABCIQuery(ctx context.Context, path string, data bytes.HexBytes) (*coretypes.ResultABCIQuery, error) {
+	res, err := c.Client.ABCIQuery(ctx, path, data)
+	c.record(ctx, "abci_query", err)
+	return res, err
+}
+
+func (c *metricsRPCClient) ABCIQueryWithOptions(ctx context.Context, path string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (*coretypes.ResultABCIQuery, error) {
+	res, err := c.Client.ABCIQueryWithOptions(ctx, path, data, opts)
+	c.record(ctx, "abci_query", err)
+	return res, err
+}
+
+func (c *metricsRPCClient) BroadcastTxSync(ctx context.Context, tx tmtypes.Tx) (*coretypes.ResultBroadcastTx, error) {
+	res, err := c.Client.BroadcastTxSync(ctx, tx)
+	c.record(ctx, "broadcast_tx_sync", err)
+	return res, err
+}
+
+func (c *metricsRPCClient) Block(ctx context.Context, height *int64) (*coretypes.ResultBlock, error) {
+	res, err := c.Client.Block(ctx, height)
+	c.record(ctx, "block", err)
+	return res, err
+}
+
+func (c *metricsRPCClient) BlockResults(ctx context.Context, height *int64) (*coretypes.ResultBlockResults, error) {
+	res, err := c.Client.BlockResults(ctx, height)
+	c.record(ctx, "block_results", err)
+	return res, err
+}
+
+func (c *metricsRPCClient) Header(ctx context.Context, height *int64) (*coretypes.ResultHeader, error) {
+	res, err := c.Client.Header(ctx, height)
+	c.record(ctx, "header", err)
+	return res, err
+}
+
+func (c *metricsRPCClient) Commit(ctx context.Context, height *int64) (*coretypes.ResultCommit, error) {
+	res, err := c.Client.Commit(ctx, height)
+	c.record(ctx, "commit", err)
+	return res, err
+}
+
+func (c *metricsRPCClient) Validators(ctx context.Context, height *int64, page, perPage *int) (*coretypes.ResultValidators, error) {
+	res, err := c.Client.Validators(ctx, height, page, perPage)
+	c.record(ctx, "validators", err)
+	return res, err
+}
+
+func (c *metricsRPCClient) Tx(ctx context.Context, hash []byte, prove bool) (*coretypes.ResultTx, error) {
+	res, err := c.Client.Tx(ctx, hash, prove)
+	c.record(ctx, "tx", err)
+	return res, err
+}
+
+func (c *metricsRPCClient) TxSearch(ctx context.Context, query string, prove bool, page, perPage *int, orderBy string) (*coretypes.ResultTxSearch, error) {
+	res, err := c.Client.TxSearch(ctx, query, prove, page, perPage, orderBy)
+	c.record(ctx, "tx_search", err)
+	return res, err
+}
@@ -0,0 +1,78 @@
+package tendermint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// counterValue returns the value of the counter `name` with exactly `attrs` collected by `reader`
+func counterValue(t *testing.T, reader *sdkmetric.ManualReader, name string, attrs ...attribute.KeyValue) int64 {
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.TODO(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	set := attribute.NewSet(attrs...)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if m.Name != name || !ok {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				if dp.Attributes.Equals(&set) {
+					return dp.Value
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestMetricsRPCClient(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	metrics.RPCCallsCounter, _ = meter.Int64Counter("relayer.rpc_calls")
+	metrics.RPCErrorsCounter, _ = meter.Int64Counter("relayer.rpc_errors")
+	t.Cleanup(func() {
+		if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	stub := newRPCStub(t, 100, false)
+	rpcClient, err := newRPCClient(stub.URL, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newMetricsRPCClient(rpcClient, "ibc0")
+
+	if _, err := client.Status(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	// the stub doesn't serve `block`
+	if _, err := client.Block(context.TODO(), nil); err == nil {
+		t.Fatal("block is expected to fail")
+	}
+
+	chainID := attribute.Key("chain_id").String("ibc0")
+	status := attribute.Key("method").String("status")
+	block := attribute.Key("method").String("block")
+	if v := counterValue(t, reader, "relayer.rpc_calls", chainID, status); v != 1 {
+		t.Errorf("unexpected status calls: %d", v)
+	}
+	if v := counterValue(t, reader, "relayer.rpc_errors", chainID, status); v != 0 {
+		t.Errorf("unexpected status errors: %d", v)
+	}
+	if v := counterValue(t, reader, "relayer.rpc_calls", chainID, block); v != 1 {
+		t.Errorf("unexpected block calls: %d", v)
+	}
+	if v := counterValue(t, reader, "relayer.rpc_errors", chainID, block); v != 1 {
+		t.Errorf("unexpected block errors: %d", v)
+	}
+}
@@ -0,0 +1,528 @@
+package tendermint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cometbft/cometbft/libs/bytes"
+	cmtlog "github.com/cometbft/cometbft/libs/log"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+)
+
+const (
+	// rpcHealthCheckInterval is the interval to probe the health of the RPC endpoints
+	rpcHealthCheckInterval = 30 * time.Second
+	// rpcMaxHeightLag is the maximum number of blocks by which a healthy endpoint can lag behind the most advanced one
+	rpcMaxHeightLag = 3
+)
+
+type rpcEndpoint struct {
+	addr    string
+	client  rpcclient.Client
+	healthy bool
+	// height is the latest block height of the endpoint at the last probe
+	height int64
+}
+
+// failoverRPCClient is a rpcclient.Client over multiple RPC endpoints of a chain.
+// Calls are sent to the current endpoint, which is switched to another healthy endpoint when it fails or becomes unhealthy.
+// An endpoint is healthy if it is not catching up and its latest block is fresh and not far behind the other endpoints.
+// If spreadQueries is set, read-only queries are sent in turn to the healthy endpoints that had reached the queried height at the last probe,
+// and retried on another one if they fail.
+// Status, BroadcastTxSync and Tx always stick to the current endpoint so that a tx is tracked on the node that broadcast it,
+// as do the methods that depend on the state of the node itself (e.g. the mempool, the peers and the event subscriptions).
+// The lifecycle methods of service.Service are applied to all the endpoints.
+type failoverRPCClient struct {
+	chainID       string
+	endpoints     []*rpcEndpoint
+	spreadQueries bool
+	maxBlockAge   time.Duration
+
+	mu        sync.Mutex
+	current   int
+	next      int
+	checkedAt time.Time
+	probing   bool
+}
+
+var _ rpcclient.Client = (*failoverRPCClient)(nil)
+
+func newFailoverRPCClient(chainID string, addrs []string, clients []rpcclient.Client, spreadQueries bool, maxBlockAge time.Duration) *failoverRPCClient {
+	c := &failoverRPCClient{
+		chainID:       chainID,
+		spreadQueries: spreadQueries,
+		maxBlockAge:   maxBlockAge,
+	}
+	for i, addr := range addrs {
+		c.endpoints = append(c.endpoints, &rpcEndpoint{addr: addr, client: clients[i], healthy: true})
+	}
+	return c
+}
+
+// Addr returns the address of the current endpoint
+func (c *failoverRPCClient) Addr() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.endpoints[c.current].addr
+}
+
+// probe checks the health of all endpoints, and switches the current endpoint to a healthy one if it is unhealthy.
+// The current endpoint is kept if no endpoint is healthy.
+// The endpoints are probed without holding `c.mu` so that calls are not blocked by slow endpoints.
+func (c *failoverRPCClient) probe(ctx context.Context) {
+	logger := GetChainLogger().WithSpanContext(ctx)
+	statuses := make([]*coretypes.ResultStatus, len(c.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range c.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res, err := e.client.Status(ctx); err != nil {
+				logger.Warn("failed to probe the RPC endpoint", "chain_id", c.chainID, "rpc_addr", e.addr, "error", err.Error())
+			} else {
+				statuses[i] = res
+			}
+		}()
+	}
+	wg.Wait()
+
+	var maxHeight int64
+	for _, s := range statuses {
+		if s != nil && s.SyncInfo.LatestBlockHeight > maxHeight {
+			maxHeight = s.SyncInfo.LatestBlockHeight
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, e := range c.endpoints {
+		s := statuses[i]
+		e.healthy = s != nil && !s.SyncInfo.CatchingUp &&
+			s.SyncInfo.LatestBlockHeight+rpcMaxHeightLag >= maxHeight &&
+			time.Since(s.SyncInfo.LatestBlockTime) <= c.maxBlockAge
+		if s != nil {
+			e.height = s.SyncInfo.LatestBlockHeight
+		}
+	}
+	c.checkedAt = time.Now()
+	c.probing = false
+	c.failover(ctx)
+}
+
+// failover switches the current endpoint to the first healthy one if it is unhealthy
+func (c *failoverRPCClient) failover(ctx context.Context) {
+	if c.endpoints[c.current].healthy {
+		return
+	}
+	for i, e := range c.endpoints {
+		if e.healthy {
+			GetChainLogger().WithSpanContext(ctx).Warn("fail over to another RPC endpoint",
+				"chain_id", c.chainID,
+				"from", c.endpoints[c.current].addr,
+				"to", e.addr,
+			)
+			c.current = i
+			return
+		}
+	}
+}
+
+// pick returns the indexes of the endpoints to which a call is sent in order.
+// A sticky call is sent only to the current endpoint, and the other calls are retried on the other healthy endpoints.
+// A call at `height` (zero for the latest height) is not sent to the endpoints other than the current one that were behind the height at the last probe.
+func (c *failoverRPCClient) pick(ctx context.Context, sticky bool, height int64) []int {
+	c.mu.Lock()
+	due := !c.probing && time.Since(c.checkedAt) >= rpcHealthCheckInterval
+	if due {
+		c.probing = true
+	}
+	c.mu.Unlock()
+	if due {
+		c.probe(ctx)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if sticky {
+		return []int{c.current}
+	}
+
+	available := func(i int) bool {
+		e := c.endpoints[i]
+		return e.healthy && e.height >= height
+	}
+	first := c.current
+	if c.spreadQueries {
+		for range c.endpoints {
+			c.next = (c.next + 1) % len(c.endpoints)
+			if available(c.next) {
+				first = c.next
+				break
+			}
+		}
+	}
+	ret := []int{first}
+	for i := range c.endpoints {
+		if i != first && (i == c.current || available(i)) {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+// markFailed marks the endpoint `i` unhealthy because a call to it failed, and schedules the next probe
+func (c *failoverRPCClient) markFailed(ctx context.Context, i int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	GetChainLogger().WithSpanContext(ctx).Warn("RPC endpoint failed", "chain_id", c.chainID, "rpc_addr", c.endpoints[i].addr, "error", err.Error())
+	c.endpoints[i].healthy = false
+	c.checkedAt = time.Time{}
+	c.failover(ctx)
+}
+
+// call sends a call at `height` to the endpoints picked by `pick` until it succeeds or fails with an error returned by the node
+func (c *failoverRPCClient) call(ctx context.Context, sticky bool, height int64, fn func(rpcclient.Client) error) error {
+	var err error
+	for _, i := range c.pick(ctx, sticky, height) {
+		if err = fn(c.endpoints[i].client); !isEndpointError(ctx, err) {
+			return err
+		}
+		c.markFailed(ctx, i, err)
+	}
+	return err
+}
+
+// isEndpointError returns true if `err` shows that the endpoint is unavailable.
+// An error returned by the node as a response (e.g. a tx not found) is not an endpoint error.
+func isEndpointError(ctx context.Context, err error) bool {
+	var rpcErr *rpctypes.RPCError
+	return err != nil && ctx.Err() == nil && !errors.As(err, &rpcErr)
+}
+
+// heightOf returns the height of a call, which is zero for the latest height
+func heightOf(height *int64) int64 {
+	if height == nil {
+		return 0
+	}
+	return *height
+}
+
+func (c *failoverRPCClient) Status(ctx context.Context) (res *coretypes.ResultStatus, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.Status(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) ABCIQuery(ctx context.Context, path string, data bytes.HexBytes) (res *coretypes.ResultABCIQuery, err error) {
+	err = c.call(ctx, false, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.ABCIQuery(ctx, path, data)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) ABCIQueryWithOptions(ctx context.Context, path string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (res *coretypes.ResultABCIQuery, err error) {
+	err = c.call(ctx, false, opts.Height, func(client rpcclient.Client) (err error) {
+		res, err = client.ABCIQueryWithOptions(ctx, path, data, opts)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) BroadcastTxSync(ctx context.Context, tx tmtypes.Tx) (res *coretypes.ResultBroadcastTx, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.BroadcastTxSync(ctx, tx)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) Block(ctx context.Context, height *int64) (res *coretypes.ResultBlock, err error) {
+	err = c.call(ctx, false, heightOf(height), func(client rpcclient.Client) (err error) {
+		res, err = client.Block(ctx, height)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) BlockResults(ctx context.Context, height *int64) (res *coretypes.ResultBlockResults, err error) {
+	err = c.call(ctx, false, heightOf(height), func(client rpcclient.Client) (err error) {
+		res, err = client.BlockResults(ctx, height)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) Header(ctx context.Context, height *int64) (res *coretypes.ResultHeader, err error) {
+	err = c.call(ctx, false, heightOf(height), func(client rpcclient.Client) (err error) {
+		res, err = client.Header(ctx, height)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) Commit(ctx context.Context, height *int64) (res *coretypes.ResultCommit, err error) {
+	err = c.call(ctx, false, heightOf(height), func(client rpcclient.Client) (err error) {
+		res, err = client.Commit(ctx, height)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) Validators(ctx context.Context, height *int64, page, perPage *int) (res *coretypes.ResultValidators, err error) {
+	err = c.call(ctx, false, heightOf(height), func(client rpcclient.Client) (err error) {
+		res, err = client.Validators(ctx, height, page, perPage)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) Tx(ctx context.Context, hash []byte, prove bool) (res *coretypes.ResultTx, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.Tx(ctx, hash, prove)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) TxSearch(ctx context.Context, query string, prove bool, page, perPage *int, orderBy string) (res *coretypes.ResultTxSearch, err error) {
+	err = c.call(ctx, false, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.TxSearch(ctx, query, prove, page, perPage, orderBy)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) ABCIInfo(ctx context.Context) (res *coretypes.ResultABCIInfo, err error) {
+	err = c.call(ctx, false, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.ABCIInfo(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) BroadcastTxCommit(ctx context.Context, tx tmtypes.Tx) (res *coretypes.ResultBroadcastTxCommit, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.BroadcastTxCommit(ctx, tx)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) BroadcastTxAsync(ctx context.Context, tx tmtypes.Tx) (res *coretypes.ResultBroadcastTx, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.BroadcastTxAsync(ctx, tx)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) BlockByHash(ctx context.Context, hash []byte) (res *coretypes.ResultBlock, err error) {
+	err = c.call(ctx, false, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.BlockByHash(ctx, hash)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) HeaderByHash(ctx context.Context, hash bytes.HexBytes) (res *coretypes.ResultHeader, err error) {
+	err = c.call(ctx, false, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.HeaderByHash(ctx, hash)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) BlockSearch(ctx context.Context, query string, page, perPage *int, orderBy string) (res *coretypes.ResultBlockSearch, err error) {
+	err = c.call(ctx, false, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.BlockSearch(ctx, query, page, perPage, orderBy)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) Genesis(ctx context.Context) (res *coretypes.ResultGenesis, err error) {
+	err = c.call(ctx, false, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.Genesis(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) GenesisChunked(ctx context.Context, id uint) (res *coretypes.ResultGenesisChunk, err error) {
+	err = c.call(ctx, false, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.GenesisChunked(ctx, id)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) BlockchainInfo(ctx context.Context, minHeight, maxHeight int64) (res *coretypes.ResultBlockchainInfo, err error) {
+	err = c.call(ctx, false, maxHeight, func(client rpcclient.Client) (err error) {
+		res, err = client.BlockchainInfo(ctx, minHeight, maxHeight)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) NetInfo(ctx context.Context) (res *coretypes.ResultNetInfo, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.NetInfo(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) DumpConsensusState(ctx context.Context) (res *coretypes.ResultDumpConsensusState, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.DumpConsensusState(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) ConsensusState(ctx context.Context) (res *coretypes.ResultConsensusState, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.ConsensusState(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) ConsensusParams(ctx context.Context, height *int64) (res *coretypes.ResultConsensusParams, err error) {
+	err = c.call(ctx, false, heightOf(height), func(client rpcclient.Client) (err error) {
+		res, err = client.ConsensusParams(ctx, height)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) Health(ctx context.Context) (res *coretypes.ResultHealth, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.Health(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) Subscribe(ctx context.Context, subscriber, query string, outCapacity ...int) (res <-chan coretypes.ResultEvent, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.Subscribe(ctx, subscriber, query, outCapacity...)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) Unsubscribe(ctx context.Context, subscriber, query string) error {
+	return c.call(ctx, true, 0, func(client rpcclient.Client) error {
+		return client.Unsubscribe(ctx, subscriber, query)
+	})
+}
+
+func (c *failoverRPCClient) UnsubscribeAll(ctx context.Context, subscriber string) error {
+	return c.call(ctx, true, 0, func(client rpcclient.Client) error {
+		return client.UnsubscribeAll(ctx, subscriber)
+	})
+}
+
+func (c *failoverRPCClient) UnconfirmedTxs(ctx context.Context, limit *int) (res *coretypes.ResultUnconfirmedTxs, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.UnconfirmedTxs(ctx, limit)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) NumUnconfirmedTxs(ctx context.Context) (res *coretypes.ResultUnconfirmedTxs, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.NumUnconfirmedTxs(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) CheckTx(ctx context.Context, tx tmtypes.Tx) (res *coretypes.ResultCheckTx, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.CheckTx(ctx, tx)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverRPCClient) BroadcastEvidence(ctx context.Context, ev tmtypes.Evidence) (res *coretypes.ResultBroadcastEvidence, err error) {
+	err = c.call(ctx, true, 0, func(client rpcclient.Client) (err error) {
+		res, err = client.BroadcastEvidence(ctx, ev)
+		return err
+	})
+	return res, err
+}
+
+// each applies `fn` to the clients of all the endpoints, and returns the errors joined
+func (c *failoverRPCClient) each(fn func(rpcclient.Client) error) error {
+	var errs []error
+	for _, e := range c.endpoints {
+		if err := fn(e.client); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// currentClient returns the client of the current endpoint
+func (c *failoverRPCClient) currentClient() rpcclient.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.endpoints[c.current].client
+}
+
+func (c *failoverRPCClient) Start() error {
+	return c.each(rpcclient.Client.Start)
+}
+
+func (c *failoverRPCClient) OnStart() error {
+	return c.each(rpcclient.Client.OnStart)
+}
+
+func (c *failoverRPCClient) Stop() error {
+	return c.each(rpcclient.Client.Stop)
+}
+
+func (c *failoverRPCClient) OnStop() {
+	for _, e := range c.endpoints {
+		e.client.OnStop()
+	}
+}
+
+func (c *failoverRPCClient) Reset() error {
+	return c.each(rpcclient.Client.Reset)
+}
+
+func (c *failoverRPCClient) OnReset() error {
+	return c.each(rpcclient.Client.OnReset)
+}
+
+func (c *failoverRPCClient) IsRunning() bool {
+	return c.currentClient().IsRunning()
+}
+
+func (c *failoverRPCClient) Quit() <-chan struct{} {
+	return c.currentClient().Quit()
+}
+
+func (c *failoverRPCClient) String() string {
+	return fmt.Sprintf("failoverRPCClient{chain_id=%s, rpc_addr=%s}", c.chainID, c.Addr())
+}
+
+func (c *failoverRPCClient) SetLogger(logger cmtlog.Logger) {
+	for _, e := range c.endpoints {
+		e.client.SetLogger(logger)
+	}
+}
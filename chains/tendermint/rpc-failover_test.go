@@ -0,0 +1,206 @@
+package tendermint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+// rpcStub is a stub of an RPC endpoint that serves `status`, `abci_query` and `abci_info`
+type rpcStub struct {
+	*httptest.Server
+	height     int64
+	catchingUp bool
+	queries    atomic.Int64
+	statuses   atomic.Int64
+	// release blocks `status` until it is closed if it is not nil
+	release chan struct{}
+}
+
+func newRPCStub(t *testing.T, height int64, catchingUp bool) *rpcStub {
+	stub := &rpcStub{height: height, catchingUp: catchingUp}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var result string
+		switch req.Method {
+		case "status":
+			stub.statuses.Add(1)
+			if stub.release != nil {
+				<-stub.release
+			}
+			result = fmt.Sprintf(`{"node_info":{},"sync_info":{"latest_block_height":"%d","latest_block_time":"%s","catching_up":%v},"validator_info":{}}`,
+				stub.height, time.Now().UTC().Format(time.RFC3339Nano), stub.catchingUp)
+		case "abci_query":
+			stub.queries.Add(1)
+			result = fmt.Sprintf(`{"response":{"height":"%d"}}`, stub.height)
+		case "abci_info":
+			stub.queries.Add(1)
+			result = fmt.Sprintf(`{"response":{"last_block_height":"%d"}}`, stub.height)
+		default:
+			http.Error(w, "unsupported method", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func newTestFailoverRPCClient(t *testing.T, spreadQueries bool, stubs ...*rpcStub) *failoverRPCClient {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		addrs   []string
+		clients []rpcclient.Client
+	)
+	for _, stub := range stubs {
+		client, err := newRPCClient(stub.URL, time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs = append(addrs, stub.URL)
+		clients = append(clients, client)
+	}
+	return newFailoverRPCClient("ibc0", addrs, clients, spreadQueries, time.Minute)
+}
+
+func TestFailoverRPCClientUnhealthyEndpoints(t *testing.T) {
+	cases := []struct {
+		name     string
+		primary  *rpcStub
+		expected int64
+	}{
+		{"catching up", newRPCStub(t, 100, true), 100},
+		{"lagging", newRPCStub(t, 90, false), 100},
+	}
+	for _, c := range cases {
+		backup := newRPCStub(t, 100, false)
+		client := newTestFailoverRPCClient(t, false, c.primary, backup)
+
+		res, err := client.Status(context.TODO())
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if res.SyncInfo.LatestBlockHeight != c.expected || client.Addr() != backup.URL {
+			t.Errorf("%s: unexpected endpoint: addr=%s, height=%d", c.name, client.Addr(), res.SyncInfo.LatestBlockHeight)
+		}
+	}
+}
+
+func TestFailoverRPCClientEndpointDown(t *testing.T) {
+	primary, backup := newRPCStub(t, 100, false), newRPCStub(t, 100, false)
+	client := newTestFailoverRPCClient(t, false, primary, backup)
+
+	if _, err := client.ABCIQuery(context.TODO(), "/store/ibc/key", nil); err != nil {
+		t.Fatal(err)
+	}
+	if client.Addr() != primary.URL || primary.queries.Load() != 1 {
+		t.Fatalf("the primary endpoint is expected to be used: addr=%s", client.Addr())
+	}
+
+	primary.Close()
+	if _, err := client.ABCIQuery(context.TODO(), "/store/ibc/key", nil); err != nil {
+		t.Fatalf("the query is expected to be retried on the backup endpoint: %v", err)
+	}
+	if client.Addr() != backup.URL || backup.queries.Load() != 1 {
+		t.Errorf("the client is expected to fail over to the backup endpoint: addr=%s", client.Addr())
+	}
+}
+
+// TestFailoverRPCClientOtherMethods checks that the methods not used for relaying are also sent to the current endpoint
+func TestFailoverRPCClientOtherMethods(t *testing.T) {
+	primary, backup := newRPCStub(t, 100, false), newRPCStub(t, 100, false)
+	client := newTestFailoverRPCClient(t, false, primary, backup)
+
+	primary.Close()
+	if _, err := client.ABCIQuery(context.TODO(), "/store/ibc/key", nil); err != nil {
+		t.Fatal(err)
+	}
+	if client.Addr() != backup.URL {
+		t.Fatalf("the client is expected to fail over to the backup endpoint: addr=%s", client.Addr())
+	}
+	if res, err := client.ABCIInfo(context.TODO()); err != nil {
+		t.Fatalf("abci_info is expected to be sent to the backup endpoint: %v", err)
+	} else if res.Response.LastBlockHeight != 100 || backup.queries.Load() != 2 {
+		t.Errorf("unexpected response: %v", res.Response)
+	}
+}
+
+func TestFailoverRPCClientSpreadQueries(t *testing.T) {
+	stubs := []*rpcStub{newRPCStub(t, 100, false), newRPCStub(t, 100, false), newRPCStub(t, 100, true)}
+	client := newTestFailoverRPCClient(t, true, stubs...)
+
+	for i := 0; i < 4; i++ {
+		if _, err := client.ABCIQuery(context.TODO(), "/store/ibc/key", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stubs[0].queries.Load() != 2 || stubs[1].queries.Load() != 2 || stubs[2].queries.Load() != 0 {
+		t.Errorf("queries are expected to be spread across the healthy endpoints: %d, %d, %d",
+			stubs[0].queries.Load(), stubs[1].queries.Load(), stubs[2].queries.Load())
+	}
+}
+
+func TestFailoverRPCClientSpreadQueriesAtHeight(t *testing.T) {
+	// the lagging endpoint is still healthy
+	latest, lagging := newRPCStub(t, 100, false), newRPCStub(t, 98, false)
+	client := newTestFailoverRPCClient(t, true, latest, lagging)
+
+	for i := 0; i < 4; i++ {
+		if _, err := client.ABCIQueryWithOptions(context.TODO(), "/store/ibc/key", nil, rpcclient.ABCIQueryOptions{Height: 100}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if latest.queries.Load() != 4 || lagging.queries.Load() != 0 {
+		t.Errorf("queries at height 100 are expected to be sent only to the endpoint at the height: %d, %d", latest.queries.Load(), lagging.queries.Load())
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.ABCIQueryWithOptions(context.TODO(), "/store/ibc/key", nil, rpcclient.ABCIQueryOptions{Height: 98}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lagging.queries.Load() != 1 {
+		t.Errorf("queries at height 98 are expected to be spread: %d, %d", latest.queries.Load(), lagging.queries.Load())
+	}
+}
+
+func TestFailoverRPCClientProbeWithoutLock(t *testing.T) {
+	slow, backup := newRPCStub(t, 100, false), newRPCStub(t, 100, false)
+	slow.release = make(chan struct{})
+	client := newTestFailoverRPCClient(t, false, slow, backup)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = client.ABCIQuery(context.TODO(), "/store/ibc/key", nil)
+	}()
+	for slow.statuses.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	addr := make(chan string, 1)
+	go func() { addr <- client.Addr() }()
+	select {
+	case <-addr:
+	case <-time.After(500 * time.Millisecond):
+		t.Error("the client is expected not to be locked while probing the endpoints")
+	}
+	close(slow.release)
+	<-done
+}
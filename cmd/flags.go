@@ -16,6 +16,10 @@ const (
 	flagTimeoutHeightOffset = "timeout-height-offset"
 	flagTimeoutTimeOffset   = "timeout-time-offset"
 	flagIBCDenoms           = "ibc-denoms"
+	flagMetricsExporter     = "metrics-exporter"
+	flagStatus              = "status"
+	flagDelayPeriod         = "delay-period"
+	flagFee                 = "fee"
 )
 
 func heightFlag(cmd *cobra.Command) *cobra.Command {
@@ -26,6 +30,14 @@ func heightFlag(cmd *cobra.Command) *cobra.Command {
 	return cmd
 }
 
+func statusFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagStatus, false, "include the status of each path in the json/yaml output")
+	if err := viper.BindPFlag(flagStatus, cmd.Flags().Lookup(flagStatus)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
 func fileFlag(cmd *cobra.Command) *cobra.Command {
 	cmd.Flags().StringP(flagFile, "f", "", "fetch json data from specified file")
 	if err := viper.BindPFlag(flagFile, cmd.Flags().Lookup(flagFile)); err != nil {
@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
 
 	"github.com/hyperledger-labs/yui-relayer/config"
 	"github.com/hyperledger-labs/yui-relayer/core"
@@ -26,6 +31,9 @@ connection, and channel ids from both the source and destination chains as well
 		pathsListCmd(ctx),
 		pathsAddCmd(ctx),
 		pathsEditCmd(ctx),
+		pathsGenerateCmd(ctx),
+		pathsQuarantineCmd(ctx),
+		pathsReleaseCmd(ctx),
 	)
 
 	return cmd
@@ -36,43 +44,101 @@ func pathsListCmd(ctx *config.Context) *cobra.Command {
 		Use:     "list",
 		Aliases: []string{"l"},
 		Short:   "print out configured paths",
+		Long: `Print out configured paths with their statuses in a table.
+The status of each path shows if both chains are reachable, both clients are active,
+the connection and the channel are OPEN on both chains, and a channel upgrade is in progress.
+With --json or --yaml, the path configurations are printed, and --status adds their statuses.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			jsn, _ := cmd.Flags().GetBool(flagJSON)
 			yml, _ := cmd.Flags().GetBool(flagYAML)
-			switch {
-			case yml && jsn:
+			status, _ := cmd.Flags().GetBool(flagStatus)
+			if yml && jsn {
 				return fmt.Errorf("can't pass both --json and --yaml, must pick one")
-			case yml:
-				out, err := yaml.Marshal(ctx.Config.Paths)
+			}
+
+			var out any = ctx.Config.Paths
+			if status || !(yml || jsn) {
+				statuses, err := queryPathStatuses(cmd.Context(), ctx)
 				if err != nil {
 					return err
 				}
-				fmt.Println(string(out))
-				return nil
-			case jsn:
-				out, err := json.Marshal(ctx.Config.Paths)
+				out = statuses
+				if !(yml || jsn) {
+					printPathStatuses(statuses)
+					return nil
+				}
+			}
+
+			if yml {
+				bz, err := yaml.Marshal(out)
 				if err != nil {
 					return err
 				}
-				fmt.Println(string(out))
-				return nil
-			default:
-				// i := 0
-				// for k, pth := range configInstance.Paths {
-				// 	chains, err := configInstance.GetChains(pth.Src.ChainID, pth.Dst.ChainID)
-				// 	if err != nil {
-				// 		return err
-				// 	}
-				// 	stat := pth.QueryPathStatus(chains[pth.Src.ChainID], chains[pth.Dst.ChainID]).Status
-				// 	printPath(i, k, pth, checkmark(stat.Chains), checkmark(stat.Clients), checkmark(stat.Connection), checkmark(stat.Channel))
-				// 	i++
-				// }
-				// return nil
-				return fmt.Errorf("not implemented error")
+				fmt.Println(string(bz))
+			} else {
+				bz, err := json.Marshal(out)
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(bz))
 			}
+			return nil
 		},
 	}
-	return yamlFlag(jsonFlag(cmd))
+	return statusFlag(yamlFlag(jsonFlag(cmd)))
+}
+
+func queryPathStatuses(cmdCtx context.Context, ctx *config.Context) (map[string]*core.PathWithStatus, error) {
+	statuses := make(map[string]*core.PathWithStatus)
+	for name, pth := range ctx.Config.Paths {
+		chains, src, dst, err := ctx.Config.ChainsFromPath(name)
+		if err != nil {
+			return nil, err
+		}
+		statuses[name] = pth.QueryPathStatus(cmdCtx, chains[src], chains[dst])
+	}
+	return statuses, nil
+}
+
+func printPathStatuses(statuses map[string]*core.PathWithStatus) {
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tNAME\tSRC\tDST\tCHAINS\tCLIENTS\tCONNECTION\tCHANNEL\tUPGRADING")
+	for i, name := range names {
+		pth, st := statuses[name].Path, statuses[name].Status
+		fmt.Fprintf(w, "%d\t%s\t%s:%s\t%s:%s\t%s\t%s\t%s\t%s\t%s\n",
+			i, name,
+			pth.Src.ChainID, pth.Src.ChannelID,
+			pth.Dst.ChainID, pth.Dst.ChannelID,
+			checkmark(st.Chains), checkmark(st.Clients), checkmark(st.Connection), checkmark(st.Channel), yesNo(st.Upgrading),
+		)
+	}
+	w.Flush()
+
+	for _, name := range names {
+		for _, e := range statuses[name].Status.Errors {
+			fmt.Printf("%s: %s\n", name, e)
+		}
+	}
+}
+
+func checkmark(ok bool) string {
+	if ok {
+		return "✔"
+	}
+	return "✘"
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
 }
 
 func pathsAddCmd(ctx *config.Context) *cobra.Command {
@@ -150,6 +216,188 @@ func pathsEditCmd(ctx *config.Context) *cobra.Command {
 	return cmd
 }
 
+func pathsGenerateCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagAdd = "add"
+	)
+	cmd := &cobra.Command{
+		Use:     "generate [src-chain-id] [dst-chain-id]",
+		Aliases: []string{"gen"},
+		Short:   "generate path configurations from the clients, connections and channels on the chains",
+		Long: `Enumerate the clients, connections and channels on both chains, and print the paths made of the ones referencing each other with their states.
+A connection without any channel is printed as a path without channel, which can't be added until its channel is configured.
+Pass the numbers of the printed paths to --add to add them to the config as '<src-chain-id>-<dst-chain-id>-<src-channel-id>'.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jsn, _ := cmd.Flags().GetBool(flagJSON)
+			yml, _ := cmd.Flags().GetBool(flagYAML)
+			if yml && jsn {
+				return fmt.Errorf("can't pass both --json and --yaml, must pick one")
+			}
+			add, err := cmd.Flags().GetIntSlice(flagAdd)
+			if err != nil {
+				return err
+			}
+
+			chains, err := ctx.Config.GetChains(args[0], args[1])
+			if err != nil {
+				return fmt.Errorf("chains need to be configured before paths to them can be generated: %w", err)
+			}
+			paths, err := core.GeneratePaths(cmd.Context(), chains[args[0]], chains[args[1]])
+			if err != nil {
+				return err
+			}
+
+			if len(add) > 0 {
+				for _, i := range add {
+					if i < 0 || i >= len(paths) {
+						return fmt.Errorf("path #%d not found", i)
+					}
+					pth := paths[i].Path
+					name := fmt.Sprintf("%s-%s-%s", pth.Src.ChainID, pth.Dst.ChainID, pth.Src.ChannelID)
+					if err := ctx.Config.AddPath(name, pth); err != nil {
+						return fmt.Errorf("failed to add path #%d: %w", i, err)
+					}
+					fmt.Printf("added path %s\n", name)
+				}
+				return ctx.Config.OverWriteConfig()
+			}
+
+			switch {
+			case yml:
+				bz, err := yaml.Marshal(paths)
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(bz))
+			case jsn:
+				bz, err := json.Marshal(paths)
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(bz))
+			default:
+				printGeneratedPaths(ctx.Config.Paths, paths)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntSlice(flagAdd, nil, "numbers of the generated paths to add to the config")
+	return yamlFlag(jsonFlag(cmd))
+}
+
+func printGeneratedPaths(configured core.Paths, paths []*core.GeneratedPath) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tSRC\tDST\tORDER\tVERSION\tSRC STATE\tDST STATE\tCONFIGURED")
+	for i, gp := range paths {
+		pth := gp.Path
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			i, formatGeneratedPathEnd(pth.Src), formatGeneratedPathEnd(pth.Dst),
+			pth.Src.Order, pth.Src.Version, gp.SrcState, gp.DstState, configuredPathName(configured, pth),
+		)
+	}
+	w.Flush()
+}
+
+func formatGeneratedPathEnd(pe *core.PathEnd) string {
+	s := fmt.Sprintf("%s:%s:%s", pe.ChainID, pe.ClientID, pe.ConnectionID)
+	if pe.ChannelID != "" {
+		s += fmt.Sprintf(":%s/%s", pe.PortID, pe.ChannelID)
+	}
+	return s
+}
+
+// configuredPathName returns the name of the configured path that has the same ends as `pth`, or "-" if not found
+func configuredPathName(configured core.Paths, pth *core.Path) string {
+	for name, p := range configured {
+		for _, ends := range [][2]*core.PathEnd{{p.Src, p.Dst}, {p.Dst, p.Src}} {
+			if samePathEnd(ends[0], pth.Src) && samePathEnd(ends[1], pth.Dst) {
+				return name
+			}
+		}
+	}
+	return "-"
+}
+
+func samePathEnd(a, b *core.PathEnd) bool {
+	return a.ChainID == b.ChainID && a.ClientID == b.ClientID && a.ConnectionID == b.ConnectionID &&
+		a.PortID == b.PortID && a.ChannelID == b.ChannelID
+}
+
+func pathsQuarantineCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "quarantine [path-name]",
+		Short: "print out the packets quarantined on the path",
+		Long: `Print out the packets whose msgs made txs fail on either chain of the path.
+The relay service excludes the failing msg from the tx and retries the others.
+If the msg was rejected by the application deterministically, it is quarantined with its msg type,
+and is no longer relayed until it is released by 'paths release'. MsgTimeout is never quarantined.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pth, err := ctx.Config.Paths.Get(args[0])
+			if err != nil {
+				return err
+			}
+			if jsn, _ := cmd.Flags().GetBool(flagJSON); jsn {
+				bz, err := json.Marshal(map[string][]*core.QuarantinedPacket{
+					pth.Src.ChainID: pth.Src.Quarantine,
+					pth.Dst.ChainID: pth.Dst.Quarantine,
+				})
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(bz))
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CHAIN\tSOURCE PORT\tSOURCE CHANNEL\tSEQUENCE\tMSG TYPE\tQUARANTINED AT\tREASON")
+			for _, pe := range []*core.PathEnd{pth.Src, pth.Dst} {
+				for _, q := range pe.Quarantine {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+						pe.ChainID, q.SourcePort, q.SourceChannel, q.Sequence, q.MsgType, q.QuarantinedAt.Format(time.RFC3339), q.Reason,
+					)
+				}
+			}
+			return w.Flush()
+		},
+	}
+	return jsonFlag(cmd)
+}
+
+func pathsReleaseCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release [path-name] [chain-id] [sequence]",
+		Short: "release quarantined packets so that they are relayed again",
+		Long: `Remove the packets of [sequence] from the quarantine of [chain-id] in the path config.
+A running relay service keeps its quarantine in memory, so it has to be restarted to relay the released packets.`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pth, err := ctx.Config.Paths.Get(args[0])
+			if err != nil {
+				return err
+			}
+			var pathEnd *core.PathEnd
+			switch args[1] {
+			case pth.Src.ChainID:
+				pathEnd = pth.Src
+			case pth.Dst.ChainID:
+				pathEnd = pth.Dst
+			default:
+				return fmt.Errorf("invalid chain ID: %s or %s was expected, but %s was given", pth.Src.ChainID, pth.Dst.ChainID, args[1])
+			}
+			seq, err := strconv.ParseUint(args[2], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid sequence: %v", err)
+			}
+			if pathEnd.ReleasePacket(seq) == 0 {
+				return fmt.Errorf("packet %d is not quarantined on chain %s", seq, args[1])
+			}
+			return ctx.Config.OverWriteConfig()
+		},
+	}
+	return cmd
+}
+
 func fileInputPathAdd(config *config.Config, file, name string) error {
 	// If the user passes in a file, attempt to read the chain config from that file
 	p := &core.Path{}
@@ -3,6 +3,9 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -29,6 +32,7 @@ func queryCmd(ctx *config.Context) *cobra.Command {
 		queryBalanceCmd(ctx),
 		queryUnrelayedPackets(ctx),
 		queryUnrelayedAcknowledgements(ctx),
+		queryPacketCmd(ctx),
 		flags.LineBreak,
 		queryClientCmd(ctx),
 		queryConnection(ctx),
@@ -333,3 +337,81 @@ func queryUnrelayedAcknowledgements(ctx *config.Context) *cobra.Command {
 
 	return cmd
 }
+
+func queryPacketCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "packet [path-name] [chain-id] [sequence]",
+		Short: "Query the lifecycle of a packet sent from a chain in a given path",
+		Long: `Follow the packet [sequence] sent from [chain-id] across both chains of the path.
+It shows the send tx, whether the packet commitment exists, the recv tx or the timeout on the counterparty chain,
+the written acknowledgement and the ack tx back on [chain-id], and whether each step is finalized according to the prover.`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chains, srcID, dstID, err := ctx.Config.ChainsFromPath(args[0])
+			if err != nil {
+				return err
+			}
+			var src, dst *core.ProvableChain
+			switch args[1] {
+			case srcID:
+				src, dst = chains[srcID], chains[dstID]
+			case dstID:
+				src, dst = chains[dstID], chains[srcID]
+			default:
+				return fmt.Errorf("invalid chain ID: %s or %s was expected, but %s was given", srcID, dstID, args[1])
+			}
+			seq, err := strconv.ParseUint(args[2], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid sequence: %v", err)
+			}
+
+			trace, err := core.TracePacket(cmd.Context(), src, dst, seq)
+			if err != nil {
+				return err
+			}
+			if jsn, _ := cmd.Flags().GetBool(flagJSON); jsn {
+				out, err := json.Marshal(trace)
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+			printPacketTrace(trace)
+			return nil
+		},
+	}
+	return jsonFlag(cmd)
+}
+
+func printPacketTrace(trace *core.PacketTrace) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STEP\tCHAIN\tHEIGHT\tTX\tFINALIZED")
+	for _, step := range []struct {
+		name string
+		step *core.PacketStep
+	}{
+		{"send", trace.Send},
+		{"recv", trace.Recv},
+		{"write-ack", trace.WriteAck},
+		{"ack", trace.Ack},
+		{"timeout", trace.Timeout},
+	} {
+		if step.step == nil {
+			if step.name != "timeout" {
+				fmt.Fprintf(w, "%s\t-\t-\t-\t-\n", step.name)
+			}
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", step.name, step.step.ChainID, step.step.Height, step.step.TxHash, yesNo(step.step.Finalized))
+	}
+	w.Flush()
+
+	fmt.Printf("commitment exists: %s\n", yesNo(trace.CommitmentExists))
+	if trace.Recv == nil {
+		fmt.Printf("timed out: %s\n", yesNo(trace.TimedOut))
+	}
+	if trace.Acknowledgement != "" {
+		fmt.Printf("acknowledgement: %s\n", trace.Acknowledgement)
+	}
+}
@@ -9,11 +9,13 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/hyperledger-labs/yui-relayer/config"
 	"github.com/hyperledger-labs/yui-relayer/core"
 	"github.com/hyperledger-labs/yui-relayer/log"
 	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"github.com/hyperledger-labs/yui-relayer/tracing"
 	"golang.org/x/sys/unix"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
@@ -22,15 +24,19 @@ import (
 )
 
 var (
-	homePath    string
-	debug       bool
-	defaultHome = os.ExpandEnv("$HOME/.yui-relayer")
-	configPath  = "config/config.json"
+	homePath        string
+	debug           bool
+	metricsExporter string
+	defaultHome     = os.ExpandEnv("$HOME/.yui-relayer")
+	configPath      = "config/config.json"
 )
 
+// shutdownTimeout is the time allowed for flushing the metrics and the spans when the command exits
+const shutdownTimeout = 10 * time.Second
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // It can support any chain by giving modules.
-func Execute(modules ...config.ModuleI) error {
+func Execute(modules ...config.ModuleI) (err error) {
 	// rootCmd represents the base command when called without any subcommands
 	var rootCmd = &cobra.Command{
 		Use:   "yrly",
@@ -45,6 +51,7 @@ func Execute(modules ...config.ModuleI) error {
 	// Register top level flags --home and --debug
 	rootCmd.PersistentFlags().StringVar(&homePath, flags.FlagHome, defaultHome, "set home directory")
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "debug output")
+	rootCmd.PersistentFlags().StringVar(&metricsExporter, flagMetricsExporter, "", "metrics exporter (null, prometheus or otlp) overriding the one in the config file")
 	if err := viper.BindPFlag(flags.FlagHome, rootCmd.PersistentFlags().Lookup(flags.FlagHome)); err != nil {
 		return err
 	}
@@ -78,6 +85,9 @@ func Execute(modules ...config.ModuleI) error {
 		}
 	}
 
+	// shutdowns are the functions that shut down the subsystems initialized before running the command, in the order of initialization
+	var shutdowns []func(ctx context.Context) error
+
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
 		// reads `homeDir/config/config.json` into `var config *Config` before each command
 		if err := viper.BindPFlags(cmd.Flags()); err != nil {
@@ -89,21 +99,52 @@ func Execute(modules ...config.ModuleI) error {
 		if err := initLogger(ctx); err != nil {
 			return err
 		}
+		shutdowns = append(shutdowns, func(context.Context) error {
+			if err := log.CloseFile(); err != nil {
+				return fmt.Errorf("failed to close the log file: %v", err)
+			}
+			return nil
+		})
+		shutdowns = append(shutdowns, func(context.Context) error {
+			if err := ctx.Config.CloseChains(); err != nil {
+				return fmt.Errorf("failed to close the chains: %v", err)
+			}
+			return nil
+		})
 		if err := ctx.InitConfig(homePath, debug); err != nil {
 			return fmt.Errorf("failed to initialize the configuration: %v", err)
 		}
-		if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		if err := initMetrics(ctx); err != nil {
 			return fmt.Errorf("failed to initialize the metrics: %v", err)
 		}
+		shutdowns = append(shutdowns, func(ctx context.Context) error {
+			if err := metrics.ShutdownMetrics(ctx); err != nil {
+				return fmt.Errorf("failed to shutdown the metrics subsystem: %v", err)
+			}
+			return nil
+		})
+		if err := initTracing(ctx); err != nil {
+			return fmt.Errorf("failed to initialize the tracing: %v", err)
+		}
+		shutdowns = append(shutdowns, func(ctx context.Context) error {
+			if err := tracing.ShutdownTracing(ctx); err != nil {
+				return fmt.Errorf("failed to shutdown the tracing subsystem: %v", err)
+			}
+			return nil
+		})
 		cmd.SetContext(notifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM))
 		return nil
 	}
-	rootCmd.PersistentPostRunE = func(cmd *cobra.Command, _ []string) error {
-		if err := metrics.ShutdownMetrics(cmd.Context()); err != nil {
-			return fmt.Errorf("failed to shutdown the metrics subsystem: %v", err)
+
+	// the subsystems are shut down even if the command fails, with a fresh context
+	// because the context of the command has been cancelled on SIGINT or SIGTERM
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		for i := len(shutdowns) - 1; i >= 0; i-- {
+			err = errors.Join(err, shutdowns[i](shutdownCtx))
 		}
-		return nil
-	}
+	}()
 
 	return rootCmd.Execute()
 }
@@ -116,7 +157,76 @@ func readStdin() (string, error) {
 
 func initLogger(ctx *config.Context) error {
 	c := ctx.Config.Global.LoggerConfig
-	return log.InitLogger(c.Level, c.Format, c.Output)
+	if c.Output == "file" {
+		fileConf, err := c.File.FileConfig()
+		if err != nil {
+			return err
+		}
+		if err := log.InitFileLogger(c.Level, c.Format, fileConf); err != nil {
+			return err
+		}
+	} else if err := log.InitLogger(c.Level, c.Format, c.Output); err != nil {
+		return err
+	}
+	return log.SetModuleLevels(c.ModuleLevels)
+}
+
+// reloadLogLevelsOnSignal re-reads the config file and applies its log levels to the global logger whenever one of `signals` is received
+func reloadLogLevelsOnSignal(ctx context.Context, signals ...os.Signal) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, signals...)
+
+	go func() {
+		defer signal.Stop(sigChan)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigChan:
+			}
+			logger := log.GetLogger()
+			var c config.Config
+			if err := c.UnmarshalConfig(homePath, configPath); err != nil {
+				logger.Error("failed to read the config file to reload log levels", err)
+				continue
+			}
+			lc := c.Global.LoggerConfig
+			if err := log.SetLevel(lc.Level); err != nil {
+				logger.Error("failed to reload the log level", err)
+				continue
+			}
+			if err := log.SetModuleLevels(lc.ModuleLevels); err != nil {
+				logger.Error("failed to reload the module log levels", err)
+				continue
+			}
+			logger.Info("reloaded log levels", "level", lc.Level, "module_levels", lc.ModuleLevels)
+		}
+	}()
+}
+
+// metricsConfig returns the metrics config in which the exporter is overridden by the --metrics-exporter flag if specified
+func metricsConfig(ctx *config.Context) config.MetricsConfig {
+	c := ctx.Config.Global.MetricsConfig
+	if metricsExporter != "" {
+		c.Exporter = metricsExporter
+	}
+	return c
+}
+
+func initMetrics(ctx *config.Context) error {
+	exporterConf, err := metricsConfig(ctx).ExporterConfig()
+	if err != nil {
+		return err
+	}
+	return metrics.InitializeMetrics(exporterConf)
+}
+
+func initTracing(ctx *config.Context) error {
+	exporterConf, err := ctx.Config.Global.TracingConfig.ExporterConfig()
+	if err != nil {
+		return err
+	}
+	return tracing.InitializeTracing(exporterConf)
 }
 
 func noCommand(cmd *cobra.Command, args []string) error {
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"syscall"
 	"time"
 
 	"github.com/hyperledger-labs/yui-relayer/config"
@@ -32,6 +33,9 @@ func startCmd(ctx *config.Context) *cobra.Command {
 		flagSrcRelayOptimizeCount    = "src-relay-optimize-count"
 		flagDstRelayOptimizeInterval = "dst-relay-optimize-interval"
 		flagDstRelayOptimizeCount    = "dst-relay-optimize-count"
+		flagRelayBackoff             = "relay-backoff"
+		flagTimeoutUrgency           = "timeout-urgency"
+		flagMaxPacketsPerCycle       = "max-packets-per-cycle"
 	)
 	const (
 		defaultRelayInterval         = 3 * time.Second
@@ -41,14 +45,21 @@ func startCmd(ctx *config.Context) *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:  "start [path-name]",
-		Args: cobra.ExactArgs(1),
+		Use:   "start [path-name]",
+		Short: "Start the relay service for the path",
+		Long:  "Start the relay service for the path. Sending SIGHUP to the process reloads the log levels from the config file.",
+		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := metrics.ShutdownMetrics(cmd.Context()); err != nil {
-				return fmt.Errorf("failed to shutdown the metrics subsystem with null exporter: %v", err)
-			}
-			if err := metrics.InitializeMetrics(metrics.ExporterProm{Addr: viper.GetString(flagPrometheusAddr)}); err != nil {
-				return fmt.Errorf("failed to re-initialize the metrics subsystem with prometheus exporter: %v", err)
+			reloadLogLevelsOnSignal(cmd.Context(), syscall.SIGHUP)
+			// the relay service exports metrics via Prometheus unless another exporter is specified by the config or the flag,
+			// and --prometheus-addr overrides the address in the config if it is given
+			if exporter := metricsConfig(ctx).Exporter; exporter == "" || (exporter == "prometheus" && cmd.Flags().Changed(flagPrometheusAddr)) {
+				if err := metrics.ShutdownMetrics(cmd.Context()); err != nil {
+					return fmt.Errorf("failed to shutdown the metrics subsystem: %v", err)
+				}
+				if err := metrics.InitializeMetrics(metrics.ExporterProm{Addr: viper.GetString(flagPrometheusAddr)}); err != nil {
+					return fmt.Errorf("failed to re-initialize the metrics subsystem with prometheus exporter: %v", err)
+				}
 			}
 			c, src, dst, err := ctx.Config.ChainsFromPath(args[0])
 			if err != nil {
@@ -65,24 +76,33 @@ func startCmd(ctx *config.Context) *cobra.Command {
 			if err := st.SetupRelay(cmd.Context(), c[src], c[dst]); err != nil {
 				return err
 			}
-			return core.StartService(
-				cmd.Context(),
-				st,
-				c[src],
-				c[dst],
-				viper.GetDuration(flagRelayInterval),
-				viper.GetDuration(flagSrcRelayOptimizeInterval),
-				viper.GetUint64(flagSrcRelayOptimizeCount),
-				viper.GetDuration(flagDstRelayOptimizeInterval),
-				viper.GetUint64(flagDstRelayOptimizeCount),
-			)
+			bm, err := core.NewBalanceMonitor(ctx.Config.Global.BalanceMonitorConfig)
+			if err != nil {
+				return err
+			}
+			return core.StartServiceWithOptions(cmd.Context(), st, c[src], c[dst], core.RelayServiceOptions{
+				RelayInterval:            viper.GetDuration(flagRelayInterval),
+				SrcRelayOptimizeInterval: viper.GetDuration(flagSrcRelayOptimizeInterval),
+				SrcRelayOptimizeCount:    viper.GetUint64(flagSrcRelayOptimizeCount),
+				DstRelayOptimizeInterval: viper.GetDuration(flagDstRelayOptimizeInterval),
+				DstRelayOptimizeCount:    viper.GetUint64(flagDstRelayOptimizeCount),
+				BalanceMonitor:           bm,
+				PathName:                 args[0],
+				UpgradePolicy:            path.ChannelUpgradePolicy,
+				MaxRelayBackoff:          viper.GetDuration(flagRelayBackoff),
+				TimeoutUrgency:           viper.GetDuration(flagTimeoutUrgency),
+				MaxPackets:               viper.GetUint64(flagMaxPacketsPerCycle),
+			})
 		},
 	}
 	cmd.Flags().Duration(flagRelayInterval, defaultRelayInterval, "time interval to perform relays")
-	cmd.Flags().String(flagPrometheusAddr, defaultPrometheusAddr, "host address to which the prometheus exporter listens")
+	cmd.Flags().String(flagPrometheusAddr, defaultPrometheusAddr, "host address to which the prometheus exporter listens, used unless another exporter is configured")
 	cmd.Flags().Duration(flagSrcRelayOptimizeInterval, defaultRelayOptimizeInterval, "maximum time interval to delay relays for optimization")
 	cmd.Flags().Uint64(flagSrcRelayOptimizeCount, defaultRelayOptimizeCount, "maximum number of relays to delay for optimization")
 	cmd.Flags().Duration(flagDstRelayOptimizeInterval, defaultRelayOptimizeInterval, "maximum time interval to delay relays for optimization")
 	cmd.Flags().Uint64(flagDstRelayOptimizeCount, defaultRelayOptimizeCount, "maximum number of relays to delay for optimization")
+	cmd.Flags().Duration(flagRelayBackoff, 0, "maximum random delay before sending msgs to reduce collisions with other relayers on the path")
+	cmd.Flags().Duration(flagTimeoutUrgency, 0, "packets that time out within this time are relayed without the delays for optimization (0 disables it)")
+	cmd.Flags().Uint64(flagMaxPacketsPerCycle, 0, "maximum number of packets and acknowledgements relayed in each direction in a cycle (0 means unlimited)")
 	return cmd
 }
@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	"github.com/cosmos/ibc-go/v8/modules/core/exported"
@@ -36,9 +41,14 @@ func transactionCmd(ctx *config.Context) *cobra.Command {
 		flags.LineBreak,
 		createClientsCmd(ctx),
 		updateClientsCmd(ctx),
+		upgradeClientCmd(ctx),
+		recoverClientCmd(ctx),
 		createConnectionCmd(ctx),
 		createChannelCmd(ctx),
+		linkCmd(ctx),
 		channelUpgradeCmd(ctx),
+		registerPayeeCmd(ctx),
+		registerCounterpartyPayeeCmd(ctx),
 	)
 
 	return cmd
@@ -133,6 +143,171 @@ func updateClientsCmd(ctx *config.Context) *cobra.Command {
 	return cmd
 }
 
+func upgradeClientCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagUpgradeHeight = "upgrade-height"
+	)
+	const (
+		defaultUpgradeHeight = 0
+	)
+	cmd := &cobra.Command{
+		Use:   "upgrade-client [path-name] [chain-id]",
+		Short: "upgrade the client on a configured chain for a planned upgrade of the counterparty chain",
+		Long: strings.TrimSpace(`This command is meant to be used to upgrade the client hosted on [chain-id]
+			with the upgraded client committed by the counterparty chain for its planned upgrade`),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pathName := args[0]
+			chainID := args[1]
+
+			upgradeHeight, err := cmd.Flags().GetUint64(flagUpgradeHeight)
+			if err != nil {
+				return err
+			}
+
+			_, srcChainID, dstChainID, err := ctx.Config.ChainsFromPath(pathName)
+			if err != nil {
+				return err
+			}
+
+			var cpChainID string
+			switch chainID {
+			case srcChainID:
+				cpChainID = dstChainID
+			case dstChainID:
+				cpChainID = srcChainID
+			default:
+				return fmt.Errorf("invalid chain ID: %s or %s was expected, but %s was given", srcChainID, dstChainID, chainID)
+			}
+
+			chain, err := ctx.Config.GetChain(chainID)
+			if err != nil {
+				return err
+			}
+			cp, err := ctx.Config.GetChain(cpChainID)
+			if err != nil {
+				return err
+			}
+
+			return core.UpgradeClient(cmd.Context(), cp, chain, upgradeHeight)
+		},
+	}
+	// the upgrade plan is removed once the counterparty chain resumes, so the height needs to be given after that
+	cmd.Flags().Uint64(flagUpgradeHeight, defaultUpgradeHeight, "the height of the planned upgrade of the counterparty chain. If zero, the currently scheduled upgrade is used")
+	return cmd
+}
+
+func recoverClientCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagAuthority = "authority"
+		flagOutput    = "output"
+		flagTitle     = "title"
+		flagSummary   = "summary"
+		flagDeposit   = "deposit"
+	)
+	cmd := &cobra.Command{
+		Use:   "recover-client [path-name] [chain-id]",
+		Short: "recover the expired or frozen client on a configured chain with a substitute client",
+		Long: strings.TrimSpace(`This command is meant to be used to recover the expired or frozen client hosted on [chain-id].
+			It creates a substitute client and submits MsgRecoverClient if the relayer's account is the authority,
+			otherwise it writes a governance proposal containing MsgRecoverClient to be submitted by the authority.
+			The substitute client ID is saved in the path config, while the client ID is kept as it is
+			because the recovered client keeps its identifier`),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pathName := args[0]
+			chainID := args[1]
+
+			_, srcChainID, dstChainID, err := ctx.Config.ChainsFromPath(pathName)
+			if err != nil {
+				return err
+			}
+
+			var cpChainID string
+			switch chainID {
+			case srcChainID:
+				cpChainID = dstChainID
+			case dstChainID:
+				cpChainID = srcChainID
+			default:
+				return fmt.Errorf("invalid chain ID: %s or %s was expected, but %s was given", srcChainID, dstChainID, chainID)
+			}
+
+			chain, err := ctx.Config.GetChain(chainID)
+			if err != nil {
+				return err
+			}
+			cp, err := ctx.Config.GetChain(cpChainID)
+			if err != nil {
+				return err
+			}
+
+			// the gov module account is the authority of MsgRecoverClient by default
+			authority := authtypes.NewModuleAddress(govtypes.ModuleName)
+			if s, err := cmd.Flags().GetString(flagAuthority); err != nil {
+				return err
+			} else if s != "" {
+				if authority, err = core.ParseAuthority(chain, s); err != nil {
+					return err
+				}
+			}
+
+			msg, submitted, err := core.RecoverClient(cmd.Context(), pathName, chain, cp, authority)
+			if err != nil {
+				return err
+			} else if submitted {
+				return nil
+			}
+
+			bz, err := chain.Codec().MarshalInterfaceJSON(msg)
+			if err != nil {
+				return fmt.Errorf("failed to marshal MsgRecoverClient: %v", err)
+			}
+			title, err := cmd.Flags().GetString(flagTitle)
+			if err != nil {
+				return err
+			}
+			summary, err := cmd.Flags().GetString(flagSummary)
+			if err != nil {
+				return err
+			}
+			deposit, err := cmd.Flags().GetString(flagDeposit)
+			if err != nil {
+				return err
+			}
+			proposal, err := json.MarshalIndent(map[string]any{
+				"messages": []json.RawMessage{bz},
+				"metadata": "",
+				"deposit":  deposit,
+				"title":    title,
+				"summary":  summary,
+			}, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			output, err := cmd.Flags().GetString(flagOutput)
+			if err != nil {
+				return err
+			} else if output == "" {
+				fmt.Println(string(proposal))
+				return nil
+			}
+			if err := os.WriteFile(output, proposal, 0o644); err != nil {
+				return fmt.Errorf("failed to write the proposal: %v", err)
+			}
+			fmt.Printf("the proposal to recover the client is written to %s\n", output)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagAuthority, "", "the bech32 address of the authority of MsgRecoverClient. If empty, the gov module account is used")
+	cmd.Flags().String(flagOutput, "", "the file to write the governance proposal to. If empty, it is written to stdout")
+	cmd.Flags().String(flagTitle, "Recover an expired IBC client", "the title of the governance proposal")
+	cmd.Flags().String(flagSummary, "Replace the expired or frozen IBC client with a substitute client", "the summary of the governance proposal")
+	cmd.Flags().String(flagDeposit, "", "the deposit of the governance proposal (e.g. 10000000stake)")
+	return cmd
+}
+
 func createConnectionCmd(ctx *config.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "connection [path-name]",
@@ -160,14 +335,30 @@ func createConnectionCmd(ctx *config.Context) *cobra.Command {
 				return err
 			}
 
+			// the delay period is saved in the path config together with the connection identifiers
+			if cmd.Flags().Changed(flagDelayPeriod) {
+				delayPeriod, err := cmd.Flags().GetDuration(flagDelayPeriod)
+				if err != nil {
+					return err
+				} else if delayPeriod < 0 {
+					return fmt.Errorf("delay period must not be negative: %v", delayPeriod)
+				}
+				c[src].Path().DelayPeriod = uint64(delayPeriod)
+				c[dst].Path().DelayPeriod = uint64(delayPeriod)
+			}
+
 			return core.CreateConnection(cmd.Context(), pathName, c[src], c[dst], to)
 		},
 	}
 
+	cmd.Flags().Duration(flagDelayPeriod, time.Duration(core.DefaultDelayPeriod), "the delay period of the connection, during which proofs are not accepted after a client update")
 	return timeoutFlag(cmd)
 }
 
 func createChannelCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagReopen = "reopen"
+	)
 	cmd := &cobra.Command{
 		Use:   "channel [path-name]",
 		Short: "create a channel between two configured chains with a configured path",
@@ -194,10 +385,83 @@ func createChannelCmd(ctx *config.Context) *cobra.Command {
 				return err
 			}
 
+			// the fee-enabled versions are saved in the path config together with the channel identifiers
+			if fee, err := cmd.Flags().GetBool(flagFee); err != nil {
+				return err
+			} else if fee {
+				c[src].Path().Version = core.FeeVersion(c[src].Path().Version)
+				c[dst].Path().Version = core.FeeVersion(c[dst].Path().Version)
+			}
+
+			if reopen, err := cmd.Flags().GetBool(flagReopen); err != nil {
+				return err
+			} else if reopen {
+				return core.ReopenChannel(cmd.Context(), pathName, c[src], c[dst], to)
+			}
 			return core.CreateChannel(cmd.Context(), pathName, c[src], c[dst], to)
 		},
 	}
 
+	cmd.Flags().Bool(flagFee, false, "stack the ICS-29 fee middleware on the channel versions of the path")
+	cmd.Flags().Bool(flagReopen, false, "open a new channel on the same ports as the closed channel of the path (e.g. an ICA channel closed by a packet timeout), and update the path config with it")
+	return timeoutFlag(cmd)
+}
+
+func linkCmd(ctx *config.Context) *cobra.Command {
+	const (
+		flagReuseExistingClient = "reuse-existing-client"
+	)
+	cmd := &cobra.Command{
+		Use:   "link [path-name]",
+		Short: "create clients, a connection and a channel between two configured chains with a configured path",
+		Long: strings.TrimSpace(`This command is meant to be used to create clients, a connection and a channel in sequence.
+			It resumes from the state of the chains, so it can be run again after a failure midway`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pathName := args[0]
+			c, src, dst, err := ctx.Config.ChainsFromPath(pathName)
+			if err != nil {
+				return err
+			}
+
+			to, err := getTimeout(cmd)
+			if err != nil {
+				return err
+			}
+			reuseExistingClient, err := cmd.Flags().GetBool(flagReuseExistingClient)
+			if err != nil {
+				return err
+			}
+
+			// ensure that keys exist
+			if _, err = c[src].GetAddress(); err != nil {
+				return err
+			}
+			if _, err = c[dst].GetAddress(); err != nil {
+				return err
+			}
+
+			if err := core.Link(cmd.Context(), pathName, c[src], c[dst], to, reuseExistingClient); err != nil {
+				return err
+			}
+
+			pth, err := ctx.Config.Paths.Get(pathName)
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CHAIN\tCLIENT\tCONNECTION\tPORT\tCHANNEL")
+			for _, pe := range []*core.PathEnd{pth.Src, pth.Dst} {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", pe.ChainID, pe.ClientID, pe.ConnectionID, pe.PortID, pe.ChannelID)
+			}
+			w.Flush()
+			printPathStatuses(map[string]*core.PathWithStatus{
+				pathName: pth.QueryPathStatus(cmd.Context(), c[src], c[dst]),
+			})
+			return nil
+		},
+	}
+	cmd.Flags().Bool(flagReuseExistingClient, false, "use an active client tracking the counterparty chain if exists instead of creating a new one")
 	return timeoutFlag(cmd)
 }
 
@@ -277,6 +541,11 @@ func channelUpgradeInitCmd(ctx *config.Context) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if fee, err := cmd.Flags().GetBool(flagFee); err != nil {
+				return err
+			} else if fee {
+				version = core.FeeVersion(version)
+			}
 
 			return core.InitChannelUpgrade(
 				cmd.Context(),
@@ -295,6 +564,7 @@ func channelUpgradeInitCmd(ctx *config.Context) *cobra.Command {
 	cmd.Flags().String(flagOrdering, "", "channel ordering applied for the new channel")
 	cmd.Flags().StringSlice(flagConnectionHops, nil, "connection hops applied for the new channel")
 	cmd.Flags().String(flagVersion, "", "channel version applied for the new channel")
+	cmd.Flags().Bool(flagFee, false, "stack the ICS-29 fee middleware on the channel version applied for the new channel")
 	cmd.Flags().Bool(flagUnsafe, false, "set true if you want to allow for initializing a new channel upgrade even though the counterparty chain is still flushing packets.")
 
 	return &cmd
@@ -411,6 +681,67 @@ func channelUpgradeCancelCmd(ctx *config.Context) *cobra.Command {
 	return &cmd
 }
 
+func registerPayeeCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "register-payee [path-name] [chain-id] [payee]",
+		Short: "register the payee of the ICS-29 fees for the relayer on a configured chain",
+		Long: strings.TrimSpace(`This command is meant to be used to register [payee] on [chain-id] as the address
+			to which the ack and timeout fees for the relayer's msgs over the channel of the path are paid`),
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, cp, err := chainsFromPathEnd(ctx, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return core.RegisterPayee(cmd.Context(), chain, cp, args[2])
+		},
+	}
+	return cmd
+}
+
+func registerCounterpartyPayeeCmd(ctx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "register-counterparty-payee [path-name] [chain-id] [counterparty-payee]",
+		Short: "register the counterparty payee of the ICS-29 fees for the relayer on a configured chain",
+		Long: strings.TrimSpace(`This command is meant to be used to register [counterparty-payee] on [chain-id] as the address
+			on the counterparty chain to which the recv fees for the relayer's MsgRecvPacket on [chain-id] are paid.
+			If [counterparty-payee] is omitted, the relayer's address on the counterparty chain is registered`),
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chain, cp, err := chainsFromPathEnd(ctx, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			var counterpartyPayee string
+			if len(args) == 3 {
+				counterpartyPayee = args[2]
+			} else if addr, err := cp.GetAddress(); err != nil {
+				return err
+			} else {
+				counterpartyPayee = addr.String()
+			}
+			return core.RegisterCounterpartyPayee(cmd.Context(), chain, cp, counterpartyPayee)
+		},
+	}
+	return cmd
+}
+
+// chainsFromPathEnd returns the chain `chainID` of the path `pathName` and its counterparty chain
+func chainsFromPathEnd(ctx *config.Context, pathName, chainID string) (*core.ProvableChain, *core.ProvableChain, error) {
+	chains, srcID, dstID, err := ctx.Config.ChainsFromPath(pathName)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch chainID {
+	case srcID:
+		return chains[srcID], chains[dstID], nil
+	case dstID:
+		return chains[dstID], chains[srcID], nil
+	default:
+		return nil, nil, fmt.Errorf("invalid chain ID: %s or %s was expected, but %s was given", srcID, dstID, chainID)
+	}
+}
+
 func relayMsgsCmd(ctx *config.Context) *cobra.Command {
 	const (
 		flagDoRefresh = "do-refresh"
@@ -520,6 +851,10 @@ func relayAcksCmd(ctx *config.Context) *cobra.Command {
 				return err
 			}
 
+			if err := st.SetupRelay(cmd.Context(), c[src], c[dst]); err != nil {
+				return err
+			}
+
 			// sp.Src contains all sequences acked on SRC but acknowledgement not processed on DST
 			// sp.Dst contains all sequences acked on DST but acknowledgement not processed on SRC
 			sp, err := st.UnrelayedAcknowledgements(cmd.Context(), c[src], c[dst], sh, false)
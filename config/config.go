@@ -2,13 +2,18 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/hyperledger-labs/yui-relayer/core"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"github.com/hyperledger-labs/yui-relayer/tracing"
 )
 
 type Config struct {
@@ -33,15 +38,132 @@ func defaultConfig(configPath string) Config {
 
 // GlobalConfig describes any global relayer settings
 type GlobalConfig struct {
-	Timeout        string       `yaml:"timeout" json:"timeout"`
-	LightCacheSize int          `yaml:"light-cache-size" json:"light-cache-size"`
-	LoggerConfig   LoggerConfig `yaml:"logger" json:"logger"`
+	Timeout        string        `yaml:"timeout" json:"timeout"`
+	LightCacheSize int           `yaml:"light-cache-size" json:"light-cache-size"`
+	LoggerConfig   LoggerConfig  `yaml:"logger" json:"logger"`
+	MetricsConfig  MetricsConfig `yaml:"metrics" json:"metrics"`
+	TracingConfig  TracingConfig `yaml:"tracing" json:"tracing"`
+
+	BalanceMonitorConfig core.BalanceMonitorConfig `yaml:"balance-monitor" json:"balance-monitor"`
+
+	SubmissionLimits []core.SubmissionLimit `yaml:"submission-limits" json:"submission-limits"`
 }
 
+// LoggerConfig describes the global logger.
+// Output is one of "stdout", "stderr" and "file". If Output is "file", logs are written to the file described by File.
+// ModuleLevels overrides Level per module (e.g. {"core.chain": "INFO"}), and a module inherits the level of its parent (e.g. "core").
 type LoggerConfig struct {
-	Level  string `yaml:"level" json:"level"`
-	Format string `yaml:"format" json:"format"`
-	Output string `yaml:"output" json:"output"`
+	Level        string            `yaml:"level" json:"level"`
+	Format       string            `yaml:"format" json:"format"`
+	Output       string            `yaml:"output" json:"output"`
+	File         LogFileConfig     `yaml:"file" json:"file"`
+	ModuleLevels map[string]string `yaml:"module-levels" json:"module-levels"`
+}
+
+// LogFileConfig describes the log file and how it is rotated.
+// MaxSize is in megabytes and MaxAge is in days. RotationInterval is a duration string (e.g. "24h").
+type LogFileConfig struct {
+	Path             string `yaml:"path" json:"path"`
+	MaxSize          int    `yaml:"max-size" json:"max-size"`
+	MaxAge           int    `yaml:"max-age" json:"max-age"`
+	MaxBackups       int    `yaml:"max-backups" json:"max-backups"`
+	RotationInterval string `yaml:"rotation-interval" json:"rotation-interval"`
+	Compress         bool   `yaml:"compress" json:"compress"`
+}
+
+// FileConfig returns the log file configuration described by the config
+func (c LogFileConfig) FileConfig() (log.FileConfig, error) {
+	var interval time.Duration
+	if c.RotationInterval != "" {
+		var err error
+		if interval, err = time.ParseDuration(c.RotationInterval); err != nil {
+			return log.FileConfig{}, fmt.Errorf("invalid log rotation interval: %v", err)
+		}
+	}
+	return log.FileConfig{
+		Path:             c.Path,
+		MaxSize:          c.MaxSize,
+		MaxAge:           c.MaxAge,
+		MaxBackups:       c.MaxBackups,
+		RotationInterval: interval,
+		Compress:         c.Compress,
+	}, nil
+}
+
+// MetricsConfig describes which exporter is used to export metrics.
+// Exporter is one of "null", "prometheus" and "otlp".
+// If Exporter is empty, `service start` exports metrics via Prometheus and the other commands don't export metrics.
+type MetricsConfig struct {
+	Exporter       string     `yaml:"exporter" json:"exporter"`
+	PrometheusAddr string     `yaml:"prometheus-addr" json:"prometheus-addr"`
+	OTLP           OTLPConfig `yaml:"otlp" json:"otlp"`
+}
+
+// OTLPConfig describes the OTLP receiver to which metrics or spans are exported.
+// Interval is used only for metrics.
+type OTLPConfig struct {
+	Protocol string            `yaml:"protocol" json:"protocol"`
+	Endpoint string            `yaml:"endpoint" json:"endpoint"`
+	Insecure bool              `yaml:"insecure" json:"insecure"`
+	Headers  map[string]string `yaml:"headers" json:"headers"`
+	Interval string            `yaml:"interval" json:"interval"`
+}
+
+// ExporterConfig returns the metrics exporter configuration described by the config
+func (c MetricsConfig) ExporterConfig() (metrics.ExporterConfig, error) {
+	switch c.Exporter {
+	case "", "null":
+		return metrics.ExporterNull{}, nil
+	case "prometheus":
+		return metrics.ExporterProm{Addr: c.PrometheusAddr}, nil
+	case "otlp":
+		var interval time.Duration
+		if c.OTLP.Interval != "" {
+			var err error
+			if interval, err = time.ParseDuration(c.OTLP.Interval); err != nil {
+				return nil, fmt.Errorf("invalid OTLP export interval: %v", err)
+			}
+		}
+		return metrics.ExporterOTLP{
+			Protocol: c.OTLP.Protocol,
+			Endpoint: c.OTLP.Endpoint,
+			Insecure: c.OTLP.Insecure,
+			Headers:  c.OTLP.Headers,
+			Interval: interval,
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid metrics exporter: '%s'", c.Exporter)
+	}
+}
+
+// TracingConfig describes which exporter is used to export spans.
+// Exporter is one of "null", "file" and "otlp". If Exporter is empty, spans are not exported.
+type TracingConfig struct {
+	Exporter string     `yaml:"exporter" json:"exporter"`
+	File     string     `yaml:"file" json:"file"`
+	OTLP     OTLPConfig `yaml:"otlp" json:"otlp"`
+}
+
+// ExporterConfig returns the tracing exporter configuration described by the config
+func (c TracingConfig) ExporterConfig() (tracing.ExporterConfig, error) {
+	switch c.Exporter {
+	case "", "null":
+		return tracing.ExporterNull{}, nil
+	case "file":
+		if c.File == "" {
+			return nil, fmt.Errorf("trace file is not specified")
+		}
+		return tracing.ExporterFile{Path: c.File}, nil
+	case "otlp":
+		return tracing.ExporterOTLP{
+			Protocol: c.OTLP.Protocol,
+			Endpoint: c.OTLP.Endpoint,
+			Insecure: c.OTLP.Insecure,
+			Headers:  c.OTLP.Headers,
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid tracing exporter: '%s'", c.Exporter)
+	}
 }
 
 // newDefaultGlobalConfig returns a global config with defaults set
@@ -54,6 +176,23 @@ func newDefaultGlobalConfig() GlobalConfig {
 			Format: "json",
 			Output: "stderr",
 		},
+		MetricsConfig: MetricsConfig{
+			PrometheusAddr: "localhost:2223",
+			OTLP: OTLPConfig{
+				Protocol: metrics.OTLPProtocolGRPC,
+				Endpoint: "localhost:4317",
+				Interval: "10s",
+			},
+		},
+		TracingConfig: TracingConfig{
+			OTLP: OTLPConfig{
+				Protocol: tracing.OTLPProtocolGRPC,
+				Endpoint: "localhost:4317",
+			},
+		},
+		BalanceMonitorConfig: core.BalanceMonitorConfig{
+			Interval: "1m",
+		},
 	}
 }
 
@@ -69,6 +208,19 @@ func (c *Config) GetChains(chainIDs ...string) (map[string]*core.ProvableChain,
 	return c.chains.Gets(chainIDs...)
 }
 
+// CloseChains closes the connections held by the chains that implement io.Closer
+func (c *Config) CloseChains() error {
+	var errs []error
+	for _, chain := range c.chains {
+		if closer, ok := chain.Chain.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // AddChain adds an additional chain to the config
 func (c *Config) AddChain(m codec.JSONCodec, config core.ChainProverConfig) error {
 	chain, err := config.Build()
@@ -179,6 +331,15 @@ func (ctx *Context) InitConfig(homePath string, debug bool) error {
 		return err
 	}
 	ctx.Config.InitCoreConfig()
+	for _, limit := range ctx.Config.Global.SubmissionLimits {
+		chain, err := ctx.Config.GetChain(limit.ChainID)
+		if err != nil {
+			return fmt.Errorf("failed to set the submission limit: %v", err)
+		}
+		if err := chain.SetSubmissionLimit(limit); err != nil {
+			return fmt.Errorf("failed to set the submission limit: %v", err)
+		}
+	}
 	return nil
 }
 
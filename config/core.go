@@ -10,7 +10,10 @@ type CoreConfig struct {
 	config *Config
 }
 
-var _ core.ConfigI = (*CoreConfig)(nil)
+var (
+	_ core.ConfigI          = (*CoreConfig)(nil)
+	_ core.QuarantineConfig = (*CoreConfig)(nil)
+)
 
 func initCoreConfig(c *Config) {
 	config := &CoreConfig{
@@ -20,20 +23,11 @@ func initCoreConfig(c *Config) {
 }
 
 func (c CoreConfig) UpdatePathConfig(pathName string, chainID string, kv map[core.PathConfigKey]string) error {
-	configPath, err := c.config.Paths.Get(pathName)
+	pathEnd, err := c.pathEnd(pathName, chainID)
 	if err != nil {
 		return err
 	}
 
-	var pathEnd *core.PathEnd
-	if chainID == configPath.Src.ChainID {
-		pathEnd = configPath.Src
-	} else if chainID == configPath.Dst.ChainID {
-		pathEnd = configPath.Dst
-	} else {
-		return fmt.Errorf("pathEnd is nil")
-	}
-
 	for k, v := range kv {
 		switch k {
 		case core.PathConfigClientID:
@@ -46,6 +40,8 @@ func (c CoreConfig) UpdatePathConfig(pathName string, chainID string, kv map[cor
 			pathEnd.Order = v
 		case core.PathConfigVersion:
 			pathEnd.Version = v
+		case core.PathConfigSubstituteClientID:
+			pathEnd.SubstituteClientID = v
 		default:
 			panic(fmt.Sprintf("unexpected path config key: %s", k))
 		}
@@ -53,3 +49,27 @@ func (c CoreConfig) UpdatePathConfig(pathName string, chainID string, kv map[cor
 
 	return c.config.OverWriteConfig()
 }
+
+func (c CoreConfig) UpdatePathQuarantine(pathName string, chainID string, quarantine []*core.QuarantinedPacket) error {
+	pathEnd, err := c.pathEnd(pathName, chainID)
+	if err != nil {
+		return err
+	}
+	pathEnd.Quarantine = quarantine
+	return c.config.OverWriteConfig()
+}
+
+func (c CoreConfig) pathEnd(pathName string, chainID string) (*core.PathEnd, error) {
+	configPath, err := c.config.Paths.Get(pathName)
+	if err != nil {
+		return nil, err
+	}
+
+	if chainID == configPath.Src.ChainID {
+		return configPath.Src, nil
+	} else if chainID == configPath.Dst.ChainID {
+		return configPath.Dst, nil
+	} else {
+		return nil, fmt.Errorf("pathEnd is nil")
+	}
+}
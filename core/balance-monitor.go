@@ -0,0 +1,245 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// defaultBalanceCheckInterval is the interval to check the balances for the metrics if the balance monitor is disabled
+const defaultBalanceCheckInterval = time.Minute
+
+// BalanceMonitorConfig describes how the relay service monitors the balances of the relayer accounts
+type BalanceMonitorConfig struct {
+	// Interval is the time interval to check the balances (e.g. "1m").
+	// If empty, the balances are not monitored.
+	Interval string `json:"interval" yaml:"interval"`
+
+	// Thresholds are the minimum balances per chain and denom.
+	// A warning is logged when a balance drops below its threshold.
+	Thresholds []BalanceThreshold `json:"thresholds" yaml:"thresholds"`
+
+	// If set, a POST request with a JSON body is sent to this URL when a balance drops below its threshold
+	WebhookURL string `json:"webhook-url" yaml:"webhook-url"`
+
+	// If set, executions of acknowledgePacket are skipped on the chains of which balances are below their thresholds
+	PauseAcksOnLowBalance bool `json:"pause-acks-on-low-balance" yaml:"pause-acks-on-low-balance"`
+}
+
+// BalanceThreshold is the minimum balance of `Denom` on the chain `ChainID`
+type BalanceThreshold struct {
+	ChainID string `json:"chain-id" yaml:"chain-id"`
+	Denom   string `json:"denom" yaml:"denom"`
+	Amount  string `json:"amount" yaml:"amount"`
+}
+
+// LowBalanceAlert is the body of the request sent to the webhook
+type LowBalanceAlert struct {
+	ChainID   string `json:"chain_id"`
+	Address   string `json:"address"`
+	Denom     string `json:"denom"`
+	Balance   string `json:"balance"`
+	Threshold string `json:"threshold"`
+}
+
+// BalanceMonitor alerts when the balances of the relayer accounts drop below the thresholds.
+// The balances are queried by the relay service every interval.
+type BalanceMonitor struct {
+	interval   time.Duration
+	thresholds map[string]sdk.Coins // chain ID => minimum balances
+	webhookURL string
+	pauseAcks  bool
+	httpClient *http.Client
+
+	mutex sync.RWMutex
+	low   map[string]map[string]bool // chain ID => denom => whether the balance is below the threshold
+}
+
+// NewBalanceMonitor returns a new BalanceMonitor. It returns nil if monitoring is disabled by `cfg`.
+func NewBalanceMonitor(cfg BalanceMonitorConfig) (*BalanceMonitor, error) {
+	if cfg.Interval == "" {
+		return nil, nil
+	}
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid balance monitor interval: %v", err)
+	} else if interval <= 0 {
+		return nil, fmt.Errorf("balance monitor interval must be positive: %v", interval)
+	}
+
+	thresholds := make(map[string]sdk.Coins)
+	for _, th := range cfg.Thresholds {
+		coin, err := sdk.ParseCoinNormalized(th.Amount + th.Denom)
+		if err != nil {
+			return nil, fmt.Errorf("invalid balance threshold for %s: %v", th.ChainID, err)
+		}
+		thresholds[th.ChainID] = thresholds[th.ChainID].Add(coin)
+	}
+
+	return &BalanceMonitor{
+		interval:   interval,
+		thresholds: thresholds,
+		webhookURL: cfg.WebhookURL,
+		pauseAcks:  cfg.PauseAcksOnLowBalance,
+		httpClient: &http.Client{Timeout: webhookTimeout},
+		low:        make(map[string]map[string]bool),
+	}, nil
+}
+
+// due returns true if the balances checked at `checkedAt` should be checked again.
+// If `m` is nil, the balances are checked every defaultBalanceCheckInterval for the metrics.
+func (m *BalanceMonitor) due(checkedAt time.Time) bool {
+	interval := defaultBalanceCheckInterval
+	if m != nil {
+		interval = m.interval
+	}
+	return time.Since(checkedAt) >= interval
+}
+
+// Check queries the balances of `chains`, exports them as metrics and compares them with the thresholds.
+// If `m` is nil, the balances are only exported as metrics.
+func (m *BalanceMonitor) Check(ctx context.Context, chains ...*ProvableChain) {
+	for _, chain := range chains {
+		logger := getBalanceMonitorLogger(chain.ChainID()).WithSpanContext(ctx)
+		coins, err := updateBalanceMetrics(ctx, chain)
+		if err != nil {
+			logger.Error("failed to check the balance", err)
+			continue
+		}
+		if m == nil {
+			continue
+		}
+		address, err := chain.GetAddress()
+		if err != nil {
+			logger.Error("failed to get the relayer address", err)
+			continue
+		}
+		m.update(ctx, chain.ChainID(), address.String(), coins)
+	}
+}
+
+// updateBalanceMetrics queries the balance of the relayer account on `chain` and records it
+func updateBalanceMetrics(ctx context.Context, chain Chain) (sdk.Coins, error) {
+	address, err := chain.GetAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the relayer address: %v", err)
+	}
+	height, err := chain.LatestHeight(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest height: %v", err)
+	}
+	coins, err := chain.QueryBalance(NewQueryContext(ctx, height), address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the balance: %v", err)
+	}
+	for _, coin := range coins {
+		if !coin.Amount.IsInt64() {
+			continue
+		}
+		metrics.AccountBalanceGauge.Set(
+			coin.Amount.Int64(),
+			attribute.Key("chain_id").String(chain.ChainID()),
+			attribute.Key("denom").String(coin.Denom),
+		)
+	}
+	return coins, nil
+}
+
+// update compares `coins` with the thresholds of `chainID`.
+// A warning is logged for every balance below its threshold, and the webhook is called only when a balance newly drops below it.
+func (m *BalanceMonitor) update(ctx context.Context, chainID, address string, coins sdk.Coins) {
+	logger := getBalanceMonitorLogger(chainID).WithSpanContext(ctx)
+
+	low := make(map[string]bool)
+	for _, th := range m.thresholds[chainID] {
+		balance := coins.AmountOf(th.Denom)
+		if balance.GTE(th.Amount) {
+			continue
+		}
+		low[th.Denom] = true
+		logger.Warn(
+			"relayer balance is below the threshold",
+			"address", address,
+			"denom", th.Denom,
+			"balance", balance.String(),
+			"threshold", th.Amount.String(),
+		)
+
+		if m.webhookURL == "" || m.isLow(chainID, th.Denom) {
+			continue
+		}
+		alert := LowBalanceAlert{
+			ChainID:   chainID,
+			Address:   address,
+			Denom:     th.Denom,
+			Balance:   balance.String(),
+			Threshold: th.Amount.String(),
+		}
+		if err := m.notify(ctx, alert); err != nil {
+			logger.Error("failed to send a low balance alert to the webhook", err, "denom", th.Denom)
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.low[chainID] = low
+}
+
+func (m *BalanceMonitor) isLow(chainID, denom string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.low[chainID][denom]
+}
+
+// IsLow returns true if any balance of the relayer account on `chainID` was below its threshold at the last check
+func (m *BalanceMonitor) IsLow(chainID string) bool {
+	if m == nil {
+		return false
+	}
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return len(m.low[chainID]) > 0
+}
+
+// ShouldPauseAcks returns true if executions of acknowledgePacket should be skipped on `chainID` to save funds
+func (m *BalanceMonitor) ShouldPauseAcks(chainID string) bool {
+	return m != nil && m.pauseAcks && m.IsLow(chainID)
+}
+
+func (m *BalanceMonitor) notify(ctx context.Context, alert LowBalanceAlert) error {
+	bz, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the alert: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.webhookURL, bytes.NewReader(bz))
+	if err != nil {
+		return fmt.Errorf("failed to create a request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send a request: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	return nil
+}
+
+func getBalanceMonitorLogger(chainID string) *log.RelayLogger {
+	return log.GetLogger().
+		WithChain(chainID).
+		WithModule("core.balance_monitor")
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+// balanceChain is a Chain whose relayer account has `balance`
+type balanceChain struct {
+	Chain
+	chainID string
+	balance sdk.Coins
+	queries int
+}
+
+func (c *balanceChain) ChainID() string { return c.chainID }
+
+func (c *balanceChain) GetAddress() (sdk.AccAddress, error) {
+	return sdk.AccAddress("relayer"), nil
+}
+
+func (c *balanceChain) LatestHeight(ctx context.Context) (ibcexported.Height, error) {
+	return clienttypes.NewHeight(0, 100), nil
+}
+
+func (c *balanceChain) QueryBalance(ctx QueryContext, address sdk.AccAddress) (sdk.Coins, error) {
+	c.queries++
+	return c.balance, nil
+}
+
+func TestBalanceMonitor(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+
+	var mutex sync.Mutex
+	var alerts []LowBalanceAlert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert LowBalanceAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		alerts = append(alerts, alert)
+	}))
+	defer srv.Close()
+
+	m, err := NewBalanceMonitor(BalanceMonitorConfig{
+		Interval: "1m",
+		Thresholds: []BalanceThreshold{
+			{ChainID: "ibc0", Denom: "stake", Amount: "1000"},
+		},
+		WebhookURL:            srv.URL,
+		PauseAcksOnLowBalance: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coins := func(amount int64) sdk.Coins {
+		return sdk.NewCoins(sdk.NewCoin("stake", sdkmath.NewInt(amount)))
+	}
+	ctx := context.TODO()
+
+	m.update(ctx, "ibc0", "addr", coins(1000))
+	if m.IsLow("ibc0") || len(alerts) != 0 {
+		t.Fatalf("balance should not be low: alerts=%v", alerts)
+	}
+
+	// the webhook is called only when the balance newly drops below the threshold
+	m.update(ctx, "ibc0", "addr", coins(999))
+	m.update(ctx, "ibc0", "addr", coins(500))
+	if !m.IsLow("ibc0") || !m.ShouldPauseAcks("ibc0") {
+		t.Fatal("balance should be low")
+	}
+	if len(alerts) != 1 || alerts[0].Balance != "999" || alerts[0].Threshold != "1000" {
+		t.Fatalf("unexpected alerts: %v", alerts)
+	}
+
+	m.update(ctx, "ibc0", "addr", coins(2000))
+	m.update(ctx, "ibc0", "addr", sdk.NewCoins())
+	if len(alerts) != 2 || alerts[1].Balance != "0" {
+		t.Fatalf("unexpected alerts: %v", alerts)
+	}
+
+	// chains without thresholds are never regarded as low
+	m.update(ctx, "ibc1", "addr", sdk.NewCoins())
+	if m.IsLow("ibc1") {
+		t.Fatal("balance of a chain without thresholds should not be low")
+	}
+
+	var nilMonitor *BalanceMonitor
+	if nilMonitor.ShouldPauseAcks("ibc0") {
+		t.Fatal("nil monitor should not pause acks")
+	}
+}
+
+func TestNewBalanceMonitor(t *testing.T) {
+	if m, err := NewBalanceMonitor(BalanceMonitorConfig{}); err != nil || m != nil {
+		t.Fatalf("monitoring should be disabled without interval: monitor=%v, err=%v", m, err)
+	}
+	if _, err := NewBalanceMonitor(BalanceMonitorConfig{Interval: "1x"}); err == nil {
+		t.Fatal("invalid interval should be rejected")
+	}
+	if _, err := NewBalanceMonitor(BalanceMonitorConfig{
+		Interval:   "1m",
+		Thresholds: []BalanceThreshold{{ChainID: "ibc0", Denom: "stake", Amount: "abc"}},
+	}); err == nil {
+		t.Fatal("invalid threshold should be rejected")
+	}
+}
+
+func TestBalanceMonitorCheck(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewBalanceMonitor(BalanceMonitorConfig{
+		Interval:   "1m",
+		Thresholds: []BalanceThreshold{{ChainID: "ibc0", Denom: "stake", Amount: "1000"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := &balanceChain{chainID: "ibc0", balance: sdk.NewCoins(sdk.NewInt64Coin("stake", 500))}
+
+	// without the monitor, the balances are only exported as metrics
+	var nilMonitor *BalanceMonitor
+	nilMonitor.Check(context.TODO(), NewProvableChain(chain, nil))
+	if chain.queries != 1 {
+		t.Fatalf("the balance is expected to be queried: queries=%d", chain.queries)
+	}
+	if !nilMonitor.due(time.Now().Add(-defaultBalanceCheckInterval)) || nilMonitor.due(time.Now()) {
+		t.Error("the balances are expected to be checked every default interval without the monitor")
+	}
+
+	// the same query feeds the monitor
+	m.Check(context.TODO(), NewProvableChain(chain, nil))
+	if chain.queries != 2 || !m.IsLow("ibc0") {
+		t.Errorf("the balance is expected to be queried and found low: queries=%d", chain.queries)
+	}
+	if !m.due(time.Now().Add(-time.Minute)) || m.due(time.Now()) {
+		t.Error("the balances are expected to be checked every interval")
+	}
+}
@@ -12,12 +12,17 @@ import (
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
 	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ProvableChain represents a chain that is supported by the relayer
 type ProvableChain struct {
 	Chain
 	Prover
+
+	// limiter limits the submissions to the chain, which is nil if no SubmissionLimit is set
+	limiter *submissionLimiter
 }
 
 // NewProvableChain returns a new ProvableChain instance
@@ -55,6 +60,42 @@ func (pc *ProvableChain) SetupForRelay(ctx context.Context) error {
 	return nil
 }
 
+// SendMsgs sends msgs to the chain within a span and the submission limits of the chain.
+// The ids of the msgs deferred by the limits are nil.
+func (pc *ProvableChain) SendMsgs(ctx context.Context, msgs []sdk.Msg) (_ []MsgID, err error) {
+	ctx, span := tracing.StartSpan(ctx, "Chain.SendMsgs", append(chainAttributes(pc), attribute.Int("num_msgs", len(msgs)))...)
+	defer func() { tracing.EndSpan(span, err) }()
+	return sendWithinLimits(ctx, pc.limiter, pc.Chain, msgs)
+}
+
+// ProveState returns a proof of an IBC state within a span
+func (pc *ProvableChain) ProveState(ctx QueryContext, path string, value []byte) (_ []byte, _ clienttypes.Height, err error) {
+	spanCtx, span := tracing.StartSpan(ctx.Context(), "Prover.ProveState", append(chainAttributes(pc), attribute.String("path", path))...)
+	defer func() { tracing.EndSpan(span, err) }()
+	return pc.Prover.ProveState(NewQueryContext(spanCtx, ctx.Height()), path, value)
+}
+
+// GetLatestFinalizedHeader returns the latest finalized header within a span
+func (pc *ProvableChain) GetLatestFinalizedHeader(ctx context.Context) (_ Header, err error) {
+	ctx, span := tracing.StartSpan(ctx, "Prover.GetLatestFinalizedHeader", chainAttributes(pc)...)
+	defer func() { tracing.EndSpan(span, err) }()
+	return pc.Prover.GetLatestFinalizedHeader(ctx)
+}
+
+// SetupHeadersForUpdate returns the headers needed to update the client on the counterparty chain within a span
+func (pc *ProvableChain) SetupHeadersForUpdate(ctx context.Context, counterparty FinalityAwareChain, latestFinalizedHeader Header) (_ []Header, err error) {
+	ctx, span := tracing.StartSpan(ctx, "Prover.SetupHeadersForUpdate", chainAttributes(pc)...)
+	defer func() { tracing.EndSpan(span, err) }()
+	return pc.Prover.SetupHeadersForUpdate(ctx, counterparty, latestFinalizedHeader)
+}
+
+// CheckRefreshRequired returns if the on-chain light client needs to be updated within a span
+func (pc *ProvableChain) CheckRefreshRequired(ctx context.Context, counterparty ChainInfoICS02Querier) (_ bool, err error) {
+	ctx, span := tracing.StartSpan(ctx, "Prover.CheckRefreshRequired", chainAttributes(pc)...)
+	defer func() { tracing.EndSpan(span, err) }()
+	return pc.Prover.CheckRefreshRequired(ctx, counterparty)
+}
+
 // Chain represents a chain that supports sending transactions and querying the state
 type Chain interface {
 	// GetAddress returns the address of relayer
@@ -0,0 +1,291 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+)
+
+// stateChain is a Chain that serves the IBC states of its path end kept in memory.
+// The states that are not set are regarded as not found.
+type stateChain struct {
+	Chain
+	path      *PathEnd
+	height    clienttypes.Height
+	timestamp time.Time
+	// if set, the chain is unreachable
+	err error
+
+	clientState  ibcexported.ClientState
+	clientStatus ibcexported.Status
+	connection   *conntypes.ConnectionEnd
+	channel      *chantypes.Channel
+	upgrade      *chantypes.Upgrade
+	nextSeqRecv  uint64
+
+	// events are the events emitted by the msgs of each type URL
+	events map[string][]MsgEventLog
+	// sent are the msgs sent by SendMsgs in each tx
+	sent [][]sdk.Msg
+}
+
+// newStateChainPair returns a pair of chains with an OPEN channel between them
+func newStateChainPair() (src, dst *stateChain) {
+	newChain := func(chainID, clientID, connectionID, channelID string) *stateChain {
+		return &stateChain{
+			path: &PathEnd{
+				ChainID:      chainID,
+				ClientID:     clientID,
+				ConnectionID: connectionID,
+				ChannelID:    channelID,
+				PortID:       "transfer",
+				Order:        "unordered",
+				Version:      "ics20-1",
+			},
+			height:      clienttypes.NewHeight(0, 100),
+			timestamp:   time.Unix(1700000000, 0),
+			clientState: &tmclient.ClientState{LatestHeight: clienttypes.NewHeight(0, 90)},
+		}
+	}
+	src = newChain("ibc0", "07-tendermint-0", "connection-0", "channel-0")
+	dst = newChain("ibc1", "07-tendermint-1", "connection-1", "channel-1")
+	src.connection = &conntypes.ConnectionEnd{
+		ClientId:     src.path.ClientID,
+		State:        conntypes.OPEN,
+		Counterparty: conntypes.NewCounterparty(dst.path.ClientID, dst.path.ConnectionID, commitmenttypes.NewMerklePrefix([]byte("ibc"))),
+	}
+	dst.connection = &conntypes.ConnectionEnd{
+		ClientId:     dst.path.ClientID,
+		State:        conntypes.OPEN,
+		Counterparty: conntypes.NewCounterparty(src.path.ClientID, src.path.ConnectionID, commitmenttypes.NewMerklePrefix([]byte("ibc"))),
+	}
+	src.channel = &chantypes.Channel{
+		State:          chantypes.OPEN,
+		Ordering:       chantypes.UNORDERED,
+		Counterparty:   chantypes.NewCounterparty(dst.path.PortID, dst.path.ChannelID),
+		ConnectionHops: []string{src.path.ConnectionID},
+		Version:        src.path.Version,
+	}
+	dst.channel = &chantypes.Channel{
+		State:          chantypes.OPEN,
+		Ordering:       chantypes.UNORDERED,
+		Counterparty:   chantypes.NewCounterparty(src.path.PortID, src.path.ChannelID),
+		ConnectionHops: []string{dst.path.ConnectionID},
+		Version:        dst.path.Version,
+	}
+	return src, dst
+}
+
+func (c *stateChain) ChainID() string { return c.path.ChainID }
+
+func (c *stateChain) Path() *PathEnd { return c.path }
+
+func (c *stateChain) Codec() codec.ProtoCodecMarshaler {
+	return codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+}
+
+func (c *stateChain) GetAddress() (sdk.AccAddress, error) {
+	return sdk.AccAddress("relayer"), nil
+}
+
+func (c *stateChain) AverageBlockTime() time.Duration { return time.Second }
+
+func (c *stateChain) LatestHeight(ctx context.Context) (ibcexported.Height, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.height, nil
+}
+
+func (c *stateChain) Timestamp(ctx context.Context, height ibcexported.Height) (time.Time, error) {
+	return c.timestamp, nil
+}
+
+func (c *stateChain) QueryClientState(ctx QueryContext) (*clienttypes.QueryClientStateResponse, error) {
+	if c.clientState == nil {
+		return nil, fmt.Errorf("client %s not found", c.path.ClientID)
+	}
+	anyCs, err := clienttypes.PackClientState(c.clientState)
+	if err != nil {
+		return nil, err
+	}
+	return &clienttypes.QueryClientStateResponse{ClientState: anyCs}, nil
+}
+
+func (c *stateChain) QueryClientConsensusState(ctx QueryContext, height ibcexported.Height) (*clienttypes.QueryConsensusStateResponse, error) {
+	return &clienttypes.QueryConsensusStateResponse{}, nil
+}
+
+func (c *stateChain) QueryClientStatus(ctx QueryContext) (ibcexported.Status, error) {
+	if c.clientStatus == "" {
+		return ibcexported.Active, nil
+	}
+	return c.clientStatus, nil
+}
+
+func (c *stateChain) QueryConnection(ctx QueryContext, connectionID string) (*conntypes.QueryConnectionResponse, error) {
+	if c.connection == nil || connectionID != c.path.ConnectionID {
+		return &conntypes.QueryConnectionResponse{Connection: &conntypes.ConnectionEnd{State: conntypes.UNINITIALIZED}}, nil
+	}
+	return &conntypes.QueryConnectionResponse{Connection: c.connection}, nil
+}
+
+func (c *stateChain) QueryChannel(ctx QueryContext) (*chantypes.QueryChannelResponse, error) {
+	if c.channel == nil {
+		return &chantypes.QueryChannelResponse{Channel: &chantypes.Channel{State: chantypes.UNINITIALIZED}}, nil
+	}
+	return &chantypes.QueryChannelResponse{Channel: c.channel}, nil
+}
+
+func (c *stateChain) QueryChannelUpgrade(ctx QueryContext) (*chantypes.QueryUpgradeResponse, error) {
+	if c.upgrade == nil {
+		return nil, nil
+	}
+	return &chantypes.QueryUpgradeResponse{Upgrade: *c.upgrade}, nil
+}
+
+func (c *stateChain) QueryNextSequenceReceive(ctx QueryContext) (*chantypes.QueryNextSequenceReceiveResponse, error) {
+	return &chantypes.QueryNextSequenceReceiveResponse{NextSequenceReceive: c.nextSeqRecv}, nil
+}
+
+func (c *stateChain) SendMsgs(ctx context.Context, msgs []sdk.Msg) ([]MsgID, error) {
+	c.sent = append(c.sent, msgs)
+	ids := make([]MsgID, len(msgs))
+	for i, msg := range msgs {
+		ids[i] = &testMsgID{typeURL: sdk.MsgTypeURL(msg)}
+	}
+	return ids, nil
+}
+
+func (c *stateChain) GetMsgResult(ctx context.Context, id MsgID) (MsgResult, error) {
+	return &testMsgResult{height: c.height, events: c.events[id.(*testMsgID).typeURL]}, nil
+}
+
+// testMsgID is a MsgID that identifies a msg by its type URL
+type testMsgID struct {
+	typeURL string
+}
+
+func (*testMsgID) Reset()            {}
+func (id *testMsgID) String() string { return id.typeURL }
+func (*testMsgID) ProtoMessage()     {}
+func (*testMsgID) Is_MsgID()         {}
+
+// testMsgResult is a successful MsgResult
+type testMsgResult struct {
+	height clienttypes.Height
+	events []MsgEventLog
+}
+
+func (r *testMsgResult) BlockHeight() clienttypes.Height { return r.height }
+func (r *testMsgResult) Status() (bool, string)          { return true, "" }
+func (r *testMsgResult) Events() []MsgEventLog           { return r.events }
+
+// testConfig is a ConfigI that records the updates of the path config of each chain,
+// and applies them to the path ends as the config does
+type testConfig struct {
+	paths      map[string]map[PathConfigKey]string
+	quarantine map[string][]*QuarantinedPacket
+	ends       map[string]*PathEnd
+}
+
+// setTestConfig replaces the core config with testConfig that updates `ends` during the test
+func setTestConfig(t *testing.T, ends ...*PathEnd) *testConfig {
+	c := &testConfig{
+		paths:      make(map[string]map[PathConfigKey]string),
+		quarantine: make(map[string][]*QuarantinedPacket),
+		ends:       make(map[string]*PathEnd),
+	}
+	for _, pe := range ends {
+		c.ends[pe.ChainID] = pe
+	}
+	orig := config
+	config = c
+	t.Cleanup(func() { config = orig })
+	return c
+}
+
+func (c *testConfig) UpdatePathConfig(pathName string, chainID string, kv map[PathConfigKey]string) error {
+	if c.paths[chainID] == nil {
+		c.paths[chainID] = make(map[PathConfigKey]string)
+	}
+	for k, v := range kv {
+		c.paths[chainID][k] = v
+	}
+	pe := c.ends[chainID]
+	if pe == nil {
+		return nil
+	}
+	for k, v := range kv {
+		switch k {
+		case PathConfigClientID:
+			pe.ClientID = v
+		case PathConfigConnectionID:
+			pe.ConnectionID = v
+		case PathConfigChannelID:
+			pe.ChannelID = v
+		case PathConfigOrder:
+			pe.Order = v
+		case PathConfigVersion:
+			pe.Version = v
+		case PathConfigSubstituteClientID:
+			pe.SubstituteClientID = v
+		}
+	}
+	return nil
+}
+
+func (c *testConfig) UpdatePathQuarantine(pathName string, chainID string, quarantine []*QuarantinedPacket) error {
+	c.quarantine[chainID] = quarantine
+	if pe := c.ends[chainID]; pe != nil {
+		pe.Quarantine = quarantine
+	}
+	return nil
+}
+
+// stateProver is a Prover that returns dummy proofs at the query height
+type stateProver struct {
+	Prover
+}
+
+func (p stateProver) CreateInitialLightClientState(ctx context.Context, height ibcexported.Height) (ibcexported.ClientState, ibcexported.ConsensusState, error) {
+	return &tmclient.ClientState{LatestHeight: clienttypes.NewHeight(0, 100)}, &tmclient.ConsensusState{}, nil
+}
+
+func (p stateProver) ProveState(ctx QueryContext, path string, value []byte) ([]byte, clienttypes.Height, error) {
+	return []byte("proof"), ctx.Height().(clienttypes.Height), nil
+}
+
+// newTestProvableChain returns a ProvableChain of `chain` with stateProver
+func newTestProvableChain(chain Chain) *ProvableChain {
+	return NewProvableChain(chain, stateProver{})
+}
+
+// stateSyncHeaders is a SyncHeaders that returns the query context at the latest height of each chain
+type stateSyncHeaders struct {
+	SyncHeaders
+	chains map[string]*stateChain
+}
+
+func newStateSyncHeaders(chains ...*stateChain) stateSyncHeaders {
+	sh := stateSyncHeaders{chains: make(map[string]*stateChain)}
+	for _, c := range chains {
+		sh.chains[c.ChainID()] = c
+	}
+	return sh
+}
+
+func (sh stateSyncHeaders) GetQueryContext(ctx context.Context, chainID string) QueryContext {
+	return NewQueryContext(ctx, sh.chains[chainID].height)
+}
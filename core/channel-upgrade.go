@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	retry "github.com/avast/retry-go"
@@ -777,3 +779,100 @@ func buildActionMsg(
 		panic(fmt.Errorf("unexpected action: %s", action))
 	}
 }
+
+// ChannelUpgradePolicy describes which channel upgrades the relay service accepts and steps forward automatically.
+// If AutoAccept is set, any upgrade is accepted.
+// Otherwise an upgrade is accepted only if its version and ordering are listed in AllowedVersions and AllowedOrderings,
+// where an empty list allows any value but at least one of them must be specified.
+type ChannelUpgradePolicy struct {
+	AutoAccept       bool     `yaml:"auto-accept" json:"auto-accept"`
+	AllowedVersions  []string `yaml:"allowed-versions,omitempty" json:"allowed-versions,omitempty"`
+	AllowedOrderings []string `yaml:"allowed-orderings,omitempty" json:"allowed-orderings,omitempty"`
+}
+
+// Enabled returns true if the policy accepts any channel upgrade
+func (p *ChannelUpgradePolicy) Enabled() bool {
+	return p != nil && (p.AutoAccept || len(p.AllowedVersions) > 0 || len(p.AllowedOrderings) > 0)
+}
+
+// Validate checks that the policy is valid
+func (p *ChannelUpgradePolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for _, order := range p.AllowedOrderings {
+		if OrderFromString(strings.ToUpper(order)) == chantypes.NONE {
+			return fmt.Errorf("invalid ordering in the channel upgrade policy: %s", order)
+		}
+	}
+	return nil
+}
+
+// Accepts returns true if the upgrade to `fields` is accepted by the policy
+func (p *ChannelUpgradePolicy) Accepts(fields chantypes.UpgradeFields) bool {
+	if !p.Enabled() {
+		return false
+	} else if p.AutoAccept {
+		return true
+	}
+	if len(p.AllowedVersions) > 0 && !slices.Contains(p.AllowedVersions, fields.Version) {
+		return false
+	}
+	if len(p.AllowedOrderings) > 0 && !slices.ContainsFunc(p.AllowedOrderings, func(order string) bool {
+		return OrderFromString(strings.ToUpper(order)) == fields.Ordering
+	}) {
+		return false
+	}
+	return true
+}
+
+// StepChannelUpgrade steps forward the channel upgrade in progress on either chain by one step if it is accepted by `policy`.
+// It returns true if a channel upgrade is in progress.
+// The path config is synchronized with the events emitted by the sent msgs.
+func StepChannelUpgrade(ctx context.Context, pathName string, src, dst *ProvableChain, policy *ChannelUpgradePolicy) (bool, error) {
+	logger := GetChannelPairLogger(src, dst).WithSpanContext(ctx)
+
+	srcH, err := src.LatestHeight(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the latest height of the src chain: %v", err)
+	}
+	dstH, err := dst.LatestHeight(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the latest height of the dst chain: %v", err)
+	}
+	srcChanUpg, dstChanUpg, err := QueryChannelUpgradePair(NewQueryContext(ctx, srcH), NewQueryContext(ctx, dstH), src, dst, false)
+	if err != nil {
+		return false, fmt.Errorf("failed to query the channel upgrade pair: %v", err)
+	} else if srcChanUpg == nil && dstChanUpg == nil {
+		return false, nil
+	}
+
+	for _, chanUpg := range []*chantypes.QueryUpgradeResponse{srcChanUpg, dstChanUpg} {
+		if chanUpg != nil && !policy.Accepts(chanUpg.Upgrade.Fields) {
+			logger.Warn("channel upgrade is not accepted by the policy",
+				"version", chanUpg.Upgrade.Fields.Version,
+				"ordering", chanUpg.Upgrade.Fields.Ordering.String(),
+				"connection_hops", chanUpg.Upgrade.Fields.ConnectionHops,
+			)
+			return true, nil
+		}
+	}
+
+	steps, err := upgradeChannelStep(ctx, src, dst, UPGRADE_STATE_UNINIT, UPGRADE_STATE_UNINIT, false)
+	if err != nil {
+		return true, fmt.Errorf("failed to create channel upgrade step: %v", err)
+	} else if steps.Last {
+		return false, nil
+	} else if !steps.Ready() {
+		return true, nil
+	}
+
+	steps.Send(ctx, src, dst)
+	if !steps.Success() {
+		return true, errors.New("failed to send msgs to step the channel upgrade")
+	}
+	if err := SyncChainConfigsFromEvents(ctx, pathName, steps.SrcMsgIDs, steps.DstMsgIDs, src, dst); err != nil {
+		return true, fmt.Errorf("failed to synchronize the updated path config to the config file: %v", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"testing"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+)
+
+func TestChannelUpgradePolicy(t *testing.T) {
+	fields := chantypes.UpgradeFields{
+		Ordering:       chantypes.UNORDERED,
+		ConnectionHops: []string{"connection-0"},
+		Version:        "ics20-2",
+	}
+	cases := []struct {
+		name     string
+		policy   *ChannelUpgradePolicy
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"empty", &ChannelUpgradePolicy{}, false},
+		{"auto-accept", &ChannelUpgradePolicy{AutoAccept: true}, true},
+		{"allowed version", &ChannelUpgradePolicy{AllowedVersions: []string{"ics20-1", "ics20-2"}}, true},
+		{"disallowed version", &ChannelUpgradePolicy{AllowedVersions: []string{"ics20-1"}}, false},
+		{"allowed ordering", &ChannelUpgradePolicy{AllowedOrderings: []string{"unordered"}}, true},
+		{"disallowed ordering", &ChannelUpgradePolicy{AllowedOrderings: []string{"ORDERED"}}, false},
+		{"allowed version and disallowed ordering", &ChannelUpgradePolicy{
+			AllowedVersions:  []string{"ics20-2"},
+			AllowedOrderings: []string{"ordered"},
+		}, false},
+	}
+	for _, c := range cases {
+		if actual := c.policy.Accepts(fields); actual != c.expected {
+			t.Errorf("%s: unexpected result: actual=%v, expected=%v", c.name, actual, c.expected)
+		}
+	}
+
+	if err := (&ChannelUpgradePolicy{AllowedOrderings: []string{"sorted"}}).Validate(); err == nil {
+		t.Error("invalid ordering should be rejected")
+	}
+}
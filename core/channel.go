@@ -172,8 +172,21 @@ func createChannelStep(ctx context.Context, src, dst *ProvableChain) (*RelayMsgs
 
 	switch {
 	// Handshake hasn't been started on src or dst, relay `chanOpenInit` to src
+	// An ICA channel can't be initialized on the host chain, so `chanOpenInit` is relayed to dst instead
+	case srcChan.Channel.State == chantypes.UNINITIALIZED && dstChan.Channel.State == chantypes.UNINITIALIZED && isICAHostPort(src.Path().PortID):
+		logChannelStates(dst, src, dstChan, srcChan)
+		if err := validateICAVersion(dst.Path(), src.Path(), dst.Path().chanInitVersion(src.Path())); err != nil {
+			return nil, err
+		}
+		addr := mustGetAddress(dst)
+		out.Dst = append(out.Dst,
+			dst.Path().ChanInit(src.Path(), addr),
+		)
 	case srcChan.Channel.State == chantypes.UNINITIALIZED && dstChan.Channel.State == chantypes.UNINITIALIZED:
 		logChannelStates(src, dst, srcChan, dstChan)
+		if err := validateICAVersion(src.Path(), dst.Path(), src.Path().chanInitVersion(dst.Path())); err != nil {
+			return nil, err
+		}
 		addr := mustGetAddress(src)
 		out.Src = append(out.Src,
 			src.Path().ChanInit(dst.Path(), addr),
@@ -181,6 +194,9 @@ func createChannelStep(ctx context.Context, src, dst *ProvableChain) (*RelayMsgs
 	// Handshake has started on dst (1 step done), relay `chanOpenTry` and `updateClient` to src
 	case srcChan.Channel.State == chantypes.UNINITIALIZED && dstChan.Channel.State == chantypes.INIT:
 		logChannelStates(src, dst, srcChan, dstChan)
+		if err := validateICAVersion(src.Path(), dst.Path(), dstChan.Channel.Version); err != nil {
+			return nil, err
+		}
 		addr := mustGetAddress(src)
 		if len(dstUpdateHeaders) > 0 {
 			out.Src = append(out.Src, src.Path().UpdateClients(dstUpdateHeaders, addr)...)
@@ -189,6 +205,9 @@ func createChannelStep(ctx context.Context, src, dst *ProvableChain) (*RelayMsgs
 	// Handshake has started on src (1 step done), relay `chanOpenTry` and `updateClient` to dst
 	case srcChan.Channel.State == chantypes.INIT && dstChan.Channel.State == chantypes.UNINITIALIZED:
 		logChannelStates(dst, src, dstChan, srcChan)
+		if err := validateICAVersion(dst.Path(), src.Path(), srcChan.Channel.Version); err != nil {
+			return nil, err
+		}
 		addr := mustGetAddress(dst)
 		if len(srcUpdateHeaders) > 0 {
 			out.Dst = append(out.Dst, dst.Path().UpdateClients(srcUpdateHeaders, addr)...)
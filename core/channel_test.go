@@ -0,0 +1,214 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	icatypes "github.com/cosmos/ibc-go/v8/modules/apps/27-interchain-accounts/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+// handshakeChain is a stateChain whose channel follows the channel handshake msgs sent to it
+type handshakeChain struct {
+	*stateChain
+}
+
+// newHandshakeChain returns a handshakeChain that generates `channelID` for a new channel
+func newHandshakeChain(c *stateChain, channelID string) *handshakeChain {
+	c.events = map[string][]MsgEventLog{
+		sdk.MsgTypeURL(&chantypes.MsgChannelOpenInit{}): {&EventGenerateChannelIdentifier{ID: channelID}},
+		sdk.MsgTypeURL(&chantypes.MsgChannelOpenTry{}):  {&EventGenerateChannelIdentifier{ID: channelID}},
+	}
+	return &handshakeChain{stateChain: c}
+}
+
+func (c *handshakeChain) SendMsgs(ctx context.Context, msgs []sdk.Msg) ([]MsgID, error) {
+	for _, msg := range msgs {
+		switch msg := msg.(type) {
+		case *chantypes.MsgChannelOpenInit:
+			channel := msg.Channel
+			c.channel = &channel
+		case *chantypes.MsgChannelOpenTry:
+			channel := msg.Channel
+			c.channel = &channel
+		case *chantypes.MsgChannelOpenAck:
+			c.channel.State = chantypes.OPEN
+			c.channel.Counterparty.ChannelId = msg.CounterpartyChannelId
+			c.channel.Version = msg.CounterpartyVersion
+		case *chantypes.MsgChannelOpenConfirm:
+			c.channel.State = chantypes.OPEN
+		case *chantypes.MsgChannelCloseConfirm:
+			c.channel.State = chantypes.CLOSED
+		}
+	}
+	return c.stateChain.SendMsgs(ctx, msgs)
+}
+
+// handshakeProver is a stateProver that returns the header at the latest height of `chain`
+type handshakeProver struct {
+	stateProver
+	chain *stateChain
+}
+
+func (p handshakeProver) GetLatestFinalizedHeader(ctx context.Context) (Header, error) {
+	return &testHeader{height: p.chain.height}, nil
+}
+
+func (p handshakeProver) SetupHeadersForUpdate(ctx context.Context, counterparty FinalityAwareChain, latestFinalizedHeader Header) ([]Header, error) {
+	return []Header{latestFinalizedHeader}, nil
+}
+
+func newHandshakeProvableChain(c *handshakeChain) *ProvableChain {
+	return NewProvableChain(c, handshakeProver{chain: c.stateChain})
+}
+
+// sentMsgTypes returns the type URLs of the msgs sent to `c` in each tx
+func sentMsgTypes(c *stateChain) [][]string {
+	var types [][]string
+	for _, msgs := range c.sent {
+		var tx []string
+		for _, msg := range msgs {
+			tx = append(tx, sdk.MsgTypeURL(msg))
+		}
+		types = append(types, tx)
+	}
+	return types
+}
+
+func TestCreateChannelStepICAHost(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	host, controller := newStateChainPair()
+	host.path.PortID, host.path.Version, host.path.ChannelID = icatypes.HostPortID, icatypes.Version, ""
+	controller.path.PortID, controller.path.Version, controller.path.ChannelID = icatypes.ControllerPortPrefix+"owner", icatypes.Version, ""
+
+	// the host is on src, but the channel can be initialized only by the controller on dst
+	out, err := createChannelStep(context.TODO(), newHandshakeProvableChain(&handshakeChain{host}), newHandshakeProvableChain(&handshakeChain{controller}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Src) != 0 || len(out.Dst) != 1 {
+		t.Fatalf("unexpected msgs: src=%v, dst=%v", out.Src, out.Dst)
+	}
+	msg, ok := out.Dst[0].(*chantypes.MsgChannelOpenInit)
+	if !ok {
+		t.Fatalf("unexpected msg: %T", out.Dst[0])
+	}
+	if msg.PortId != controller.path.PortID || msg.Channel.Counterparty.PortId != icatypes.HostPortID {
+		t.Errorf("unexpected ports: %s, %s", msg.PortId, msg.Channel.Counterparty.PortId)
+	}
+	metadata, err := icatypes.MetadataFromVersion(msg.Channel.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.ControllerConnectionId != controller.path.ConnectionID || metadata.HostConnectionId != host.path.ConnectionID {
+		t.Errorf("unexpected connections in the metadata: %v", metadata)
+	}
+}
+
+func TestReopenChannel(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	initType := sdk.MsgTypeURL(&chantypes.MsgChannelOpenInit{})
+	closeConfirmType := sdk.MsgTypeURL(&chantypes.MsgChannelCloseConfirm{})
+
+	cases := []struct {
+		name               string
+		srcState, dstState chantypes.State
+		wantErr            bool
+		wantCloseConfirm   bool
+	}{
+		{"closed on both", chantypes.CLOSED, chantypes.CLOSED, false, false},
+		{"closed on src", chantypes.CLOSED, chantypes.OPEN, false, true},
+		{"open on both", chantypes.OPEN, chantypes.OPEN, true, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, d := newStateChainPair()
+			s.channel.State, d.channel.State = c.srcState, c.dstState
+			src, dst := newHandshakeChain(s, "channel-5"), newHandshakeChain(d, "channel-6")
+			cfg := setTestConfig(t, s.path, d.path)
+
+			err := ReopenChannel(context.TODO(), "path", newHandshakeProvableChain(src), newHandshakeProvableChain(dst), time.Millisecond)
+			if c.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				} else if len(cfg.paths) != 0 || len(s.sent) != 0 || len(d.sent) != 0 {
+					t.Errorf("unexpected updates: config=%v, src=%v, dst=%v", cfg.paths, sentMsgTypes(s), sentMsgTypes(d))
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if s.path.ChannelID != "channel-5" || d.path.ChannelID != "channel-6" {
+				t.Errorf("unexpected channel IDs: %s, %s", s.path.ChannelID, d.path.ChannelID)
+			}
+			if s.channel.State != chantypes.OPEN || d.channel.State != chantypes.OPEN {
+				t.Errorf("unexpected channel states: %s, %s", s.channel.State, d.channel.State)
+			}
+			// MsgChannelCloseConfirm is relayed before the new channel is initialized
+			dstTypes, srcTypes := sentMsgTypes(d), sentMsgTypes(s)
+			closeConfirmed := len(dstTypes) > 0 && dstTypes[0][len(dstTypes[0])-1] == closeConfirmType
+			if closeConfirmed != c.wantCloseConfirm {
+				t.Errorf("unexpected msgs sent to dst: %v", dstTypes)
+			}
+			if len(srcTypes) == 0 || srcTypes[0][len(srcTypes[0])-1] != initType {
+				t.Errorf("unexpected msgs sent to src: %v", srcTypes)
+			}
+		})
+	}
+}
+
+func TestReopenICAChannel(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	s, d := newStateChainPair()
+	closedVersion := string(icatypes.ModuleCdc.MustMarshalJSON(&icatypes.Metadata{
+		Version:                icatypes.Version,
+		ControllerConnectionId: s.path.ConnectionID,
+		HostConnectionId:       d.path.ConnectionID,
+		Address:                "cosmos1ica",
+		Encoding:               icatypes.EncodingProtobuf,
+		TxType:                 icatypes.TxTypeSDKMultiMsg,
+	}))
+	s.path.PortID, s.path.Version = icatypes.ControllerPortPrefix+"owner", icatypes.Version
+	d.path.PortID, d.path.Version = icatypes.HostPortID, icatypes.Version
+	s.channel = &chantypes.Channel{State: chantypes.CLOSED, Ordering: chantypes.ORDERED, Counterparty: chantypes.NewCounterparty(d.path.PortID, d.path.ChannelID), ConnectionHops: []string{s.path.ConnectionID}, Version: closedVersion}
+	d.channel = &chantypes.Channel{State: chantypes.CLOSED, Ordering: chantypes.ORDERED, Counterparty: chantypes.NewCounterparty(s.path.PortID, s.path.ChannelID), ConnectionHops: []string{d.path.ConnectionID}, Version: closedVersion}
+	src, dst := newHandshakeChain(s, "channel-5"), newHandshakeChain(d, "channel-6")
+	cfg := setTestConfig(t, s.path, d.path)
+
+	if err := ReopenChannel(context.TODO(), "path", newHandshakeProvableChain(src), newHandshakeProvableChain(dst), time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	// the controller proposes the version of the closed channel to keep the interchain account
+	if v := cfg.paths["ibc0"][PathConfigVersion]; v != closedVersion {
+		t.Errorf("unexpected version of the controller in the config: %s", v)
+	}
+	if _, ok := cfg.paths["ibc1"][PathConfigVersion]; ok {
+		t.Error("the version of the host is updated")
+	}
+	if s.channel.Version != closedVersion || d.channel.Version != closedVersion {
+		t.Errorf("unexpected channel versions: %s, %s", s.channel.Version, d.channel.Version)
+	}
+	if s.channel.State != chantypes.OPEN || d.channel.State != chantypes.OPEN {
+		t.Errorf("unexpected channel states: %s, %s", s.channel.State, d.channel.State)
+	}
+}
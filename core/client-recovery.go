@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+)
+
+// clientExpirationWarningRate is the rate of the trusting period below which the remaining time until the client expiration is warned
+const clientExpirationWarningRate = 1.0 / 3
+
+// ClientExpirationQuerier is an optional interface of Chain that queries when the light client of the path end expires
+type ClientExpirationQuerier interface {
+	// QueryClientExpiration returns the expiration time of the light client and its trusting period
+	QueryClientExpiration(ctx QueryContext) (expiration time.Time, trustingPeriod time.Duration, err error)
+}
+
+// CheckClientHealth checks if the client on `chain` tracking `cp` can still be updated, and warns if it is about to expire.
+// It returns an error if the client has expired or been frozen, which needs to be recovered by `tx recover-client`.
+func CheckClientHealth(ctx context.Context, chain, cp *ProvableChain) error {
+	logger := GetClientPairLogger(chain, cp).WithSpanContext(ctx)
+
+	height, err := chain.LatestHeight(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the latest height of chain %s: %v", chain.ChainID(), err)
+	}
+	queryCtx := NewQueryContext(ctx, height)
+
+	if querier, ok := chain.Chain.(ClientStatusQuerier); ok {
+		status, err := querier.QueryClientStatus(queryCtx)
+		if err != nil {
+			return fmt.Errorf("failed to query the client status: %v", err)
+		} else if status == ibcexported.Expired || status == ibcexported.Frozen {
+			return fmt.Errorf("client %s on %s is %s and needs to be recovered", chain.Path().ClientID, chain.ChainID(), status)
+		}
+	}
+
+	if querier, ok := chain.Chain.(ClientExpirationQuerier); ok {
+		expiration, trustingPeriod, err := querier.QueryClientExpiration(queryCtx)
+		if err != nil {
+			return fmt.Errorf("failed to query the client expiration: %v", err)
+		}
+		if remaining := time.Until(expiration); remaining <= 0 {
+			return fmt.Errorf("client %s on %s expired at %v and needs to be recovered", chain.Path().ClientID, chain.ChainID(), expiration)
+		} else if remaining < time.Duration(float64(trustingPeriod)*clientExpirationWarningRate) {
+			logger.Warn("client is about to expire", "expiration", expiration, "remaining", remaining.String())
+		}
+	}
+	return nil
+}
+
+// AccountPrefixer is an optional interface of Chain that returns the bech32 prefix of the account addresses on the chain
+type AccountPrefixer interface {
+	AccountPrefix() string
+}
+
+// ParseAuthority decodes the bech32 address `s` of the authority of MsgRecoverClient on `chain`.
+// It returns an error if `chain` implements AccountPrefixer and the prefix of `s` is not the account prefix of `chain`.
+func ParseAuthority(chain *ProvableChain, s string) (sdk.AccAddress, error) {
+	hrp, bz, err := bech32.DecodeAndConvert(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authority: %v", err)
+	}
+	if prefixer, ok := chain.Chain.(AccountPrefixer); ok && hrp != prefixer.AccountPrefix() {
+		return nil, fmt.Errorf("invalid authority: the prefix %s is not the account prefix %s of chain %s", hrp, prefixer.AccountPrefix(), chain.ChainID())
+	}
+	return bz, nil
+}
+
+// RecoverClient creates a substitute client tracking `cp` on `chain` and returns MsgRecoverClient that replaces the expired or frozen client of the path with it.
+// The message is submitted to `chain` if the relayer's address is `authority`, otherwise it needs to be submitted as a governance proposal.
+// The substitute client ID is saved in the path config, while the client ID is kept as it is because the subject client keeps its identifier after the recovery.
+func RecoverClient(ctx context.Context, pathName string, chain, cp *ProvableChain, authority sdk.AccAddress) (msg sdk.Msg, submitted bool, err error) {
+	logger := GetClientPairLogger(chain, cp).WithSpanContext(ctx)
+	defer logger.TimeTrack(time.Now(), "RecoverClient")
+
+	height, err := chain.LatestHeight(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get the latest height of chain %s: %v", chain.ChainID(), err)
+	}
+	if querier, ok := chain.Chain.(ClientStatusQuerier); ok {
+		status, err := querier.QueryClientStatus(NewQueryContext(ctx, height))
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to query the client status: %v", err)
+		} else if status == ibcexported.Active {
+			return nil, false, fmt.Errorf("client %s on %s is active and doesn't need to be recovered", chain.Path().ClientID, chain.ChainID())
+		}
+	}
+
+	addr, err := chain.GetAddress()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get the address of chain %s: %v", chain.ChainID(), err)
+	}
+
+	substituteID, err := createSubstituteClient(ctx, chain, cp, addr)
+	if err != nil {
+		return nil, false, err
+	}
+	logger.Info("★ Substitute client created", "substitute_client_id", substituteID)
+	if err := config.UpdatePathConfig(pathName, chain.ChainID(), map[PathConfigKey]string{PathConfigSubstituteClientID: substituteID}); err != nil {
+		return nil, false, err
+	}
+
+	msg = clienttypes.NewMsgRecoverClient(authority.String(), chain.Path().ClientID, substituteID)
+	if !bytes.Equal(addr, authority) {
+		return msg, false, nil
+	}
+
+	msgs := NewRelayMsgs()
+	msgs.Src = append(msgs.Src, msg)
+	if msgs.Send(ctx, chain, cp); !msgs.Success() {
+		return msg, false, fmt.Errorf("failed to send MsgRecoverClient to chain %s", chain.ChainID())
+	}
+	logger.Info("★ Client recovered", "substitute_client_id", substituteID)
+	return msg, true, nil
+}
+
+// createSubstituteClient creates a new client tracking `cp` on `chain` and returns its identifier
+func createSubstituteClient(ctx context.Context, chain, cp *ProvableChain, signer sdk.AccAddress) (string, error) {
+	cs, cons, err := cp.CreateInitialLightClientState(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create initial light client state: %v", err)
+	}
+	msg, err := clienttypes.NewMsgCreateClient(cs, cons, signer.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to create MsgCreateClient: %v", err)
+	}
+
+	msgs := NewRelayMsgs()
+	msgs.Src = append(msgs.Src, msg)
+	if msgs.Send(ctx, chain, cp); !msgs.Success() {
+		return "", fmt.Errorf("failed to send MsgCreateClient to chain %s", chain.ChainID())
+	}
+
+	msgRes, err := chain.GetMsgResult(ctx, msgs.SrcMsgIDs[0])
+	if err != nil {
+		return "", fmt.Errorf("failed to get message result: %v", err)
+	} else if ok, failureReason := msgRes.Status(); !ok {
+		return "", fmt.Errorf("MsgCreateClient execution failed: %v", failureReason)
+	}
+	for _, event := range msgRes.Events() {
+		if event, ok := event.(*EventGenerateClientIdentifier); ok {
+			return event.ID, nil
+		}
+	}
+	return "", fmt.Errorf("client identifier not found in the events of MsgCreateClient")
+}
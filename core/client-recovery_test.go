@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+// prefixChain is a stateChain with the bech32 account prefix `prefix`
+type prefixChain struct {
+	*stateChain
+	prefix string
+}
+
+func (c prefixChain) AccountPrefix() string { return c.prefix }
+
+func TestRecoverClient(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	createClientType := sdk.MsgTypeURL(&clienttypes.MsgCreateClient{})
+
+	cases := []struct {
+		name          string
+		status        ibcexported.Status
+		authority     sdk.AccAddress
+		wantErr       bool
+		wantSubmitted bool
+	}{
+		{"active client", ibcexported.Active, sdk.AccAddress("relayer"), true, false},
+		{"relayer is the authority", ibcexported.Expired, sdk.AccAddress("relayer"), false, true},
+		{"gov is the authority", ibcexported.Frozen, sdk.AccAddress("gov"), false, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := setTestConfig(t)
+			src, dst := newStateChainPair()
+			src.clientStatus = c.status
+			src.events = map[string][]MsgEventLog{
+				createClientType: {&EventGenerateClientIdentifier{ID: "07-tendermint-5"}},
+			}
+
+			msg, submitted, err := RecoverClient(context.TODO(), "path", newTestProvableChain(src), newTestProvableChain(dst), c.authority)
+			if c.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				} else if len(src.sent) != 0 {
+					t.Errorf("unexpected msgs sent: %v", src.sent)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if submitted != c.wantSubmitted {
+				t.Errorf("unexpected submitted: %v", submitted)
+			}
+			recoverMsg, ok := msg.(*clienttypes.MsgRecoverClient)
+			if !ok {
+				t.Fatalf("unexpected msg: %T", msg)
+			} else if recoverMsg.SubjectClientId != "07-tendermint-0" || recoverMsg.SubstituteClientId != "07-tendermint-5" || recoverMsg.Signer != c.authority.String() {
+				t.Errorf("unexpected MsgRecoverClient: %v", recoverMsg)
+			}
+			if got := cfg.paths["ibc0"][PathConfigSubstituteClientID]; got != "07-tendermint-5" {
+				t.Errorf("unexpected substitute client ID in the config: %s", got)
+			}
+			wantTxs := 1
+			if c.wantSubmitted {
+				wantTxs = 2
+			}
+			if len(src.sent) != wantTxs {
+				t.Fatalf("unexpected txs: %v", src.sent)
+			} else if _, ok := src.sent[0][0].(*clienttypes.MsgCreateClient); !ok {
+				t.Errorf("unexpected msg of the first tx: %T", src.sent[0][0])
+			}
+		})
+	}
+}
+
+func TestParseAuthority(t *testing.T) {
+	src, _ := newStateChainPair()
+	addr := sdk.AccAddress("gov")
+	encode := func(hrp string) string {
+		s, err := bech32.ConvertAndEncode(hrp, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	cases := []struct {
+		name    string
+		chain   Chain
+		s       string
+		wantErr bool
+	}{
+		{"matching prefix", prefixChain{src, "cosmos"}, encode("cosmos"), false},
+		{"prefix of another chain", prefixChain{src, "cosmos"}, encode("osmo"), true},
+		{"chain without prefix", src, encode("osmo"), false},
+		{"invalid address", prefixChain{src, "cosmos"}, "cosmos1invalid", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			authority, err := ParseAuthority(newTestProvableChain(c.chain), c.s)
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, but got %v", authority)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !authority.Equals(addr) {
+				t.Errorf("unexpected authority: %v", authority)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+)
+
+// queryReusableClientHeight returns the latest height of the client on `receiver` and true if the client is active and has a consensus state at or above the height of `queryCtx`,
+// e.g. because another relayer has updated the client. The proofs at that height can be verified without updating the client.
+func queryReusableClientHeight(ctx context.Context, queryCtx QueryContext, receiver *ProvableChain) (ibcexported.Height, bool, error) {
+	height, err := receiver.LatestHeight(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get the latest height: %v", err)
+	}
+	receiverCtx := NewQueryContext(ctx, height)
+
+	// a client that has expired or been frozen can't verify the proofs even at the height of its existing consensus state
+	if querier, ok := receiver.Chain.(ClientStatusQuerier); ok {
+		if status, err := querier.QueryClientStatus(receiverCtx); err != nil {
+			return nil, false, fmt.Errorf("failed to query the client status: %v", err)
+		} else if status != ibcexported.Active {
+			return nil, false, nil
+		}
+	}
+
+	csRes, err := receiver.QueryClientState(receiverCtx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query the client state: %v", err)
+	}
+	cs, err := clienttypes.UnpackClientState(csRes.ClientState)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to unpack the client state: %v", err)
+	}
+	clientHeight := cs.GetLatestHeight()
+	if clientHeight.LT(queryCtx.Height()) {
+		return clientHeight, false, nil
+	}
+	if _, err := receiver.QueryClientConsensusState(receiverCtx, clientHeight); err != nil {
+		return nil, false, fmt.Errorf("failed to query the consensus state: height=%v, err=%v", clientHeight, err)
+	}
+	return clientHeight, true, nil
+}
+
+// reusedClientQueryContext returns the query context on `sender` at `clientHeight`, the height of the consensus state of the client on `receiver`
+// that UpdateClients reused instead of updating the client. If the client is not reused (i.e. `clientHeight` is nil), it returns `queryCtx`.
+func reusedClientQueryContext(ctx context.Context, queryCtx QueryContext, clientHeight ibcexported.Height, sender, receiver *ProvableChain) QueryContext {
+	if clientHeight == nil {
+		return queryCtx
+	}
+	if !clientHeight.EQ(queryCtx.Height()) {
+		GetChannelPairLogger(sender, receiver).WithSpanContext(ctx).Info("prove at the height of the existing consensus state",
+			"query_height", queryCtx.Height(),
+			"client_height", clientHeight,
+		)
+	}
+	return NewQueryContext(ctx, clientHeight)
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+// clientStateChain is a Chain that has the client updated to `clientHeight`
+type clientStateChain struct {
+	Chain
+	clientHeight clienttypes.Height
+}
+
+func (c *clientStateChain) LatestHeight(ctx context.Context) (ibcexported.Height, error) {
+	return clienttypes.NewHeight(0, 1000), nil
+}
+
+func (c *clientStateChain) QueryClientState(ctx QueryContext) (*clienttypes.QueryClientStateResponse, error) {
+	anyCs, err := clienttypes.PackClientState(&tmclient.ClientState{LatestHeight: c.clientHeight})
+	if err != nil {
+		return nil, err
+	}
+	return &clienttypes.QueryClientStateResponse{ClientState: anyCs}, nil
+}
+
+func (c *clientStateChain) QueryClientConsensusState(ctx QueryContext, dstClientConsHeight ibcexported.Height) (*clienttypes.QueryConsensusStateResponse, error) {
+	return &clienttypes.QueryConsensusStateResponse{}, nil
+}
+
+func TestQueryReusableClientHeight(t *testing.T) {
+	cases := []struct {
+		name         string
+		clientHeight clienttypes.Height
+		expected     bool
+	}{
+		{"behind", clienttypes.NewHeight(1, 99), false},
+		{"at the query height", clienttypes.NewHeight(1, 100), true},
+		{"updated by another relayer", clienttypes.NewHeight(1, 105), true},
+	}
+	for _, c := range cases {
+		receiver := &ProvableChain{Chain: &clientStateChain{clientHeight: c.clientHeight}}
+		queryCtx := NewQueryContext(context.TODO(), clienttypes.NewHeight(1, 100))
+		height, ok, err := queryReusableClientHeight(context.TODO(), queryCtx, receiver)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if ok != c.expected || !height.EQ(c.clientHeight) {
+			t.Errorf("%s: unexpected result: actual=(%v, %v), expected=(%v, %v)", c.name, height, ok, c.clientHeight, c.expected)
+		}
+	}
+}
+
+// TestUpdateClientsReusingClient checks that the client already updated by another relayer is not updated,
+// and the proofs of the packets are built at the height of its existing consensus state.
+func TestUpdateClientsReusingClient(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	src, dst := newStateChainPair()
+	// the client on dst has been updated beyond the latest height of src
+	clientHeight := clienttypes.NewHeight(0, 105)
+	dst.clientState = &tmclient.ClientState{LatestHeight: clientHeight}
+	srcPC, dstPC := newTestProvableChain(src), newTestProvableChain(dst)
+	st := &NaiveStrategy{}
+	sh := newStateSyncHeaders(src, dst)
+
+	msgs, err := st.UpdateClients(context.TODO(), srcPC, dstPC, false, true, false, false, sh, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs.Src) != 0 || len(msgs.Dst) != 0 {
+		t.Fatalf("unexpected msgs: src=%v, dst=%v", msgs.Src, msgs.Dst)
+	}
+
+	rp := &RelayPackets{Src: PacketInfoList{{
+		Packet:      chantypes.Packet{Sequence: 1, SourcePort: src.path.PortID, SourceChannel: src.path.ChannelID},
+		EventHeight: clienttypes.NewHeight(0, 95),
+	}}}
+	msgs, err = st.RelayPackets(context.TODO(), srcPC, dstPC, rp, sh, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs.Dst) != 1 {
+		t.Fatalf("unexpected msgs: %v", msgs.Dst)
+	}
+	if msg, ok := msgs.Dst[0].(*chantypes.MsgRecvPacket); !ok || !msg.ProofHeight.EQ(clientHeight) {
+		t.Errorf("unexpected msg: %v", msgs.Dst[0])
+	}
+
+	// the client is updated once it has expired
+	dst.clientStatus = ibcexported.Expired
+	if _, ok, err := queryReusableClientHeight(context.TODO(), sh.GetQueryContext(context.TODO(), src.ChainID()), dstPC); err != nil || ok {
+		t.Errorf("the expired client is not expected to be reused: ok=%v, err=%v", ok, err)
+	}
+}
@@ -176,6 +176,96 @@ func UpdateClients(ctx context.Context, src, dst *ProvableChain) error {
 	return nil
 }
 
+// UpgradeClient upgrades the client on `dst` with the upgraded client of `src` committed for the planned upgrade at `upgradeHeight`.
+// `upgradeHeight` is the revision height before the upgrade. If it is zero, the height of the upgrade currently scheduled on `src` is used.
+func UpgradeClient(ctx context.Context, src, dst *ProvableChain, upgradeHeight uint64) error {
+	logger := GetClientPairLogger(src, dst).WithSpanContext(ctx)
+	defer logger.TimeTrack(time.Now(), "UpgradeClient")
+
+	upgrader, ok := src.Prover.(ClientUpgrader)
+	if !ok {
+		return fmt.Errorf("the prover of chain %s doesn't support client upgrade", src.ChainID())
+	}
+	if upgradeHeight == 0 {
+		h, err := upgrader.QueryUpgradeHeight(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to query the upgrade height of chain %s: %v", src.ChainID(), err)
+		} else if h == nil {
+			return fmt.Errorf("no upgrade of IBC clients is scheduled on chain %s", src.ChainID())
+		}
+		upgradeHeight = h.GetRevisionHeight()
+	}
+
+	dstHeight, err := dst.LatestHeight(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the latest height of chain %s: %v", dst.ChainID(), err)
+	}
+	csRes, err := dst.QueryClientState(NewQueryContext(ctx, dstHeight))
+	if err != nil {
+		return fmt.Errorf("failed to query the client state on chain %s: %v", dst.ChainID(), err)
+	}
+	cs, err := clienttypes.UnpackClientState(csRes.ClientState)
+	if err != nil {
+		return fmt.Errorf("failed to unpack the client state: %v", err)
+	}
+	// the upgrade height belongs to the revision that the client tracks before the upgrade
+	clientHeight := cs.GetLatestHeight()
+	height := clienttypes.NewHeight(clientHeight.GetRevisionNumber(), upgradeHeight)
+	if clientHeight.GT(height) {
+		logger.Info("client has already been upgraded", "client_height", clientHeight.String(), "upgrade_height", height.String())
+		return nil
+	}
+
+	upgraded, err := upgrader.ProveUpgradedClient(NewQueryContext(ctx, height))
+	if err != nil {
+		return fmt.Errorf("failed to prove the upgraded client at %v: %v", height, err)
+	}
+	addr, err := dst.GetAddress()
+	if err != nil {
+		return fmt.Errorf("failed to get the address of chain %s: %v", dst.ChainID(), err)
+	}
+
+	// the upgraded states are verified against the consensus state at the upgrade height
+	msgs := NewRelayMsgs()
+	if clientHeight.LT(height) {
+		headers, err := src.SetupHeadersForUpdate(ctx, dst, upgraded.Header)
+		if err != nil {
+			return fmt.Errorf("failed to set up headers for LC update: %v", err)
+		}
+		msgs.Dst = append(msgs.Dst, dst.Path().UpdateClients(headers, addr)...)
+	}
+	msgs.Dst = append(msgs.Dst, dst.Path().UpgradeClient(upgraded, addr))
+
+	if msgs.Send(ctx, src, dst); !msgs.Success() {
+		return fmt.Errorf("failed to send MsgUpgradeClient to chain %s", dst.ChainID())
+	}
+	logger.Info("★ Client upgraded", "upgrade_height", height.String())
+	return nil
+}
+
+// UpgradeClientIfHalted upgrades the client on `dst` if `src` has halted for a planned upgrade of IBC clients.
+// It returns true if `src` is halted for the upgrade, i.e. the upgrade plan is scheduled and the latest height of `src` is just before the upgrade height,
+// because the block at the upgrade height is not committed until `src` restarts with the upgraded binary.
+func UpgradeClientIfHalted(ctx context.Context, src, dst *ProvableChain) (bool, error) {
+	upgrader, ok := src.Prover.(ClientUpgrader)
+	if !ok {
+		return false, nil
+	}
+	upgradeHeight, err := upgrader.QueryUpgradeHeight(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to query the upgrade height of chain %s: %v", src.ChainID(), err)
+	} else if upgradeHeight == nil {
+		return false, nil
+	}
+	latestHeight, err := src.LatestHeight(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the latest height of chain %s: %v", src.ChainID(), err)
+	} else if !clienttypes.NewHeight(latestHeight.GetRevisionNumber(), latestHeight.GetRevisionHeight()+1).EQ(upgradeHeight) {
+		return false, nil
+	}
+	return true, UpgradeClient(ctx, src, dst, upgradeHeight.GetRevisionHeight())
+}
+
 func GetClientPairLogger(src, dst Chain) *log.RelayLogger {
 	return log.GetLogger().
 		WithClientPair(
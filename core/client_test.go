@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gogoproto/proto"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	solomachine "github.com/cosmos/ibc-go/v8/modules/light-clients/06-solomachine"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+// testHeader is a Header at `height`, which is encoded as an empty solo machine header
+type testHeader struct {
+	solomachine.Header
+	height clienttypes.Height
+}
+
+func (h *testHeader) GetHeight() ibcexported.Height { return h.height }
+
+func (h *testHeader) ValidateBasic() error { return nil }
+
+func (h *testHeader) XXX_MessageName() string { return proto.MessageName(&h.Header) }
+
+// upgraderProver is a Prover of the chain that schedules a planned upgrade at `upgradeHeight`
+type upgraderProver struct {
+	stateProver
+	upgradeHeight ibcexported.Height
+	provedAt      ibcexported.Height
+}
+
+func (p *upgraderProver) QueryUpgradeHeight(ctx context.Context) (ibcexported.Height, error) {
+	return p.upgradeHeight, nil
+}
+
+func (p *upgraderProver) ProveUpgradedClient(ctx QueryContext) (*UpgradedClient, error) {
+	p.provedAt = ctx.Height()
+	return &UpgradedClient{
+		Header:         &testHeader{height: ctx.Height().(clienttypes.Height)},
+		ClientState:    &tmclient.ClientState{ChainId: "ibc0-1", LatestHeight: clienttypes.NewHeight(1, 1)},
+		ConsensusState: &tmclient.ConsensusState{},
+	}, nil
+}
+
+func (p *upgraderProver) SetupHeadersForUpdate(ctx context.Context, counterparty FinalityAwareChain, latestFinalizedHeader Header) ([]Header, error) {
+	return []Header{latestFinalizedHeader}, nil
+}
+
+func TestUpgradeClientIfHalted(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name          string
+		upgradeHeight ibcexported.Height
+		clientHeight  uint64
+		halted        bool
+		// the msg types sent to dst
+		expected []string
+	}{
+		// src is at height 100
+		{"no upgrade scheduled", nil, 90, false, nil},
+		{"not halted yet", clienttypes.NewHeight(0, 150), 90, false, nil},
+		{"halted", clienttypes.NewHeight(0, 101), 90, true, []string{
+			sdk.MsgTypeURL(&clienttypes.MsgUpdateClient{}),
+			sdk.MsgTypeURL(&clienttypes.MsgUpgradeClient{}),
+		}},
+		{"client at the upgrade height", clienttypes.NewHeight(0, 101), 101, true, []string{
+			sdk.MsgTypeURL(&clienttypes.MsgUpgradeClient{}),
+		}},
+		{"client already upgraded", clienttypes.NewHeight(0, 101), 102, true, nil},
+		{"past the upgrade height", clienttypes.NewHeight(0, 100), 90, false, nil},
+	}
+	for _, c := range cases {
+		src, dst := newStateChainPair()
+		dst.clientState = &tmclient.ClientState{LatestHeight: clienttypes.NewHeight(0, c.clientHeight)}
+		prover := &upgraderProver{upgradeHeight: c.upgradeHeight}
+
+		halted, err := UpgradeClientIfHalted(context.TODO(), NewProvableChain(src, prover), newTestProvableChain(dst))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if halted != c.halted {
+			t.Errorf("%s: unexpected halted: actual=%v, expected=%v", c.name, halted, c.halted)
+		}
+
+		var sent []string
+		for _, msgs := range dst.sent {
+			for _, msg := range msgs {
+				sent = append(sent, sdk.MsgTypeURL(msg))
+			}
+		}
+		if len(sent) != len(c.expected) {
+			t.Errorf("%s: unexpected msgs: actual=%v, expected=%v", c.name, sent, c.expected)
+			continue
+		}
+		for i := range sent {
+			if sent[i] != c.expected[i] {
+				t.Errorf("%s: unexpected msgs: actual=%v, expected=%v", c.name, sent, c.expected)
+				break
+			}
+		}
+		if len(sent) > 0 && !prover.provedAt.EQ(c.upgradeHeight) {
+			t.Errorf("%s: the upgraded client is expected to be proven at the upgrade height: %v", c.name, prover.provedAt)
+		}
+	}
+}
+
+func TestUpgradeClientWithoutUpgrader(t *testing.T) {
+	src, dst := newStateChainPair()
+	halted, err := UpgradeClientIfHalted(context.TODO(), newTestProvableChain(src), newTestProvableChain(dst))
+	if err != nil || halted {
+		t.Errorf("a chain without ClientUpgrader is never regarded as halted: halted=%v, err=%v", halted, err)
+	}
+	if err := UpgradeClient(context.TODO(), newTestProvableChain(src), newTestProvableChain(dst), 100); err == nil {
+		t.Error("the upgrade is expected to fail without ClientUpgrader")
+	}
+}
@@ -23,6 +23,8 @@ const (
 	PathConfigChannelID    PathConfigKey = "channel-id"
 	PathConfigOrder        PathConfigKey = "order"
 	PathConfigVersion      PathConfigKey = "version"
+
+	PathConfigSubstituteClientID PathConfigKey = "substitute-client-id"
 )
 
 type ConfigI interface {
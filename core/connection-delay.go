@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+)
+
+// ConnectionDelayQuerier is an optional interface of Chain required to relay packets over a connection with a non-zero delay period
+type ConnectionDelayQuerier interface {
+	// QueryConsensusStateHeights returns the heights of the consensus states of the light client of the path end
+	QueryConsensusStateHeights(ctx QueryContext) ([]ibcexported.Height, error)
+
+	// QueryConsensusStateProcessed returns the time and height at which the consensus state at `height` of the light client of the path end was stored
+	QueryConsensusStateProcessed(ctx QueryContext, height ibcexported.Height) (processedTime time.Time, processedHeight ibcexported.Height, err error)
+
+	// QueryBlockDelay returns the number of blocks that this chain requires to pass in addition to `delayPeriod`
+	QueryBlockDelay(ctx QueryContext, delayPeriod time.Duration) (uint64, error)
+}
+
+// queryDelayPeriod returns the delay period of the connection of the path end of `chain`
+func queryDelayPeriod(ctx context.Context, chain *ProvableChain) (time.Duration, error) {
+	height, err := chain.LatestHeight(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the latest height: %v", err)
+	}
+	conn, err := chain.QueryConnection(NewQueryContext(ctx, height), chain.Path().ConnectionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query the connection: %v", err)
+	}
+	return time.Duration(conn.Connection.DelayPeriod), nil
+}
+
+// queryMaturedClientHeight returns the latest height of the consensus states of the client on `receiver`
+// for which both the time delay and the block delay have passed since it was stored, or false if there is no such consensus state.
+// The delay of each consensus state is independent of the later client updates, so the client can be updated while waiting for it.
+func queryMaturedClientHeight(ctx context.Context, receiver *ProvableChain, delayPeriod time.Duration) (ibcexported.Height, bool, error) {
+	querier, ok := receiver.Chain.(ConnectionDelayQuerier)
+	if !ok {
+		return nil, false, fmt.Errorf("chain %s doesn't support connections with a delay period", receiver.ChainID())
+	}
+
+	height, err := receiver.LatestHeight(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get the latest height: %v", err)
+	}
+	queryCtx := NewQueryContext(ctx, height)
+
+	heights, err := querier.QueryConsensusStateHeights(queryCtx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query the consensus state heights: %v", err)
+	}
+	blockDelay, err := querier.QueryBlockDelay(queryCtx, delayPeriod)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query the block delay: %v", err)
+	}
+	now, err := receiver.Timestamp(ctx, height)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get the timestamp of the latest block: %v", err)
+	}
+
+	// the consensus states are checked from the latest one, and the older ones are usually stored earlier
+	heights = slices.Clone(heights)
+	slices.SortFunc(heights, func(a, b ibcexported.Height) int {
+		if a.GT(b) {
+			return -1
+		} else if a.LT(b) {
+			return 1
+		}
+		return 0
+	})
+	for _, h := range heights {
+		processedTime, processedHeight, err := querier.QueryConsensusStateProcessed(queryCtx, h)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to query when the consensus state was stored: %v", err)
+		}
+		// the latest block is checked instead of the block including the proofs, which is stricter than the verification on chain
+		if !now.Before(processedTime.Add(delayPeriod)) &&
+			height.GetRevisionHeight() >= processedHeight.GetRevisionHeight()+blockDelay {
+			return h, true, nil
+		}
+	}
+	return nil, false, nil
+}
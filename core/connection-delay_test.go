@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+const testDelayPeriod = 10 * time.Second
+
+// delayChain is a stateChain that serves when the consensus states of its client were stored
+type delayChain struct {
+	*stateChain
+	// processed are the times and heights at which the consensus state at each revision height was stored
+	processed  map[uint64]processedAt
+	blockDelay uint64
+}
+
+type processedAt struct {
+	time   time.Time
+	height uint64
+}
+
+func (c *delayChain) QueryConsensusStateHeights(ctx QueryContext) ([]ibcexported.Height, error) {
+	var heights []ibcexported.Height
+	for h := range c.processed {
+		heights = append(heights, clienttypes.NewHeight(0, h))
+	}
+	return heights, nil
+}
+
+func (c *delayChain) QueryConsensusStateProcessed(ctx QueryContext, height ibcexported.Height) (time.Time, ibcexported.Height, error) {
+	p := c.processed[height.GetRevisionHeight()]
+	return p.time, clienttypes.NewHeight(0, p.height), nil
+}
+
+func (c *delayChain) QueryBlockDelay(ctx QueryContext, delayPeriod time.Duration) (uint64, error) {
+	return c.blockDelay, nil
+}
+
+// stored returns when a consensus state was stored `ago` before the latest block at height `height`
+func (c *delayChain) stored(ago time.Duration, height uint64) processedAt {
+	return processedAt{time: c.timestamp.Add(-ago), height: height}
+}
+
+// delaySyncHeaders is a stateSyncHeaders that returns the header at the latest height of the chain to update the client
+type delaySyncHeaders struct {
+	stateSyncHeaders
+}
+
+func (sh delaySyncHeaders) SetupHeadersForUpdate(ctx context.Context, src, dst ChainLightClient) ([]Header, error) {
+	return []Header{&testHeader{height: sh.chains[src.ChainID()].height}}, nil
+}
+
+func TestQueryMaturedClientHeight(t *testing.T) {
+	cases := []struct {
+		name       string
+		processed  func(c *delayChain) map[uint64]processedAt
+		blockDelay uint64
+		expected   uint64
+		passed     bool
+	}{
+		{"no consensus state", func(c *delayChain) map[uint64]processedAt {
+			return nil
+		}, 0, 0, false},
+		{"latest passed", func(c *delayChain) map[uint64]processedAt {
+			return map[uint64]processedAt{80: c.stored(30*time.Second, 70), 90: c.stored(20*time.Second, 80)}
+		}, 0, 90, true},
+		{"time delay of the latest not passed", func(c *delayChain) map[uint64]processedAt {
+			return map[uint64]processedAt{80: c.stored(30*time.Second, 70), 90: c.stored(5*time.Second, 95)}
+		}, 0, 80, true},
+		{"time delay not passed", func(c *delayChain) map[uint64]processedAt {
+			return map[uint64]processedAt{90: c.stored(5*time.Second, 95)}
+		}, 0, 0, false},
+		{"block delay of the latest not passed", func(c *delayChain) map[uint64]processedAt {
+			return map[uint64]processedAt{70: c.stored(40*time.Second, 60), 90: c.stored(20*time.Second, 80)}
+		}, 30, 70, true},
+		{"block delay not passed", func(c *delayChain) map[uint64]processedAt {
+			return map[uint64]processedAt{90: c.stored(20*time.Second, 80)}
+		}, 30, 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src, _ := newStateChainPair()
+			chain := &delayChain{stateChain: src, blockDelay: c.blockDelay}
+			chain.processed = c.processed(chain)
+
+			height, passed, err := queryMaturedClientHeight(context.TODO(), newTestProvableChain(chain), testDelayPeriod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if passed != c.passed {
+				t.Fatalf("unexpected passed: %v", passed)
+			}
+			if passed && height.GetRevisionHeight() != c.expected {
+				t.Errorf("unexpected height: actual=%v, expected=%v", height, c.expected)
+			}
+		})
+	}
+}
+
+func TestQueryMaturedClientHeightWithoutQuerier(t *testing.T) {
+	src, _ := newStateChainPair()
+	if _, _, err := queryMaturedClientHeight(context.TODO(), newTestProvableChain(src), testDelayPeriod); err == nil {
+		t.Error("expected an error for the chain without ConnectionDelayQuerier")
+	}
+}
+
+func TestDelayedPackets(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	packets := PacketInfoList{
+		{Packet: chantypes.Packet{Sequence: 1}, EventHeight: clienttypes.NewHeight(0, 60)},
+		{Packet: chantypes.Packet{Sequence: 2}, EventHeight: clienttypes.NewHeight(0, 85)},
+		{Packet: chantypes.Packet{Sequence: 3}, EventHeight: clienttypes.NewHeight(0, 95)},
+	}
+
+	cases := []struct {
+		name        string
+		delayPeriod time.Duration
+		processed   func(c *delayChain) map[uint64]processedAt
+		expected    []uint64
+		queryHeight uint64
+	}{
+		{"no delay period", 0, func(c *delayChain) map[uint64]processedAt {
+			return nil
+		}, []uint64{1, 2, 3}, 100},
+		{"matured consensus state", testDelayPeriod, func(c *delayChain) map[uint64]processedAt {
+			return map[uint64]processedAt{90: c.stored(20*time.Second, 80)}
+		}, []uint64{1, 2}, 90},
+		{"older matured consensus state", testDelayPeriod, func(c *delayChain) map[uint64]processedAt {
+			return map[uint64]processedAt{70: c.stored(30*time.Second, 60), 90: c.stored(5*time.Second, 95)}
+		}, []uint64{1}, 70},
+		{"no matured consensus state", testDelayPeriod, func(c *delayChain) map[uint64]processedAt {
+			return map[uint64]processedAt{90: c.stored(5*time.Second, 95)}
+		}, nil, 100},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src, dst := newStateChainPair()
+			receiver := &delayChain{stateChain: dst}
+			receiver.processed = c.processed(receiver)
+			st := &NaiveStrategy{DelayPeriod: c.delayPeriod}
+
+			queryCtx := NewQueryContext(context.TODO(), src.height)
+			ret, retCtx, err := st.delayedPackets(context.TODO(), queryCtx, newTestProvableChain(src), newTestProvableChain(receiver), packets)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var seqs []uint64
+			for _, p := range ret {
+				seqs = append(seqs, p.Sequence)
+			}
+			if len(seqs) != len(c.expected) {
+				t.Fatalf("unexpected packets: actual=%v, expected=%v", seqs, c.expected)
+			}
+			for i := range seqs {
+				if seqs[i] != c.expected[i] {
+					t.Fatalf("unexpected packets: actual=%v, expected=%v", seqs, c.expected)
+				}
+			}
+			if h := retCtx.Height().GetRevisionHeight(); h != c.queryHeight {
+				t.Errorf("unexpected query height: actual=%v, expected=%v", h, c.queryHeight)
+			}
+		})
+	}
+}
+
+// TestUpdateClientsWithDelay checks that the client is updated while waiting for the delay period,
+// so that the packets committed after its latest consensus state don't wait for another delay period.
+func TestUpdateClientsWithDelay(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	src, dst := newStateChainPair()
+	srcChain := &delayChain{stateChain: src}
+	srcChain.processed = map[uint64]processedAt{90: srcChain.stored(5*time.Second, 95)}
+	st := &NaiveStrategy{DelayPeriod: testDelayPeriod}
+	sh := delaySyncHeaders{newStateSyncHeaders(src, dst)}
+
+	msgs, err := st.UpdateClients(context.TODO(), newTestProvableChain(srcChain), newTestProvableChain(dst), true, false, false, false, sh, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs.Src) != 1 || len(msgs.Dst) != 0 {
+		t.Fatalf("unexpected msgs: src=%v, dst=%v", msgs.Src, msgs.Dst)
+	}
+	if msg, ok := msgs.Src[0].(*clienttypes.MsgUpdateClient); !ok || msg.ClientId != src.path.ClientID {
+		t.Errorf("unexpected msg: %v", msgs.Src[0])
+	}
+}
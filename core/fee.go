@@ -0,0 +1,226 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	feetypes "github.com/cosmos/ibc-go/v8/modules/apps/29-fee/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// FeePolicyPrioritize relays the packets with escrowed ICS-29 fees before the others
+	FeePolicyPrioritize = "prioritize"
+	// FeePolicyOnly relays only the packets with escrowed ICS-29 fees
+	FeePolicyOnly = "only"
+)
+
+// FeeQuerier is an optional interface of Chain required to relay packets over channels with the ICS-29 fee middleware
+type FeeQuerier interface {
+	// QueryIncentivizedPacketFee returns the sum of the fees escrowed for the packet `packetID` sent from this chain,
+	// or nil if the packet is not incentivized
+	QueryIncentivizedPacketFee(ctx QueryContext, packetID chantypes.PacketId) (*feetypes.Fee, error)
+}
+
+// FeeVersion returns the channel version that stacks the ICS-29 fee middleware on `appVersion`.
+// `appVersion` is returned as it is if it already enables the fee middleware.
+func FeeVersion(appVersion string) string {
+	if IsFeeEnabledVersion(appVersion) {
+		return appVersion
+	}
+	return string(feetypes.ModuleCdc.MustMarshalJSON(&feetypes.Metadata{
+		FeeVersion: feetypes.Version,
+		AppVersion: appVersion,
+	}))
+}
+
+// IsFeeEnabledVersion returns true if the channel version `version` enables the ICS-29 fee middleware
+func IsFeeEnabledVersion(version string) bool {
+	_, fee := splitFeeVersion(version)
+	return fee
+}
+
+// splitFeeVersion returns the application version stacked on the ICS-29 fee middleware and true if `version` enables it,
+// otherwise `version` as it is and false
+func splitFeeVersion(version string) (string, bool) {
+	if metadata, err := feetypes.MetadataFromVersion(version); err == nil && metadata.FeeVersion == feetypes.Version {
+		return metadata.AppVersion, true
+	}
+	return version, false
+}
+
+func validateFeePolicy(policy string) error {
+	switch policy {
+	case "", FeePolicyPrioritize, FeePolicyOnly:
+		return nil
+	default:
+		return fmt.Errorf("invalid fee policy: %s", policy)
+	}
+}
+
+// incentivizedPackets sets the fees escrowed for `packets` sent from `sender`, and then orders or filters them by the fee policy.
+// The fees are queried only if the fee policy is set or the channel of `sender` enables the fee middleware on chain,
+// which may differ from the version in the path config after a channel upgrade.
+// The order of packets is kept on ORDERED channels, on which packets can't be relayed out of order.
+func (st *NaiveStrategy) incentivizedPackets(ctx QueryContext, sender *ProvableChain, packets PacketInfoList) (PacketInfoList, error) {
+	if len(packets) == 0 {
+		return packets, nil
+	} else if st.FeePolicy == "" {
+		res, err := sender.QueryChannel(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query the channel: %v", err)
+		} else if !IsFeeEnabledVersion(res.Channel.Version) {
+			return packets, nil
+		}
+	}
+	querier, ok := sender.Chain.(FeeQuerier)
+	if !ok {
+		if st.FeePolicy == "" {
+			return packets, nil
+		}
+		return nil, fmt.Errorf("chain %s doesn't support querying packet fees", sender.ChainID())
+	}
+
+	for _, p := range packets {
+		fee, err := querier.QueryIncentivizedPacketFee(ctx, chantypes.NewPacketID(p.SourcePort, p.SourceChannel, p.Sequence))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query the fee of packet %d: %v", p.Sequence, err)
+		}
+		p.Fee = fee
+	}
+
+	if st.Ordered {
+		return packets, nil
+	}
+	switch st.FeePolicy {
+	case FeePolicyPrioritize:
+		ret := slices.Clone(packets)
+		slices.SortStableFunc(ret, func(a, b *PacketInfo) int {
+			switch {
+			case a.Fee != nil && b.Fee == nil:
+				return -1
+			case a.Fee == nil && b.Fee != nil:
+				return 1
+			default:
+				return 0
+			}
+		})
+		return ret, nil
+	case FeePolicyOnly:
+		var ret PacketInfoList
+		for _, p := range packets {
+			if p.Fee != nil {
+				ret = append(ret, p)
+			}
+		}
+		if skipped := len(packets) - len(ret); skipped > 0 {
+			GetChannelLogger(sender).WithSpanContext(ctx.Context()).Info("skip packets without fees", "num_packets", skipped)
+		}
+		return ret, nil
+	default:
+		return packets, nil
+	}
+}
+
+// recordFeesEarned logs and records the fees of the incentivized packets relayed by the committed msgs.
+// The recv fee is attributed to MsgRecvPacket and the ack fee to MsgAcknowledgement, both of which are paid on the chain sending the packet.
+func recordFeesEarned(ctx context.Context, src, dst *ProvableChain, packetLists []PacketInfoList, msgs *RelayMsgs) {
+	fees := make(map[chantypes.PacketId]*feetypes.Fee)
+	for _, packets := range packetLists {
+		for _, p := range packets {
+			if p.Fee != nil {
+				fees[chantypes.NewPacketID(p.SourcePort, p.SourceChannel, p.Sequence)] = p.Fee
+			}
+		}
+	}
+	if len(fees) == 0 {
+		return
+	}
+
+	earned := make(map[string]sdk.Coins)
+	for _, sent := range []struct {
+		msgs   []sdk.Msg
+		msgIDs []MsgID
+	}{{msgs.Src, msgs.SrcMsgIDs}, {msgs.Dst, msgs.DstMsgIDs}} {
+		for i, msg := range sent.msgs {
+			if i >= len(sent.msgIDs) || sent.msgIDs[i] == nil {
+				continue
+			}
+			var (
+				packet chantypes.Packet
+				coins  func(*feetypes.Fee) sdk.Coins
+			)
+			switch msg := msg.(type) {
+			case *chantypes.MsgRecvPacket:
+				packet, coins = msg.Packet, func(f *feetypes.Fee) sdk.Coins { return f.RecvFee }
+			case *chantypes.MsgAcknowledgement:
+				packet, coins = msg.Packet, func(f *feetypes.Fee) sdk.Coins { return f.AckFee }
+			default:
+				continue
+			}
+			fee, ok := fees[chantypes.NewPacketID(packet.SourcePort, packet.SourceChannel, packet.Sequence)]
+			if !ok {
+				continue
+			}
+			payer := dst
+			if packet.SourcePort == src.Path().PortID && packet.SourceChannel == src.Path().ChannelID {
+				payer = src
+			}
+			earned[payer.ChainID()] = earned[payer.ChainID()].Add(coins(fee)...)
+		}
+	}
+
+	for _, chain := range []*ProvableChain{src, dst} {
+		coins := earned[chain.ChainID()]
+		if coins.IsZero() {
+			continue
+		}
+		GetChannelLogger(chain).WithSpanContext(ctx).Info("★ Fees earned", "fees", coins.String())
+		for _, coin := range coins {
+			if !coin.Amount.IsInt64() {
+				continue
+			}
+			metrics.FeesEarnedCounter.Add(ctx, coin.Amount.Int64(), api.WithAttributes(
+				attribute.Key("chain_id").String(chain.ChainID()),
+				attribute.Key("denom").String(coin.Denom),
+			))
+		}
+	}
+}
+
+// RegisterPayee registers `payee` on `chain` as the address to which the ack and timeout fees for the relayer's msgs over the channel of the path are paid
+func RegisterPayee(ctx context.Context, chain, cp *ProvableChain, payee string) error {
+	relayer, err := chain.GetAddress()
+	if err != nil {
+		return fmt.Errorf("failed to get the address of chain %s: %v", chain.ChainID(), err)
+	}
+	pe := chain.Path()
+	msgs := NewRelayMsgs()
+	msgs.Src = append(msgs.Src, feetypes.NewMsgRegisterPayee(pe.PortID, pe.ChannelID, relayer.String(), payee))
+	if msgs.Send(ctx, chain, cp); !msgs.Success() {
+		return fmt.Errorf("failed to send MsgRegisterPayee to chain %s", chain.ChainID())
+	}
+	GetChannelLogger(chain).WithSpanContext(ctx).Info("★ Payee registered", "payee", payee)
+	return nil
+}
+
+// RegisterCounterpartyPayee registers `counterpartyPayee` on `chain` as the address on `cp` to which the recv fees for the relayer's MsgRecvPacket on `chain` are paid
+func RegisterCounterpartyPayee(ctx context.Context, chain, cp *ProvableChain, counterpartyPayee string) error {
+	relayer, err := chain.GetAddress()
+	if err != nil {
+		return fmt.Errorf("failed to get the address of chain %s: %v", chain.ChainID(), err)
+	}
+	pe := chain.Path()
+	msgs := NewRelayMsgs()
+	msgs.Src = append(msgs.Src, feetypes.NewMsgRegisterCounterpartyPayee(pe.PortID, pe.ChannelID, relayer.String(), counterpartyPayee))
+	if msgs.Send(ctx, chain, cp); !msgs.Success() {
+		return fmt.Errorf("failed to send MsgRegisterCounterpartyPayee to chain %s", chain.ChainID())
+	}
+	GetChannelLogger(chain).WithSpanContext(ctx).Info("★ Counterparty payee registered", "counterparty_payee", counterpartyPayee)
+	return nil
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	feetypes "github.com/cosmos/ibc-go/v8/modules/apps/29-fee/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// feeChain is a stateChain that has the fees escrowed for the packets of each sequence
+type feeChain struct {
+	*stateChain
+	fees map[uint64]*feetypes.Fee
+	// queried is the number of the packets whose fees are queried
+	queried int
+}
+
+func (c *feeChain) QueryIncentivizedPacketFee(ctx QueryContext, packetID chantypes.PacketId) (*feetypes.Fee, error) {
+	c.queried++
+	return c.fees[packetID.Sequence], nil
+}
+
+func TestIncentivizedPackets(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	fee := &feetypes.Fee{RecvFee: sdk.NewCoins(sdk.NewInt64Coin("stake", 10))}
+	cases := []struct {
+		name          string
+		policy        string
+		ordered       bool
+		onChainFee    bool
+		expected      []uint64
+		expectedQuery bool
+	}{
+		{"fee middleware disabled", "", false, false, []uint64{1, 2, 3}, false},
+		{"fee middleware enabled on chain", "", false, true, []uint64{1, 2, 3}, true},
+		{"prioritize", FeePolicyPrioritize, false, true, []uint64{2, 1, 3}, true},
+		{"only", FeePolicyOnly, false, true, []uint64{2}, true},
+		{"only on the channel without fee middleware", FeePolicyOnly, false, false, []uint64{2}, true},
+		{"ordered", FeePolicyOnly, true, true, []uint64{1, 2, 3}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src, _ := newStateChainPair()
+			// the path config has the version before the channel upgrade
+			src.path.Version = "ics20-1"
+			if c.onChainFee {
+				src.channel.Version = FeeVersion("ics20-1")
+			}
+			chain := &feeChain{stateChain: src, fees: map[uint64]*feetypes.Fee{2: fee}}
+			st := &NaiveStrategy{FeePolicy: c.policy, Ordered: c.ordered}
+			packets := PacketInfoList{
+				{Packet: chantypes.Packet{Sequence: 1, SourcePort: "transfer", SourceChannel: "channel-0"}},
+				{Packet: chantypes.Packet{Sequence: 2, SourcePort: "transfer", SourceChannel: "channel-0"}},
+				{Packet: chantypes.Packet{Sequence: 3, SourcePort: "transfer", SourceChannel: "channel-0"}},
+			}
+
+			ret, err := st.incentivizedPackets(NewQueryContext(context.TODO(), src.height), newTestProvableChain(chain), packets)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if seqs := ret.ExtractSequenceList(); len(seqs) != len(c.expected) {
+				t.Fatalf("unexpected packets: actual=%v, expected=%v", seqs, c.expected)
+			} else {
+				for i := range seqs {
+					if seqs[i] != c.expected[i] {
+						t.Fatalf("unexpected packets: actual=%v, expected=%v", seqs, c.expected)
+					}
+				}
+			}
+			if queried := chain.queried > 0; queried != c.expectedQuery {
+				t.Errorf("unexpected fee query: %v", queried)
+			}
+			if c.expectedQuery && (packets[1].Fee != fee || packets[0].Fee != nil) {
+				t.Errorf("unexpected fees: %v, %v", packets[0].Fee, packets[1].Fee)
+			}
+		})
+	}
+}
+
+func TestIncentivizedPacketsWithoutQuerier(t *testing.T) {
+	src, _ := newStateChainPair()
+	src.channel.Version = FeeVersion("ics20-1")
+	packets := PacketInfoList{{Packet: chantypes.Packet{Sequence: 1}}}
+	queryCtx := NewQueryContext(context.TODO(), src.height)
+
+	// the packets are relayed without fees unless the fee policy requires them
+	if ret, err := (&NaiveStrategy{}).incentivizedPackets(queryCtx, newTestProvableChain(src), packets); err != nil || len(ret) != 1 {
+		t.Errorf("unexpected result: packets=%v, err=%v", ret, err)
+	}
+	if _, err := (&NaiveStrategy{FeePolicy: FeePolicyOnly}).incentivizedPackets(queryCtx, newTestProvableChain(src), packets); err == nil {
+		t.Error("expected an error for the chain without FeeQuerier")
+	}
+}
+
+func TestRecordFeesEarned(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	meter, reader := newTestMeter()
+	metrics.FeesEarnedCounter, _ = meter.Int64Counter("relayer.fees_earned")
+	t.Cleanup(func() {
+		if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	src, dst := newStateChainPair()
+	fee := &feetypes.Fee{
+		RecvFee:    sdk.NewCoins(sdk.NewInt64Coin("stake", 10)),
+		AckFee:     sdk.NewCoins(sdk.NewInt64Coin("stake", 3)),
+		TimeoutFee: sdk.NewCoins(sdk.NewInt64Coin("stake", 5)),
+	}
+	// packet 1 and 2 are sent from src, and packet 3 from dst
+	packet := func(seq uint64, sender *stateChain) *PacketInfo {
+		return &PacketInfo{Packet: chantypes.Packet{Sequence: seq, SourcePort: sender.path.PortID, SourceChannel: sender.path.ChannelID}, Fee: fee}
+	}
+	p1, p2, p3 := packet(1, src), packet(2, src), packet(3, dst)
+	unpaid := &PacketInfo{Packet: chantypes.Packet{Sequence: 4, SourcePort: "transfer", SourceChannel: "channel-0"}}
+
+	msgs := NewRelayMsgs()
+	msgs.Dst = []sdk.Msg{
+		&chantypes.MsgRecvPacket{Packet: p1.Packet},
+		&chantypes.MsgRecvPacket{Packet: p2.Packet},
+		&chantypes.MsgRecvPacket{Packet: unpaid.Packet},
+	}
+	msgs.Src = []sdk.Msg{
+		&chantypes.MsgAcknowledgement{Packet: p3.Packet},
+	}
+	// the msg for packet 2 was not committed
+	msgs.DstMsgIDs = []MsgID{&testMsgID{}, nil, &testMsgID{}}
+	msgs.SrcMsgIDs = []MsgID{&testMsgID{}}
+
+	recordFeesEarned(context.TODO(), newTestProvableChain(src), newTestProvableChain(dst), []PacketInfoList{{p1, p2, unpaid}, {p3}}, msgs)
+
+	denom := attribute.Key("denom").String("stake")
+	if v := counterValue(t, reader, "relayer.fees_earned", attribute.Key("chain_id").String("ibc0"), denom); v != 10 {
+		t.Errorf("unexpected fees earned on src: %d", v)
+	}
+	if v := counterValue(t, reader, "relayer.fees_earned", attribute.Key("chain_id").String("ibc1"), denom); v != 3 {
+		t.Errorf("unexpected fees earned on dst: %d", v)
+	}
+}
+
+func TestRegisterPayee(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	src, dst := newStateChainPair()
+	if err := RegisterPayee(context.TODO(), newTestProvableChain(src), newTestProvableChain(dst), "payee"); err != nil {
+		t.Fatal(err)
+	}
+	if len(src.sent) != 1 || len(src.sent[0]) != 1 || len(dst.sent) != 0 {
+		t.Fatalf("unexpected txs: src=%v, dst=%v", src.sent, dst.sent)
+	}
+	msg, ok := src.sent[0][0].(*feetypes.MsgRegisterPayee)
+	if !ok {
+		t.Fatalf("unexpected msg: %T", src.sent[0][0])
+	}
+	if msg.PortId != "transfer" || msg.ChannelId != "channel-0" || msg.Relayer != sdk.AccAddress("relayer").String() || msg.Payee != "payee" {
+		t.Errorf("unexpected MsgRegisterPayee: %v", msg)
+	}
+}
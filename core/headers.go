@@ -71,7 +71,7 @@ func NewSyncHeaders(ctx context.Context, src, dst ChainInfoLightClient) (SyncHea
 
 // Updates updates the headers on both chains
 func (sh *syncHeaders) Updates(ctx context.Context, src, dst ChainInfoLightClient) error {
-	logger := GetChainPairLogger(src, dst)
+	logger := GetChainPairLogger(src, dst).WithSpanContext(ctx)
 	if err := ensureDifferentChains(src, dst); err != nil {
 		logger.Error("error ensuring different chains", err)
 		return err
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	icatypes "github.com/cosmos/ibc-go/v8/modules/apps/27-interchain-accounts/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+)
+
+func isICAControllerPort(portID string) bool {
+	return strings.HasPrefix(portID, icatypes.ControllerPortPrefix)
+}
+
+func isICAHostPort(portID string) bool {
+	return portID == icatypes.HostPortID
+}
+
+// chanInitVersion returns the version proposed by `pe` in MsgChannelOpenInit.
+// On an ICA controller port, the ICS-27 metadata is built from the connection identifiers of the path,
+// and the encoding, tx type and address are taken from the configured version if it is a metadata.
+func (pe *PathEnd) chanInitVersion(dst *PathEnd) string {
+	if !isICAControllerPort(pe.PortID) {
+		return pe.Version
+	}
+	appVersion, fee := splitFeeVersion(pe.Version)
+	metadata := icatypes.NewDefaultMetadata(pe.ConnectionID, dst.ConnectionID)
+	if configured, err := icatypes.MetadataFromVersion(appVersion); err == nil {
+		if configured.Encoding != "" {
+			metadata.Encoding = configured.Encoding
+		}
+		if configured.TxType != "" {
+			metadata.TxType = configured.TxType
+		}
+		metadata.Address = configured.Address
+	}
+	version := string(icatypes.ModuleCdc.MustMarshalJSON(&metadata))
+	if fee {
+		return FeeVersion(version)
+	}
+	return version
+}
+
+// chanTryVersion returns the version proposed by `pe` in MsgChannelOpenTry.
+// The ICA host accepts the metadata proposed by the controller, in which it fills in the address of the interchain account.
+func (pe *PathEnd) chanTryVersion(counterpartyVersion string) string {
+	if isICAHostPort(pe.PortID) {
+		return counterpartyVersion
+	}
+	return pe.Version
+}
+
+// validateICAVersion validates the ICS-27 metadata `version` proposed on the channel between `pe` and `cp` if either of them is an ICA port
+func validateICAVersion(pe, cp *PathEnd, version string) error {
+	var controller, host *PathEnd
+	switch {
+	case isICAControllerPort(pe.PortID) && isICAHostPort(cp.PortID):
+		controller, host = pe, cp
+	case isICAHostPort(pe.PortID) && isICAControllerPort(cp.PortID):
+		controller, host = cp, pe
+	case isICAControllerPort(pe.PortID) || isICAHostPort(pe.PortID) || isICAControllerPort(cp.PortID) || isICAHostPort(cp.PortID):
+		return fmt.Errorf("an ICA controller port must be paired with the ICA host port: %s and %s", pe.PortID, cp.PortID)
+	default:
+		return nil
+	}
+
+	appVersion, _ := splitFeeVersion(version)
+	metadata, err := icatypes.MetadataFromVersion(appVersion)
+	if err != nil {
+		return fmt.Errorf("invalid ICS-27 metadata %s: %v", appVersion, err)
+	}
+	if metadata.Version != icatypes.Version {
+		return fmt.Errorf("invalid ICS-27 version: expected %s, got %s", icatypes.Version, metadata.Version)
+	}
+	if metadata.ControllerConnectionId != controller.ConnectionID || metadata.HostConnectionId != host.ConnectionID {
+		return fmt.Errorf("ICS-27 metadata doesn't match the connections of the path: expected %s and %s, got %s and %s",
+			controller.ConnectionID, host.ConnectionID, metadata.ControllerConnectionId, metadata.HostConnectionId)
+	}
+	if !slices.Contains([]string{icatypes.EncodingProtobuf, icatypes.EncodingProto3JSON}, metadata.Encoding) {
+		return fmt.Errorf("unsupported ICS-27 encoding: %s", metadata.Encoding)
+	}
+	if metadata.TxType != icatypes.TxTypeSDKMultiMsg {
+		return fmt.Errorf("unsupported ICS-27 tx type: %s", metadata.TxType)
+	}
+	return nil
+}
+
+// ReopenChannel opens a new channel on the same ports as the closed channel of the path, and updates the path config with the new channel identifiers.
+// If only one end of the channel is closed (e.g. by a packet timeout on an ORDERED channel), MsgChannelCloseConfirm is relayed to the other end first.
+// The path config is updated only after both ends are closed, and then the new channel identifiers are saved as the handshake proceeds.
+// On an ICA channel, the controller proposes the version of the closed channel so that the host keeps the interchain account.
+func ReopenChannel(ctx context.Context, pathName string, src, dst *ProvableChain, to time.Duration) error {
+	logger := GetChannelPairLogger(src, dst)
+
+	if err := confirmChannelClose(ctx, src, dst); err != nil {
+		return err
+	}
+
+	srcCtx, err := latestQueryContext(ctx, src)
+	if err != nil {
+		return err
+	}
+	dstCtx, err := latestQueryContext(ctx, dst)
+	if err != nil {
+		return err
+	}
+	srcChan, dstChan, err := QueryChannelPair(srcCtx, dstCtx, src, dst, false)
+	if err != nil {
+		return err
+	}
+	if srcChan.Channel.State != chantypes.CLOSED || dstChan.Channel.State != chantypes.CLOSED {
+		return fmt.Errorf("the channel of path %s is not closed on both chains: src=%s, dst=%s", pathName, srcChan.Channel.State, dstChan.Channel.State)
+	}
+	logger.Info("reopen the closed channel",
+		"src_channel_id", src.Path().ChannelID,
+		"dst_channel_id", dst.Path().ChannelID,
+	)
+
+	for _, c := range []struct {
+		chain   *ProvableChain
+		channel *chantypes.Channel
+	}{
+		{src, srcChan.Channel},
+		{dst, dstChan.Channel},
+	} {
+		kv := map[PathConfigKey]string{PathConfigChannelID: ""}
+		if isICAControllerPort(c.chain.Path().PortID) && c.channel.Version != "" {
+			kv[PathConfigVersion] = c.channel.Version
+		}
+		if err := config.UpdatePathConfig(pathName, c.chain.ChainID(), kv); err != nil {
+			return err
+		}
+	}
+	return CreateChannel(ctx, pathName, src, dst, to)
+}
+
+// confirmChannelClose relays MsgChannelCloseConfirm with the client update to the end of the channel of the path
+// that is not closed yet while the other end is closed. It does nothing unless exactly one end is closed.
+func confirmChannelClose(ctx context.Context, src, dst *ProvableChain) error {
+	logger := GetChannelPairLogger(src, dst)
+
+	sh, err := NewSyncHeaders(ctx, src, dst)
+	if err != nil {
+		return err
+	}
+	srcChan, dstChan, err := QueryChannelPair(sh.GetQueryContext(ctx, src.ChainID()), sh.GetQueryContext(ctx, dst.ChainID()), src, dst, true)
+	if err != nil {
+		return err
+	}
+
+	msgs := NewRelayMsgs()
+	switch {
+	case srcChan.Channel.State == chantypes.CLOSED && dstChan.Channel.State != chantypes.CLOSED && dstChan.Channel.State != chantypes.UNINITIALIZED:
+		hs, err := sh.SetupHeadersForUpdate(ctx, src, dst)
+		if err != nil {
+			return err
+		}
+		addr := mustGetAddress(dst)
+		if len(hs) > 0 {
+			msgs.Dst = append(msgs.Dst, dst.Path().UpdateClients(hs, addr)...)
+		}
+		msgs.Dst = append(msgs.Dst, dst.Path().ChanCloseConfirm(srcChan, addr))
+	case dstChan.Channel.State == chantypes.CLOSED && srcChan.Channel.State != chantypes.CLOSED && srcChan.Channel.State != chantypes.UNINITIALIZED:
+		hs, err := sh.SetupHeadersForUpdate(ctx, dst, src)
+		if err != nil {
+			return err
+		}
+		addr := mustGetAddress(src)
+		if len(hs) > 0 {
+			msgs.Src = append(msgs.Src, src.Path().UpdateClients(hs, addr)...)
+		}
+		msgs.Src = append(msgs.Src, src.Path().ChanCloseConfirm(dstChan, addr))
+	default:
+		return nil
+	}
+
+	if msgs.Send(ctx, src, dst); !msgs.Success() {
+		return fmt.Errorf("failed to send MsgChannelCloseConfirm")
+	}
+	logger.Info("★ Channel close confirmed")
+	return nil
+}
@@ -0,0 +1,52 @@
+package core_test
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	icatypes "github.com/cosmos/ibc-go/v8/modules/apps/27-interchain-accounts/types"
+	feetypes "github.com/cosmos/ibc-go/v8/modules/apps/29-fee/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/core"
+)
+
+func TestChanInitICAVersion(t *testing.T) {
+	host := &core.PathEnd{ConnectionID: "connection-1", PortID: icatypes.HostPortID}
+	signer := sdk.AccAddress("signer")
+
+	cases := []struct {
+		name     string
+		version  string
+		fee      bool
+		encoding string
+		address  string
+	}{
+		{"bare version", icatypes.Version, false, icatypes.EncodingProtobuf, ""},
+		{"empty version", "", false, icatypes.EncodingProtobuf, ""},
+		{"metadata of the closed channel", `{"version":"ics27-1","controller_connection_id":"connection-9","host_connection_id":"connection-9","address":"cosmos1ica","encoding":"proto3json","tx_type":"sdk_multi_msg"}`, false, icatypes.EncodingProto3JSON, "cosmos1ica"},
+		{"fee-enabled version", core.FeeVersion(icatypes.Version), true, icatypes.EncodingProtobuf, ""},
+	}
+	for _, c := range cases {
+		controller := &core.PathEnd{ConnectionID: "connection-0", PortID: icatypes.ControllerPortPrefix + "owner", Version: c.version}
+		msg := controller.ChanInit(host, signer).(*chantypes.MsgChannelOpenInit)
+
+		version := msg.Channel.Version
+		if c.fee {
+			feeMetadata, err := feetypes.MetadataFromVersion(version)
+			if err != nil || feeMetadata.FeeVersion != feetypes.Version {
+				t.Errorf("%s: fee metadata expected: version=%s, err=%v", c.name, version, err)
+				continue
+			}
+			version = feeMetadata.AppVersion
+		}
+		metadata, err := icatypes.MetadataFromVersion(version)
+		if err != nil {
+			t.Errorf("%s: failed to parse the ICS-27 metadata: version=%s, err=%v", c.name, version, err)
+			continue
+		}
+		expected := icatypes.NewMetadata(icatypes.Version, "connection-0", "connection-1", c.address, c.encoding, icatypes.TxTypeSDKMultiMsg)
+		if metadata != expected {
+			t.Errorf("%s: unexpected metadata: actual=%v, expected=%v", c.name, metadata, expected)
+		}
+	}
+}
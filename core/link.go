@@ -0,0 +1,338 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+)
+
+// LinkQuerier is an optional interface of Chain that lists IBC objects on the chain.
+// Link uses it to reuse existing clients and to resume handshakes whose identifiers are missing in the path config.
+type LinkQuerier interface {
+	// QueryClientStates returns all the light clients on this chain
+	QueryClientStates(ctx QueryContext) (clienttypes.IdentifiedClientStates, error)
+
+	// QueryClientStatusByID returns the status of the light client `clientID`
+	QueryClientStatusByID(ctx QueryContext, clientID string) (ibcexported.Status, error)
+
+	// QueryClientConnections returns the identifiers of the connections associated with the light client `clientID`
+	QueryClientConnections(ctx QueryContext, clientID string) ([]string, error)
+
+	// QueryConnectionChannels returns the channels associated with the connection `connectionID`
+	QueryConnectionChannels(ctx QueryContext, connectionID string) ([]*chantypes.IdentifiedChannel, error)
+}
+
+// Link creates the clients, connection and channel of the path in sequence.
+// Each step is resumed from the state of the chains, so it can be run again after a failure midway.
+// If `reuseExistingClient` is true, an active client tracking the counterparty chain is used instead of creating a new one.
+func Link(ctx context.Context, pathName string, src, dst *ProvableChain, to time.Duration, reuseExistingClient bool) error {
+	logger := GetChainPairLogger(src, dst)
+	defer logger.TimeTrack(time.Now(), "Link")
+
+	if reuseExistingClient {
+		if err := reuseClient(ctx, pathName, src, dst); err != nil {
+			return err
+		}
+		if err := reuseClient(ctx, pathName, dst, src); err != nil {
+			return err
+		}
+	}
+	if err := CreateClients(ctx, pathName, src, dst, nil, nil); err != nil {
+		return err
+	} else if src.Path().ClientID == "" || dst.Path().ClientID == "" {
+		return fmt.Errorf("failed to create clients")
+	}
+
+	if err := resumeConnection(ctx, pathName, src, dst); err != nil {
+		return err
+	}
+	if err := CreateConnection(ctx, pathName, src, dst, to); err != nil {
+		return err
+	}
+
+	if err := resumeChannel(ctx, pathName, src, dst); err != nil {
+		return err
+	}
+	return CreateChannel(ctx, pathName, src, dst, to)
+}
+
+// reuseClient sets the most advanced active client on `chain` that has the same type as a new client tracking `cp` and tracks the same chain
+func reuseClient(ctx context.Context, pathName string, chain, cp *ProvableChain) error {
+	if chain.Path().ClientID != "" {
+		return nil
+	}
+	querier, ok := chain.Chain.(LinkQuerier)
+	if !ok {
+		return fmt.Errorf("chain %s doesn't support listing clients", chain.ChainID())
+	}
+	expected, _, err := cp.CreateInitialLightClientState(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create initial light client state: %v", err)
+	}
+
+	queryCtx, err := latestQueryContext(ctx, chain)
+	if err != nil {
+		return err
+	}
+	states, err := querier.QueryClientStates(queryCtx)
+	if err != nil {
+		return err
+	}
+	var (
+		clientID     string
+		clientHeight ibcexported.Height
+	)
+	for _, s := range states {
+		cs, err := clienttypes.UnpackClientState(s.ClientState)
+		if err != nil {
+			return fmt.Errorf("failed to unpack the client state of %s: %v", s.ClientId, err)
+		}
+		if cs.ClientType() != expected.ClientType() || trackedChainID(cs) != trackedChainID(expected) {
+			continue
+		}
+		if clientHeight != nil && !cs.GetLatestHeight().GT(clientHeight) {
+			continue
+		}
+		if status, err := querier.QueryClientStatusByID(queryCtx, s.ClientId); err != nil {
+			return err
+		} else if status != ibcexported.Active {
+			continue
+		}
+		clientID, clientHeight = s.ClientId, cs.GetLatestHeight()
+	}
+	if clientID == "" {
+		return nil
+	}
+
+	GetChainLogger(chain).Info("reuse the existing client", "client_id", clientID)
+	return config.UpdatePathConfig(pathName, chain.ChainID(), map[PathConfigKey]string{PathConfigClientID: clientID})
+}
+
+// trackedChainID returns the identifier of the chain tracked by `cs` if the client type has it
+func trackedChainID(cs ibcexported.ClientState) string {
+	if cs, ok := cs.(interface{ GetChainID() string }); ok {
+		return cs.GetChainID()
+	}
+	return ""
+}
+
+// resumeConnection finds the connections of the path whose identifiers are missing in the path config,
+// and abandons the connection on dst if both chains have initialized a connection independently.
+func resumeConnection(ctx context.Context, pathName string, src, dst *ProvableChain) error {
+	for _, pair := range [][2]*ProvableChain{{src, dst}, {dst, src}} {
+		chain, cp := pair[0], pair[1]
+		if chain.Path().ConnectionID != "" {
+			continue
+		}
+		connectionID, err := findConnection(ctx, chain, cp)
+		if err != nil {
+			return err
+		} else if connectionID == "" {
+			continue
+		}
+		GetChainLogger(chain).Info("resume the existing connection", "connection_id", connectionID)
+		if err := config.UpdatePathConfig(pathName, chain.ChainID(), map[PathConfigKey]string{PathConfigConnectionID: connectionID}); err != nil {
+			return err
+		}
+	}
+
+	srcCtx, err := latestQueryContext(ctx, src)
+	if err != nil {
+		return err
+	}
+	dstCtx, err := latestQueryContext(ctx, dst)
+	if err != nil {
+		return err
+	}
+	srcConn, dstConn, err := QueryConnectionPair(srcCtx, dstCtx, src, dst, false)
+	if err != nil {
+		return err
+	}
+	// the handshakes initialized by different relayers can't be merged, so the one on src is continued
+	if srcConn.Connection.State == conntypes.INIT && dstConn.Connection.State == conntypes.INIT {
+		GetConnectionPairLogger(src, dst).Warn("both chains have initialized a connection; the one on dst is abandoned",
+			"abandoned_connection_id", dst.Path().ConnectionID)
+		return config.UpdatePathConfig(pathName, dst.ChainID(), map[PathConfigKey]string{PathConfigConnectionID: ""})
+	}
+	return nil
+}
+
+// findConnection returns the most advanced connection on `chain` between the clients of the path that can be paired with the connection on `cp`
+func findConnection(ctx context.Context, chain, cp *ProvableChain) (string, error) {
+	if chain.Path().ClientID == "" {
+		return "", nil
+	}
+	// the counterparty connection knows its peer once the handshake has proceeded
+	if cp.Path().ConnectionID != "" {
+		cpCtx, err := latestQueryContext(ctx, cp)
+		if err != nil {
+			return "", err
+		}
+		res, err := cp.QueryConnection(cpCtx, cp.Path().ConnectionID)
+		if err != nil {
+			return "", err
+		} else if res.Connection.Counterparty.ConnectionId != "" {
+			return res.Connection.Counterparty.ConnectionId, nil
+		}
+	}
+
+	querier, ok := chain.Chain.(LinkQuerier)
+	if !ok {
+		return "", nil
+	}
+	queryCtx, err := latestQueryContext(ctx, chain)
+	if err != nil {
+		return "", err
+	}
+	connectionIDs, err := querier.QueryClientConnections(queryCtx, chain.Path().ClientID)
+	if err != nil {
+		return "", err
+	}
+	var (
+		found string
+		rank  int
+	)
+	for _, connectionID := range connectionIDs {
+		res, err := chain.QueryConnection(queryCtx, connectionID)
+		if err != nil {
+			return "", err
+		}
+		conn := res.Connection
+		if conn.Counterparty.ClientId != cp.Path().ClientID ||
+			(cp.Path().ConnectionID != "" && conn.Counterparty.ConnectionId != "" && conn.Counterparty.ConnectionId != cp.Path().ConnectionID) {
+			continue
+		}
+		if r := connectionStateRank(conn.State); r > 0 && r >= rank {
+			found, rank = connectionID, r
+		}
+	}
+	return found, nil
+}
+
+func connectionStateRank(state conntypes.State) int {
+	switch state {
+	case conntypes.INIT:
+		return 1
+	case conntypes.TRYOPEN:
+		return 2
+	case conntypes.OPEN:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// resumeChannel finds the channels of the path whose identifiers are missing in the path config,
+// and abandons the channel on dst if both chains have initialized a channel independently.
+func resumeChannel(ctx context.Context, pathName string, src, dst *ProvableChain) error {
+	for _, pair := range [][2]*ProvableChain{{src, dst}, {dst, src}} {
+		chain, cp := pair[0], pair[1]
+		if chain.Path().ChannelID != "" {
+			continue
+		}
+		channelID, err := findChannel(ctx, chain, cp)
+		if err != nil {
+			return err
+		} else if channelID == "" {
+			continue
+		}
+		GetChainLogger(chain).Info("resume the existing channel", "channel_id", channelID)
+		if err := config.UpdatePathConfig(pathName, chain.ChainID(), map[PathConfigKey]string{PathConfigChannelID: channelID}); err != nil {
+			return err
+		}
+	}
+
+	srcCtx, err := latestQueryContext(ctx, src)
+	if err != nil {
+		return err
+	}
+	dstCtx, err := latestQueryContext(ctx, dst)
+	if err != nil {
+		return err
+	}
+	srcChan, dstChan, err := QueryChannelPair(srcCtx, dstCtx, src, dst, false)
+	if err != nil {
+		return err
+	}
+	// the handshakes initialized by different relayers can't be merged, so the one on src is continued
+	if srcChan.Channel.State == chantypes.INIT && dstChan.Channel.State == chantypes.INIT {
+		GetChannelPairLogger(src, dst).Warn("both chains have initialized a channel; the one on dst is abandoned",
+			"abandoned_channel_id", dst.Path().ChannelID)
+		return config.UpdatePathConfig(pathName, dst.ChainID(), map[PathConfigKey]string{PathConfigChannelID: ""})
+	}
+	return nil
+}
+
+// findChannel returns the most advanced channel on `chain` over the connection of the path that can be paired with the channel on `cp`
+func findChannel(ctx context.Context, chain, cp *ProvableChain) (string, error) {
+	if chain.Path().ConnectionID == "" {
+		return "", nil
+	}
+	// the counterparty channel knows its peer once the handshake has proceeded
+	if cp.Path().ChannelID != "" {
+		cpCtx, err := latestQueryContext(ctx, cp)
+		if err != nil {
+			return "", err
+		}
+		res, err := cp.QueryChannel(cpCtx)
+		if err != nil {
+			return "", err
+		} else if res.Channel.Counterparty.ChannelId != "" {
+			return res.Channel.Counterparty.ChannelId, nil
+		}
+	}
+
+	querier, ok := chain.Chain.(LinkQuerier)
+	if !ok {
+		return "", nil
+	}
+	queryCtx, err := latestQueryContext(ctx, chain)
+	if err != nil {
+		return "", err
+	}
+	channels, err := querier.QueryConnectionChannels(queryCtx, chain.Path().ConnectionID)
+	if err != nil {
+		return "", err
+	}
+	var (
+		found string
+		rank  int
+	)
+	for _, c := range channels {
+		if c.PortId != chain.Path().PortID ||
+			c.Counterparty.PortId != cp.Path().PortID ||
+			(cp.Path().ChannelID != "" && c.Counterparty.ChannelId != "" && c.Counterparty.ChannelId != cp.Path().ChannelID) {
+			continue
+		}
+		if r := channelStateRank(c.State); r > 0 && r >= rank {
+			found, rank = c.ChannelId, r
+		}
+	}
+	return found, nil
+}
+
+func channelStateRank(state chantypes.State) int {
+	switch state {
+	case chantypes.INIT:
+		return 1
+	case chantypes.TRYOPEN:
+		return 2
+	case chantypes.OPEN, chantypes.FLUSHING, chantypes.FLUSHCOMPLETE:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func latestQueryContext(ctx context.Context, chain *ProvableChain) (QueryContext, error) {
+	height, err := chain.LatestHeight(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest height of chain %s: %v", chain.ChainID(), err)
+	}
+	return NewQueryContext(ctx, height), nil
+}
@@ -0,0 +1,323 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+// linkChain is a stateChain that has multiple clients, connections and channels listed by LinkQuerier
+type linkChain struct {
+	*stateChain
+	clients     clienttypes.IdentifiedClientStates
+	statuses    map[string]ibcexported.Status
+	connections map[string]*conntypes.ConnectionEnd
+	channels    []*chantypes.IdentifiedChannel
+}
+
+func newLinkChain(c *stateChain) *linkChain {
+	return &linkChain{
+		stateChain:  c,
+		statuses:    make(map[string]ibcexported.Status),
+		connections: make(map[string]*conntypes.ConnectionEnd),
+	}
+}
+
+// addClient adds an Active client tracking `chainID` at `height` unless `status` is given
+func (c *linkChain) addClient(t *testing.T, clientID, chainID string, height uint64, status ibcexported.Status) {
+	anyCs, err := clienttypes.PackClientState(&tmclient.ClientState{ChainId: chainID, LatestHeight: clienttypes.NewHeight(0, height)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.clients = append(c.clients, clienttypes.IdentifiedClientState{ClientId: clientID, ClientState: anyCs})
+	if status == "" {
+		status = ibcexported.Active
+	}
+	c.statuses[clientID] = status
+}
+
+func (c *linkChain) addConnection(connectionID, clientID string, state conntypes.State, cpClientID, cpConnectionID string) {
+	c.connections[connectionID] = &conntypes.ConnectionEnd{
+		ClientId:     clientID,
+		State:        state,
+		Counterparty: conntypes.NewCounterparty(cpClientID, cpConnectionID, commitmenttypes.NewMerklePrefix([]byte("ibc"))),
+	}
+}
+
+func (c *linkChain) addChannel(channelID, connectionID string, state chantypes.State, cpPortID, cpChannelID string) {
+	c.channels = append(c.channels, &chantypes.IdentifiedChannel{
+		State:          state,
+		Ordering:       chantypes.UNORDERED,
+		Counterparty:   chantypes.NewCounterparty(cpPortID, cpChannelID),
+		ConnectionHops: []string{connectionID},
+		Version:        "ics20-1",
+		PortId:         "transfer",
+		ChannelId:      channelID,
+	})
+}
+
+func (c *linkChain) QueryConnection(ctx QueryContext, connectionID string) (*conntypes.QueryConnectionResponse, error) {
+	conn, ok := c.connections[connectionID]
+	if !ok {
+		conn = &conntypes.ConnectionEnd{State: conntypes.UNINITIALIZED}
+	}
+	return &conntypes.QueryConnectionResponse{Connection: conn}, nil
+}
+
+func (c *linkChain) QueryChannel(ctx QueryContext) (*chantypes.QueryChannelResponse, error) {
+	for _, ch := range c.channels {
+		if ch.PortId == c.path.PortID && ch.ChannelId == c.path.ChannelID {
+			return &chantypes.QueryChannelResponse{Channel: &chantypes.Channel{
+				State:          ch.State,
+				Ordering:       ch.Ordering,
+				Counterparty:   ch.Counterparty,
+				ConnectionHops: ch.ConnectionHops,
+				Version:        ch.Version,
+			}}, nil
+		}
+	}
+	return &chantypes.QueryChannelResponse{Channel: &chantypes.Channel{State: chantypes.UNINITIALIZED}}, nil
+}
+
+func (c *linkChain) QueryClientStates(ctx QueryContext) (clienttypes.IdentifiedClientStates, error) {
+	return c.clients, nil
+}
+
+func (c *linkChain) QueryClientStatusByID(ctx QueryContext, clientID string) (ibcexported.Status, error) {
+	return c.statuses[clientID], nil
+}
+
+func (c *linkChain) QueryClientConnections(ctx QueryContext, clientID string) ([]string, error) {
+	var ids []string
+	for id, conn := range c.connections {
+		if conn.ClientId == clientID {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+func (c *linkChain) QueryConnectionChannels(ctx QueryContext, connectionID string) ([]*chantypes.IdentifiedChannel, error) {
+	var channels []*chantypes.IdentifiedChannel
+	for _, ch := range c.channels {
+		if ch.ConnectionHops[0] == connectionID {
+			channels = append(channels, ch)
+		}
+	}
+	return channels, nil
+}
+
+// linkProver is a stateProver that creates a client state tracking `chainID`
+type linkProver struct {
+	stateProver
+	chainID string
+}
+
+func (p linkProver) CreateInitialLightClientState(ctx context.Context, height ibcexported.Height) (ibcexported.ClientState, ibcexported.ConsensusState, error) {
+	return &tmclient.ClientState{ChainId: p.chainID, LatestHeight: clienttypes.NewHeight(0, 100)}, &tmclient.ConsensusState{}, nil
+}
+
+// newLinkChainPair returns a pair of linkChains with nothing in the path config except the chain and port IDs
+func newLinkChainPair(t *testing.T) (src, dst *linkChain, srcPC, dstPC *ProvableChain) {
+	s, d := newStateChainPair()
+	src, dst = newLinkChain(s), newLinkChain(d)
+	for _, c := range []*linkChain{src, dst} {
+		c.path.ClientID, c.path.ConnectionID, c.path.ChannelID = "", "", ""
+	}
+	setTestConfig(t, src.path, dst.path)
+	return src, dst, NewProvableChain(src, linkProver{chainID: src.ChainID()}), NewProvableChain(dst, linkProver{chainID: dst.ChainID()})
+}
+
+func TestReuseClient(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name     string
+		clientID string
+		setup    func(t *testing.T, c *linkChain)
+		expected string
+	}{
+		{"client already set", "07-tendermint-9", func(t *testing.T, c *linkChain) {
+			c.addClient(t, "07-tendermint-0", "ibc1", 100, "")
+		}, "07-tendermint-9"},
+		{"no client", "", func(t *testing.T, c *linkChain) {}, ""},
+		{"most advanced active client", "", func(t *testing.T, c *linkChain) {
+			c.addClient(t, "07-tendermint-0", "ibc1", 50, "")
+			c.addClient(t, "07-tendermint-1", "ibc1", 80, "")
+			c.addClient(t, "07-tendermint-2", "ibc1", 90, ibcexported.Expired)
+			c.addClient(t, "07-tendermint-3", "ibc2", 95, "")
+		}, "07-tendermint-1"},
+		{"client of another chain", "", func(t *testing.T, c *linkChain) {
+			c.addClient(t, "07-tendermint-0", "ibc2", 100, "")
+		}, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src, _, srcPC, dstPC := newLinkChainPair(t)
+			src.path.ClientID = c.clientID
+			c.setup(t, src)
+			if err := reuseClient(context.TODO(), "path", srcPC, dstPC); err != nil {
+				t.Fatal(err)
+			}
+			if src.path.ClientID != c.expected {
+				t.Errorf("unexpected client ID: actual=%s, expected=%s", src.path.ClientID, c.expected)
+			}
+		})
+	}
+}
+
+func TestReuseClientWithoutQuerier(t *testing.T) {
+	src, dst := newStateChainPair()
+	src.path.ClientID = ""
+	setTestConfig(t, src.path, dst.path)
+	if err := reuseClient(context.TODO(), "path", newTestProvableChain(src), NewProvableChain(dst, linkProver{chainID: dst.ChainID()})); err == nil {
+		t.Error("expected an error for the chain without LinkQuerier")
+	}
+}
+
+func TestResumeConnection(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name        string
+		setup       func(src, dst *linkChain)
+		expectedSrc string
+		expectedDst string
+	}{
+		{"nothing to resume", func(src, dst *linkChain) {}, "", ""},
+		{"open on both", func(src, dst *linkChain) {
+			src.addConnection("connection-0", "07-tendermint-0", conntypes.OPEN, "07-tendermint-1", "connection-1")
+			dst.addConnection("connection-1", "07-tendermint-1", conntypes.OPEN, "07-tendermint-0", "connection-0")
+		}, "connection-0", "connection-1"},
+		{"most advanced", func(src, dst *linkChain) {
+			src.addConnection("connection-0", "07-tendermint-0", conntypes.INIT, "07-tendermint-1", "")
+			src.addConnection("connection-2", "07-tendermint-0", conntypes.INIT, "07-tendermint-1", "")
+			src.addConnection("connection-3", "07-tendermint-0", conntypes.TRYOPEN, "07-tendermint-1", "connection-1")
+			dst.addConnection("connection-1", "07-tendermint-1", conntypes.INIT, "07-tendermint-0", "")
+		}, "connection-3", "connection-1"},
+		{"counterparty client mismatch", func(src, dst *linkChain) {
+			src.addConnection("connection-0", "07-tendermint-0", conntypes.INIT, "07-tendermint-5", "")
+		}, "", ""},
+		{"crossing INIT", func(src, dst *linkChain) {
+			src.addConnection("connection-0", "07-tendermint-0", conntypes.INIT, "07-tendermint-1", "")
+			dst.addConnection("connection-1", "07-tendermint-1", conntypes.INIT, "07-tendermint-0", "")
+		}, "connection-0", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src, dst, srcPC, dstPC := newLinkChainPair(t)
+			src.path.ClientID, dst.path.ClientID = "07-tendermint-0", "07-tendermint-1"
+			c.setup(src, dst)
+			if err := resumeConnection(context.TODO(), "path", srcPC, dstPC); err != nil {
+				t.Fatal(err)
+			}
+			if src.path.ConnectionID != c.expectedSrc || dst.path.ConnectionID != c.expectedDst {
+				t.Errorf("unexpected connections: actual=(%s, %s), expected=(%s, %s)",
+					src.path.ConnectionID, dst.path.ConnectionID, c.expectedSrc, c.expectedDst)
+			}
+		})
+	}
+}
+
+func TestResumeChannel(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name        string
+		setup       func(src, dst *linkChain)
+		expectedSrc string
+		expectedDst string
+	}{
+		{"nothing to resume", func(src, dst *linkChain) {}, "", ""},
+		{"open on both", func(src, dst *linkChain) {
+			src.addChannel("channel-0", "connection-0", chantypes.OPEN, "transfer", "channel-1")
+			dst.addChannel("channel-1", "connection-1", chantypes.OPEN, "transfer", "channel-0")
+		}, "channel-0", "channel-1"},
+		{"most advanced", func(src, dst *linkChain) {
+			src.addChannel("channel-0", "connection-0", chantypes.INIT, "transfer", "")
+			src.addChannel("channel-2", "connection-0", chantypes.TRYOPEN, "transfer", "channel-1")
+			dst.addChannel("channel-1", "connection-1", chantypes.INIT, "transfer", "")
+		}, "channel-2", "channel-1"},
+		{"counterparty port mismatch", func(src, dst *linkChain) {
+			src.addChannel("channel-0", "connection-0", chantypes.INIT, "ica", "")
+		}, "", ""},
+		{"other connection", func(src, dst *linkChain) {
+			src.addChannel("channel-0", "connection-5", chantypes.INIT, "transfer", "")
+		}, "", ""},
+		{"crossing INIT", func(src, dst *linkChain) {
+			src.addChannel("channel-0", "connection-0", chantypes.INIT, "transfer", "")
+			dst.addChannel("channel-1", "connection-1", chantypes.INIT, "transfer", "")
+		}, "channel-0", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src, dst, srcPC, dstPC := newLinkChainPair(t)
+			src.path.ClientID, dst.path.ClientID = "07-tendermint-0", "07-tendermint-1"
+			src.path.ConnectionID, dst.path.ConnectionID = "connection-0", "connection-1"
+			c.setup(src, dst)
+			if err := resumeChannel(context.TODO(), "path", srcPC, dstPC); err != nil {
+				t.Fatal(err)
+			}
+			if src.path.ChannelID != c.expectedSrc || dst.path.ChannelID != c.expectedDst {
+				t.Errorf("unexpected channels: actual=(%s, %s), expected=(%s, %s)",
+					src.path.ChannelID, dst.path.ChannelID, c.expectedSrc, c.expectedDst)
+			}
+		})
+	}
+}
+
+// TestLinkResume checks that Link resumes the path linked before without sending any msg
+func TestLinkResume(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	src, dst, srcPC, dstPC := newLinkChainPair(t)
+	src.addClient(t, "07-tendermint-0", "ibc1", 90, "")
+	dst.addClient(t, "07-tendermint-1", "ibc0", 90, "")
+	src.addConnection("connection-0", "07-tendermint-0", conntypes.OPEN, "07-tendermint-1", "connection-1")
+	dst.addConnection("connection-1", "07-tendermint-1", conntypes.OPEN, "07-tendermint-0", "connection-0")
+	src.addChannel("channel-0", "connection-0", chantypes.OPEN, "transfer", "channel-1")
+	dst.addChannel("channel-1", "connection-1", chantypes.OPEN, "transfer", "channel-0")
+
+	if err := Link(context.TODO(), "path", srcPC, dstPC, time.Millisecond, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		chain    *linkChain
+		expected PathEnd
+	}{
+		{src, PathEnd{ClientID: "07-tendermint-0", ConnectionID: "connection-0", ChannelID: "channel-0"}},
+		{dst, PathEnd{ClientID: "07-tendermint-1", ConnectionID: "connection-1", ChannelID: "channel-1"}},
+	} {
+		pe := c.chain.path
+		if pe.ClientID != c.expected.ClientID || pe.ConnectionID != c.expected.ConnectionID || pe.ChannelID != c.expected.ChannelID {
+			t.Errorf("unexpected path end of %s: %+v", c.chain.ChainID(), pe)
+		}
+		if len(c.chain.sent) != 0 {
+			t.Errorf("unexpected msgs sent to %s: %v", c.chain.ChainID(), c.chain.sent)
+		}
+	}
+}
+
+func TestLinkReuseWithoutQuerier(t *testing.T) {
+	src, dst := newStateChainPair()
+	src.path.ClientID = ""
+	setTestConfig(t, src.path, dst.path)
+	srcPC, dstPC := NewProvableChain(src, linkProver{chainID: "ibc0"}), NewProvableChain(dst, linkProver{chainID: "ibc1"})
+	if err := Link(context.TODO(), "path", srcPC, dstPC, time.Millisecond, true); err == nil {
+		t.Error("expected an error for the chain without LinkQuerier")
+	}
+}
@@ -1,8 +1,10 @@
 package core
 
 import (
+	"errors"
 	"time"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/gogoproto/proto"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 )
@@ -131,3 +133,50 @@ type EventUnknown struct {
 
 	Value any
 }
+
+// TxFailureReason represents the stage at which a tx sent by `Chain::SendMsgs` failed
+type TxFailureReason string
+
+const (
+	TxFailureReasonCheckTx       TxFailureReason = "check_tx"
+	TxFailureReasonDeliverTx     TxFailureReason = "deliver_tx"
+	TxFailureReasonCommitTimeout TxFailureReason = "commit_timeout"
+	TxFailureReasonRPC           TxFailureReason = "rpc_error"
+	TxFailureReasonCanceled      TxFailureReason = "canceled"
+	TxFailureReasonUnknown       TxFailureReason = "unknown"
+)
+
+// TxCost is the gas used and the fees paid by a tx
+type TxCost struct {
+	GasUsed  uint64
+	FeesPaid sdk.Coins
+}
+
+// TxError is an error returned by `Chain::SendMsgs` that provides the reason why the tx failed.
+type TxError struct {
+	Reason TxFailureReason
+	Err    error
+}
+
+// NewTxError returns a new TxError that wraps `err`
+func NewTxError(reason TxFailureReason, err error) *TxError {
+	return &TxError{Reason: reason, Err: err}
+}
+
+func (e *TxError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TxError) Unwrap() error {
+	return e.Err
+}
+
+// GetTxFailureReason returns the reason of the TxError contained in `err`.
+// If `err` contains no TxError, TxFailureReasonUnknown is returned.
+func GetTxFailureReason(err error) TxFailureReason {
+	var txErr *TxError
+	if errors.As(err, &txErr) {
+		return txErr.Reason
+	}
+	return TxFailureReasonUnknown
+}
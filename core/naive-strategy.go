@@ -1,15 +1,19 @@
 package core
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	retry "github.com/avast/retry-go"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
 	"github.com/hyperledger-labs/yui-relayer/metrics"
 	"go.opentelemetry.io/otel/attribute"
 	api "go.opentelemetry.io/otel/metric"
@@ -19,11 +23,19 @@ import (
 // NaiveStrategy is an implementation of Strategy.
 type NaiveStrategy struct {
 	Ordered      bool
-	MaxTxSize    uint64 // maximum permitted size of the msgs in a bundled relay transaction
-	MaxMsgLength uint64 // maximum amount of messages in a bundled relay transaction
+	DelayPeriod  time.Duration // delay period of the connection, during which proofs are not accepted after the client update
+	MaxTxSize    uint64        // maximum permitted size of the msgs in a bundled relay transaction
+	MaxMsgLength uint64        // maximum amount of messages in a bundled relay transaction
+	MaxGas       uint64        // maximum gas estimated for a bundled relay transaction
+	FeePolicy    string        // how packets with escrowed ICS-29 fees are treated ("", "prioritize" or "only")
 	srcNoAck     bool
 	dstNoAck     bool
 
+	// the heights of the existing consensus states of the clients on src and dst that UpdateClients reused instead of updating the clients,
+	// at which the proofs are built in the same relay cycle
+	srcReusedClientHeight ibcexported.Height
+	dstReusedClientHeight ibcexported.Height
+
 	metrics naiveStrategyMetrics
 }
 
@@ -48,6 +60,13 @@ func (st *NaiveStrategy) GetType() string {
 
 func (st *NaiveStrategy) SetupRelay(ctx context.Context, src, dst *ProvableChain) error {
 	logger := GetChannelPairLogger(src, dst)
+	st.Ordered = src.Path().GetOrder() == chantypes.ORDERED
+	if delayPeriod, err := queryDelayPeriod(ctx, src); err != nil {
+		logger.Error("failed to determine the delay period of the connection", err)
+		return fmt.Errorf("failed to determine the delay period of the connection %s: %v", src.Path().ConnectionID, err)
+	} else {
+		st.DelayPeriod = delayPeriod
+	}
 	if err := src.SetupForRelay(ctx); err != nil {
 		logger.Error(
 			"failed to setup for src",
@@ -78,7 +97,7 @@ func getQueryContext(ctx context.Context, chain *ProvableChain, sh SyncHeaders,
 }
 
 func (st *NaiveStrategy) UnrelayedPackets(ctx context.Context, src, dst *ProvableChain, sh SyncHeaders, includeRelayedButUnfinalized bool) (*RelayPackets, error) {
-	logger := GetChannelPairLogger(src, dst)
+	logger := GetChannelPairLogger(src, dst).WithSpanContext(ctx)
 	now := time.Now()
 	var (
 		eg         = new(errgroup.Group)
@@ -200,7 +219,7 @@ func (st *NaiveStrategy) UnrelayedPackets(ctx context.Context, src, dst *Provabl
 }
 
 func (st *NaiveStrategy) RelayPackets(ctx context.Context, src, dst *ProvableChain, rp *RelayPackets, sh SyncHeaders, doExecuteRelaySrc, doExecuteRelayDst bool) (*RelayMsgs, error) {
-	logger := GetChannelPairLogger(src, dst)
+	logger := GetChannelPairLogger(src, dst).WithSpanContext(ctx)
 	defer logger.TimeTrack(time.Now(), "RelayPackets", "num_src", len(rp.Src), "num_dst", len(rp.Dst))
 
 	msgs := NewRelayMsgs()
@@ -225,8 +244,36 @@ func (st *NaiveStrategy) RelayPackets(ctx context.Context, src, dst *ProvableCha
 		return nil, err
 	}
 
+	// on ORDERED channels, a timed-out packet is not received but timed out on its sending chain, which closes the channel
+	var srcTimeoutMsgs, dstTimeoutMsgs []sdk.Msg
+
 	if doExecuteRelayDst {
-		msgs.Dst, err = collectPackets(srcCtx, src, rp.Src, dstAddress)
+		packets, queryCtx := rp.Src, reusedClientQueryContext(ctx, srcCtx, st.dstReusedClientHeight, src, dst)
+		if st.Ordered {
+			packets, srcTimeoutMsgs, err = collectOrderedPackets(ctx, src, dst, packets, sh, srcAddress)
+			if err != nil {
+				logger.Error(
+					"error collecting ordered packets",
+					err,
+				)
+				return nil, err
+			}
+		}
+		if packets, queryCtx, err = st.delayedPackets(ctx, queryCtx, src, dst, packets); err != nil {
+			logger.Error(
+				"error collecting packets past the delay period",
+				err,
+			)
+			return nil, err
+		}
+		if packets, err = st.incentivizedPackets(queryCtx, src, packets); err != nil {
+			logger.Error(
+				"error collecting incentivized packets",
+				err,
+			)
+			return nil, err
+		}
+		msgs.Dst, err = collectPackets(queryCtx, src, packets, dstAddress)
 		if err != nil {
 			logger.Error(
 				"error collecting packets",
@@ -237,7 +284,32 @@ func (st *NaiveStrategy) RelayPackets(ctx context.Context, src, dst *ProvableCha
 	}
 
 	if doExecuteRelaySrc {
-		msgs.Src, err = collectPackets(dstCtx, dst, rp.Dst, srcAddress)
+		packets, queryCtx := rp.Dst, reusedClientQueryContext(ctx, dstCtx, st.srcReusedClientHeight, dst, src)
+		if st.Ordered {
+			packets, dstTimeoutMsgs, err = collectOrderedPackets(ctx, dst, src, packets, sh, dstAddress)
+			if err != nil {
+				logger.Error(
+					"error collecting ordered packets",
+					err,
+				)
+				return nil, err
+			}
+		}
+		if packets, queryCtx, err = st.delayedPackets(ctx, queryCtx, dst, src, packets); err != nil {
+			logger.Error(
+				"error collecting packets past the delay period",
+				err,
+			)
+			return nil, err
+		}
+		if packets, err = st.incentivizedPackets(queryCtx, dst, packets); err != nil {
+			logger.Error(
+				"error collecting incentivized packets",
+				err,
+			)
+			return nil, err
+		}
+		msgs.Src, err = collectPackets(queryCtx, dst, packets, srcAddress)
 		if err != nil {
 			logger.Error(
 				"error collecting packets",
@@ -247,6 +319,9 @@ func (st *NaiveStrategy) RelayPackets(ctx context.Context, src, dst *ProvableCha
 		}
 	}
 
+	msgs.Src = append(msgs.Src, srcTimeoutMsgs...)
+	msgs.Dst = append(msgs.Dst, dstTimeoutMsgs...)
+
 	if len(msgs.Dst) == 0 && len(msgs.Src) == 0 {
 		logger.Info("no packates to relay")
 	} else {
@@ -262,7 +337,7 @@ func (st *NaiveStrategy) RelayPackets(ctx context.Context, src, dst *ProvableCha
 }
 
 func (st *NaiveStrategy) UnrelayedAcknowledgements(ctx context.Context, src, dst *ProvableChain, sh SyncHeaders, includeRelayedButUnfinalized bool) (*RelayPackets, error) {
-	logger := GetChannelPairLogger(src, dst)
+	logger := GetChannelPairLogger(src, dst).WithSpanContext(ctx)
 	now := time.Now()
 	var (
 		eg      = new(errgroup.Group)
@@ -391,7 +466,7 @@ func (st *NaiveStrategy) UnrelayedAcknowledgements(ctx context.Context, src, dst
 
 // TODO add packet-timeout support
 func collectPackets(ctx QueryContext, chain *ProvableChain, packets PacketInfoList, signer sdk.AccAddress) ([]sdk.Msg, error) {
-	logger := GetChannelLogger(chain)
+	logger := GetChannelLogger(chain).WithSpanContext(ctx.Context())
 	var msgs []sdk.Msg
 	for _, p := range packets {
 		commitment := chantypes.CommitPacket(chain.Codec(), &p.Packet)
@@ -411,6 +486,157 @@ func collectPackets(ctx QueryContext, chain *ProvableChain, packets PacketInfoLi
 	return msgs, nil
 }
 
+// NextSequenceReceiveQuerier is an optional interface of Chain that queries the next sequence to be received on an ORDERED channel.
+// If the receiving chain doesn't implement it, the packets on the ORDERED channel are relayed as they are.
+type NextSequenceReceiveQuerier interface {
+	// QueryNextSequenceReceive returns the next sequence to be received on the channel of the path end
+	QueryNextSequenceReceive(ctx QueryContext) (*chantypes.QueryNextSequenceReceiveResponse, error)
+}
+
+// collectOrderedPackets returns the contiguous run of `packets` sent on `sender` that starts from the next sequence to be received on `receiver`.
+// The run stops at the first gap of sequences because any later packet cannot be received on the ORDERED channel.
+// If the first packet of the run has timed out, the run is empty and MsgTimeout of the packet to be submitted to `sender` is returned instead.
+func collectOrderedPackets(ctx context.Context, sender, receiver *ProvableChain, packets PacketInfoList, sh SyncHeaders, signer sdk.AccAddress) (PacketInfoList, []sdk.Msg, error) {
+	logger := GetChannelPairLogger(sender, receiver).WithSpanContext(ctx)
+	if len(packets) == 0 {
+		return packets, nil, nil
+	}
+	querier, ok := receiver.Chain.(NextSequenceReceiveQuerier)
+	if !ok {
+		return packets, nil, nil
+	}
+
+	receiverCtx, err := getQueryContext(ctx, receiver, sh, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := querier.QueryNextSequenceReceive(receiverCtx)
+	if err != nil {
+		return nil, nil, err
+	}
+	receiverTimestamp, err := receiver.Timestamp(ctx, receiverCtx.Height())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sorted := make(PacketInfoList, len(packets))
+	copy(sorted, packets)
+	slices.SortFunc(sorted, func(a, b *PacketInfo) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+
+	var run PacketInfoList
+	nextSeq := res.NextSequenceReceive
+	for _, p := range sorted {
+		if p.Sequence < nextSeq {
+			continue
+		} else if p.Sequence > nextSeq {
+			logger.Info("stop collecting packets at the gap of sequences", "expected_sequence", nextSeq, "sequence", p.Sequence)
+			break
+		}
+		if packetTimedOut(p.Packet, receiverCtx.Height(), receiverTimestamp) {
+			if len(run) > 0 {
+				// the timed-out packet is handled after the preceding packets are received
+				break
+			}
+			msg, err := timeoutOrderedPacket(ctx, sender, receiver, querier, p, sh, signer)
+			if err != nil {
+				return nil, nil, err
+			} else if msg == nil {
+				return nil, nil, nil
+			}
+			logger.Warn("packet has timed out on the ORDERED channel, which will be closed by MsgTimeout", "sequence", p.Sequence)
+			return nil, []sdk.Msg{msg}, nil
+		}
+		run = append(run, p)
+		nextSeq++
+	}
+	return run, nil, nil
+}
+
+// timeoutOrderedPacket returns MsgTimeout of `packet` to be submitted to `sender`.
+// The proof of the next sequence to be received is taken at the latest height of the client on `sender`,
+// so nil is returned if the packet has not timed out at that height yet.
+func timeoutOrderedPacket(ctx context.Context, sender, receiver *ProvableChain, querier NextSequenceReceiveQuerier, packet *PacketInfo, sh SyncHeaders, signer sdk.AccAddress) (sdk.Msg, error) {
+	logger := GetChannelPairLogger(sender, receiver).WithSpanContext(ctx)
+
+	csRes, err := sender.QueryClientState(sh.GetQueryContext(ctx, sender.ChainID()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the client state: %v", err)
+	}
+	cs, err := clienttypes.UnpackClientState(csRes.ClientState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack the client state: %v", err)
+	}
+	height := cs.GetLatestHeight()
+	timestamp, err := receiver.Timestamp(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	if !packetTimedOut(packet.Packet, height, timestamp) {
+		logger.Info("wait for the client to be updated to prove the timeout of the packet", "sequence", packet.Sequence, "client_height", height)
+		return nil, nil
+	}
+
+	queryCtx := NewQueryContext(ctx, height)
+	res, err := querier.QueryNextSequenceReceive(queryCtx)
+	if err != nil {
+		return nil, err
+	} else if res.NextSequenceReceive != packet.Sequence {
+		logger.Info("next sequence receive at the client height doesn't match the packet", "sequence", packet.Sequence, "next_sequence_receive", res.NextSequenceReceive)
+		return nil, nil
+	}
+	path := host.NextSequenceRecvPath(packet.DestinationPort, packet.DestinationChannel)
+	proof, proofHeight, err := receiver.ProveState(queryCtx, path, sdk.Uint64ToBigEndian(res.NextSequenceReceive))
+	if err != nil {
+		logger.Error("failed to ProveState", err,
+			"height", height,
+			"path", path,
+		)
+		return nil, err
+	}
+	return chantypes.NewMsgTimeout(packet.Packet, res.NextSequenceReceive, proof, proofHeight, signer.String()), nil
+}
+
+// delayedPackets returns the packets committed on `sender` that can be proven at the latest height of the consensus states of the client on `receiver`
+// for which the delay period has passed, and the query context at that height. The other packets are relayed in a later cycle
+// after the client is updated to their heights and the delay period passes. If the connection has no delay period, it returns the packets and `queryCtx` as they are.
+func (st *NaiveStrategy) delayedPackets(ctx context.Context, queryCtx QueryContext, sender, receiver *ProvableChain, packets PacketInfoList) (PacketInfoList, QueryContext, error) {
+	if len(packets) == 0 || st.DelayPeriod == 0 {
+		return packets, queryCtx, nil
+	}
+	clientHeight, passed, err := queryMaturedClientHeight(ctx, receiver, st.DelayPeriod)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ret PacketInfoList
+	if passed {
+		for _, p := range packets {
+			if p.EventHeight.LT(clientHeight) {
+				ret = append(ret, p)
+			}
+		}
+	}
+	if len(ret) < len(packets) {
+		GetChannelPairLogger(sender, receiver).WithSpanContext(ctx).Info("waiting for the delay period to pass",
+			"num_waiting", len(packets)-len(ret),
+			"delay_period", st.DelayPeriod.String(),
+		)
+	}
+	if len(ret) == 0 {
+		return nil, queryCtx, nil
+	}
+	return ret, NewQueryContext(ctx, clientHeight), nil
+}
+
+// packetTimedOut returns true if `packet` can no longer be received on the chain at `height` and `timestamp`
+func packetTimedOut(packet chantypes.Packet, height ibcexported.Height, timestamp time.Time) bool {
+	if !packet.TimeoutHeight.IsZero() && height.GTE(packet.TimeoutHeight) {
+		return true
+	}
+	return packet.TimeoutTimestamp != 0 && uint64(timestamp.UnixNano()) >= packet.TimeoutTimestamp
+}
+
 func logPacketsRelayed(src, dst Chain, num int, obj string, dir string) {
 	logger := GetChannelPairLogger(src, dst)
 	logger.Info(
@@ -421,7 +647,7 @@ func logPacketsRelayed(src, dst Chain, num int, obj string, dir string) {
 }
 
 func (st *NaiveStrategy) RelayAcknowledgements(ctx context.Context, src, dst *ProvableChain, rp *RelayPackets, sh SyncHeaders, doExecuteAckSrc, doExecuteAckDst bool) (*RelayMsgs, error) {
-	logger := GetChannelPairLogger(src, dst)
+	logger := GetChannelPairLogger(src, dst).WithSpanContext(ctx)
 	defer logger.TimeTrack(time.Now(), "RelayAcknowledgements", "num_src", len(rp.Src), "num_dst", len(rp.Dst))
 
 	msgs := NewRelayMsgs()
@@ -446,13 +672,27 @@ func (st *NaiveStrategy) RelayAcknowledgements(ctx context.Context, src, dst *Pr
 	}
 
 	if !st.dstNoAck && doExecuteAckDst {
-		msgs.Dst, err = collectAcks(srcCtx, src, rp.Src, dstAddress)
+		packets, queryCtx, err := st.delayedPackets(ctx, reusedClientQueryContext(ctx, srcCtx, st.dstReusedClientHeight, src, dst), src, dst, rp.Src)
+		if err != nil {
+			return nil, err
+		}
+		if packets, err = st.incentivizedPackets(dstCtx, dst, packets); err != nil {
+			return nil, err
+		}
+		msgs.Dst, err = collectAcks(queryCtx, src, packets, dstAddress)
 		if err != nil {
 			return nil, err
 		}
 	}
 	if !st.srcNoAck && doExecuteAckSrc {
-		msgs.Src, err = collectAcks(dstCtx, dst, rp.Dst, srcAddress)
+		packets, queryCtx, err := st.delayedPackets(ctx, reusedClientQueryContext(ctx, dstCtx, st.srcReusedClientHeight, dst, src), dst, src, rp.Dst)
+		if err != nil {
+			return nil, err
+		}
+		if packets, err = st.incentivizedPackets(srcCtx, src, packets); err != nil {
+			return nil, err
+		}
+		msgs.Src, err = collectAcks(queryCtx, dst, packets, srcAddress)
 		if err != nil {
 			return nil, err
 		}
@@ -473,7 +713,7 @@ func (st *NaiveStrategy) RelayAcknowledgements(ctx context.Context, src, dst *Pr
 }
 
 func collectAcks(ctx QueryContext, chain *ProvableChain, packets PacketInfoList, signer sdk.AccAddress) ([]sdk.Msg, error) {
-	logger := GetChannelLogger(chain)
+	logger := GetChannelLogger(chain).WithSpanContext(ctx.Context())
 	var msgs []sdk.Msg
 
 	for _, p := range packets {
@@ -496,7 +736,7 @@ func collectAcks(ctx QueryContext, chain *ProvableChain, packets PacketInfoList,
 }
 
 func (st *NaiveStrategy) UpdateClients(ctx context.Context, src, dst *ProvableChain, doExecuteRelaySrc, doExecuteRelayDst, doExecuteAckSrc, doExecuteAckDst bool, sh SyncHeaders, doRefresh bool) (*RelayMsgs, error) {
-	logger := GetChannelPairLogger(src, dst)
+	logger := GetChannelPairLogger(src, dst).WithSpanContext(ctx)
 
 	msgs := NewRelayMsgs()
 
@@ -519,6 +759,28 @@ func (st *NaiveStrategy) UpdateClients(ctx context.Context, src, dst *ProvableCh
 		}
 	}
 
+	// the client already updated to the latest finalized height or above (e.g. by another relayer) is reused without being updated,
+	// and the proofs are built at the height of its existing consensus state by RelayPackets and RelayAcknowledgements
+	st.srcReusedClientHeight, st.dstReusedClientHeight = nil, nil
+	if needsUpdateForSrc {
+		if h, ok, err := queryReusableClientHeight(ctx, sh.GetQueryContext(ctx, dst.ChainID()), src); err != nil {
+			return nil, fmt.Errorf("failed to check if the LC on the src chain can be reused: %v", err)
+		} else if ok {
+			logger.Info("skip updating the client on src chain with an existing consensus state", "client_height", h)
+			needsUpdateForSrc = false
+			st.srcReusedClientHeight = h
+		}
+	}
+	if needsUpdateForDst {
+		if h, ok, err := queryReusableClientHeight(ctx, sh.GetQueryContext(ctx, src.ChainID()), dst); err != nil {
+			return nil, fmt.Errorf("failed to check if the LC on the dst chain can be reused: %v", err)
+		} else if ok {
+			logger.Info("skip updating the client on dst chain with an existing consensus state", "client_height", h)
+			needsUpdateForDst = false
+			st.dstReusedClientHeight = h
+		}
+	}
+
 	if needsUpdateForSrc {
 		srcAddress, err := src.GetAddress()
 		if err != nil {
@@ -558,10 +820,11 @@ func (st *NaiveStrategy) UpdateClients(ctx context.Context, src, dst *ProvableCh
 }
 
 func (st *NaiveStrategy) Send(ctx context.Context, src, dst Chain, msgs *RelayMsgs) {
-	logger := GetChannelPairLogger(src, dst)
+	logger := GetChannelPairLogger(src, dst).WithSpanContext(ctx)
 
 	msgs.MaxTxSize = st.MaxTxSize
 	msgs.MaxMsgLength = st.MaxMsgLength
+	msgs.MaxGas = st.MaxGas
 	msgs.Send(ctx, src, dst)
 
 	logger.Info("msgs relayed",
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+func TestPacketTimedOut(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		name     string
+		packet   chantypes.Packet
+		height   clienttypes.Height
+		expected bool
+	}{
+		{"no timeout", chantypes.Packet{}, clienttypes.NewHeight(1, 100), false},
+		{"before timeout height", chantypes.Packet{TimeoutHeight: clienttypes.NewHeight(1, 101)}, clienttypes.NewHeight(1, 100), false},
+		{"at timeout height", chantypes.Packet{TimeoutHeight: clienttypes.NewHeight(1, 100)}, clienttypes.NewHeight(1, 100), true},
+		{"newer revision", chantypes.Packet{TimeoutHeight: clienttypes.NewHeight(1, 100)}, clienttypes.NewHeight(2, 1), true},
+		{"before timeout timestamp", chantypes.Packet{TimeoutTimestamp: uint64(now.Add(time.Second).UnixNano())}, clienttypes.NewHeight(1, 100), false},
+		{"at timeout timestamp", chantypes.Packet{TimeoutTimestamp: uint64(now.UnixNano())}, clienttypes.NewHeight(1, 100), true},
+	}
+	for _, c := range cases {
+		if actual := packetTimedOut(c.packet, c.height, now); actual != c.expected {
+			t.Errorf("%s: unexpected result: actual=%v, expected=%v", c.name, actual, c.expected)
+		}
+	}
+}
+
+func TestCollectOrderedPackets(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	packet := func(seq uint64, timeoutHeight uint64) *PacketInfo {
+		return &PacketInfo{Packet: chantypes.Packet{
+			Sequence:           seq,
+			DestinationPort:    "transfer",
+			DestinationChannel: "channel-1",
+			TimeoutHeight:      clienttypes.NewHeight(0, timeoutHeight),
+		}}
+	}
+
+	cases := []struct {
+		name string
+		// the latest height of the client tracking the receiver on the sender
+		clientHeight uint64
+		packets      PacketInfoList
+		expected     []uint64
+		timeout      bool
+	}{
+		{"contiguous run", 90, PacketInfoList{packet(3, 200), packet(2, 200), packet(1, 200)}, []uint64{2, 3}, false},
+		{"stop at the gap", 90, PacketInfoList{packet(2, 200), packet(3, 200), packet(5, 200), packet(6, 200)}, []uint64{2, 3}, false},
+		{"gap at the next sequence", 90, PacketInfoList{packet(3, 200), packet(4, 200)}, nil, false},
+		{"timed out after the run", 90, PacketInfoList{packet(2, 200), packet(3, 50)}, []uint64{2}, false},
+		{"timed out at the next sequence", 90, PacketInfoList{packet(2, 50), packet(3, 200)}, nil, true},
+		{"timed out but the client is not updated yet", 40, PacketInfoList{packet(2, 50), packet(3, 200)}, nil, false},
+	}
+	for _, c := range cases {
+		src, dst := newStateChainPair()
+		dst.nextSeqRecv = 2
+		src.clientState = &tmclient.ClientState{LatestHeight: clienttypes.NewHeight(0, c.clientHeight)}
+		sh := newStateSyncHeaders(src, dst)
+
+		run, msgs, err := collectOrderedPackets(context.TODO(), newTestProvableChain(src), newTestProvableChain(dst), c.packets, sh, sdk.AccAddress("relayer"))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		var seqs []uint64
+		for _, p := range run {
+			seqs = append(seqs, p.Sequence)
+		}
+		if len(seqs) != len(c.expected) {
+			t.Errorf("%s: unexpected run: actual=%v, expected=%v", c.name, seqs, c.expected)
+		} else {
+			for i := range seqs {
+				if seqs[i] != c.expected[i] {
+					t.Errorf("%s: unexpected run: actual=%v, expected=%v", c.name, seqs, c.expected)
+					break
+				}
+			}
+		}
+		if !c.timeout {
+			if len(msgs) != 0 {
+				t.Errorf("%s: unexpected msgs: %v", c.name, msgs)
+			}
+			continue
+		}
+		if len(msgs) != 1 {
+			t.Fatalf("%s: MsgTimeout is expected: %v", c.name, msgs)
+		}
+		msg, ok := msgs[0].(*chantypes.MsgTimeout)
+		if !ok || msg.Packet.Sequence != 2 || msg.NextSequenceRecv != 2 || !msg.ProofHeight.EQ(clienttypes.NewHeight(0, c.clientHeight)) {
+			t.Errorf("%s: unexpected MsgTimeout: %v", c.name, msgs[0])
+		}
+	}
+}
+
+func TestCollectOrderedPacketsWithoutQuerier(t *testing.T) {
+	src, dst := newStateChainPair()
+	packets := PacketInfoList{{Packet: chantypes.Packet{Sequence: 3}}, {Packet: chantypes.Packet{Sequence: 5}}}
+	// the receiver doesn't implement NextSequenceReceiveQuerier
+	receiver := newTestProvableChain(struct{ Chain }{dst})
+
+	run, msgs, err := collectOrderedPackets(context.TODO(), newTestProvableChain(src), receiver, packets, newStateSyncHeaders(src, dst), sdk.AccAddress("relayer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(run) != len(packets) || len(msgs) != 0 {
+		t.Errorf("the packets are expected to be relayed as they are: run=%v, msgs=%v", run, msgs)
+	}
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// timeToTimeout returns the time remaining until `packet` times out on the receiving chain at `height` and `timestamp`.
+// The time until the timeout height is estimated by `blockTime`. It returns false if the packet never times out.
+func timeToTimeout(packet chantypes.Packet, height ibcexported.Height, timestamp time.Time, blockTime time.Duration) (time.Duration, bool) {
+	if packetTimedOut(packet, height, timestamp) {
+		return 0, true
+	}
+	var (
+		remaining time.Duration
+		ok        bool
+	)
+	// a timeout height of a later revision can't be estimated by the block time
+	if !packet.TimeoutHeight.IsZero() && packet.TimeoutHeight.GetRevisionNumber() == height.GetRevisionNumber() {
+		remaining, ok = time.Duration(packet.TimeoutHeight.GetRevisionHeight()-height.GetRevisionHeight())*blockTime, true
+	}
+	if packet.TimeoutTimestamp != 0 {
+		if r := time.Unix(0, int64(packet.TimeoutTimestamp)).Sub(timestamp); !ok || r < remaining {
+			remaining, ok = r, true
+		}
+	}
+	return remaining, ok
+}
+
+// schedulePackets orders the packets in each direction of `rp` by their deadlines on the receiving chain, and caps them at maxPackets.
+// It returns true for each receiving chain if any of its packets times out within timeoutUrgency, which should be relayed without delay.
+// Packets on an ORDERED channel keep their order because they can only be received in sequence.
+func (srv *RelayService) schedulePackets(ctx context.Context, rp *RelayPackets) (srcUrgent, dstUrgent bool, err error) {
+	if dstUrgent, rp.Src, err = srv.schedule(ctx, srv.src, srv.dst, rp.Src); err != nil {
+		return false, false, err
+	}
+	if srcUrgent, rp.Dst, err = srv.schedule(ctx, srv.dst, srv.src, rp.Dst); err != nil {
+		return false, false, err
+	}
+	return srcUrgent, dstUrgent, nil
+}
+
+// schedule orders `packets` sent on `sender` by their deadlines on `receiver`, and caps them at maxPackets.
+// Packets that have already timed out are put first so that the cap never holds them back.
+func (srv *RelayService) schedule(ctx context.Context, sender, receiver *ProvableChain, packets PacketInfoList) (bool, PacketInfoList, error) {
+	attr := attribute.Key("chain_id").String(receiver.ChainID())
+	if len(packets) == 0 {
+		metrics.TimeToTimeoutGauge.Delete(attr)
+		return false, packets, nil
+	}
+
+	height := srv.sh.GetQueryContext(ctx, receiver.ChainID()).Height()
+	timestamp, err := receiver.Timestamp(ctx, height)
+	if err != nil {
+		return false, nil, err
+	}
+	deadlines := make(map[*PacketInfo]time.Duration, len(packets))
+	minRemaining := time.Duration(math.MaxInt64)
+	for _, p := range packets {
+		remaining, ok := timeToTimeout(p.Packet, height, timestamp, receiver.AverageBlockTime())
+		if !ok {
+			remaining = math.MaxInt64
+		} else if remaining == 0 {
+			remaining = -1
+		} else if remaining < minRemaining {
+			minRemaining = remaining
+		}
+		deadlines[p] = remaining
+	}
+
+	if minRemaining == math.MaxInt64 {
+		metrics.TimeToTimeoutGauge.Delete(attr)
+	} else {
+		metrics.TimeToTimeoutGauge.Set(int64(minRemaining.Seconds()), attr)
+	}
+
+	if sender.Path().GetOrder() != chantypes.ORDERED {
+		packets = slices.Clone(packets)
+		slices.SortStableFunc(packets, func(a, b *PacketInfo) int {
+			return cmp.Compare(deadlines[a], deadlines[b])
+		})
+	}
+	if srv.optimizeRelay.maxPackets > 0 && uint64(len(packets)) > srv.optimizeRelay.maxPackets {
+		GetChannelPairLogger(sender, receiver).WithSpanContext(ctx).Info("cap the packets relayed in this cycle",
+			"num_packets", len(packets),
+			"max_packets", srv.optimizeRelay.maxPackets,
+		)
+		packets = packets[:srv.optimizeRelay.maxPackets]
+	}
+	return minRemaining <= srv.optimizeRelay.timeoutUrgency, packets, nil
+}
+
+// capAcknowledgements caps the acknowledgements in each direction of `rp` at maxPackets, keeping the order in which they were written
+func (srv *RelayService) capAcknowledgements(rp *RelayPackets) {
+	if maxPackets := srv.optimizeRelay.maxPackets; maxPackets > 0 {
+		if uint64(len(rp.Src)) > maxPackets {
+			rp.Src = rp.Src[:maxPackets]
+		}
+		if uint64(len(rp.Dst)) > maxPackets {
+			rp.Dst = rp.Dst[:maxPackets]
+		}
+	}
+}
+
+// oldestEventHeight returns the lowest event height of `packets`, which may not be ordered by their events after scheduling
+func oldestEventHeight(packets PacketInfoList) clienttypes.Height {
+	oldest := packets[0].EventHeight
+	for _, p := range packets[1:] {
+		if p.EventHeight.LT(oldest) {
+			oldest = p.EventHeight
+		}
+	}
+	return oldest
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+func TestTimeToTimeout(t *testing.T) {
+	now := time.Now()
+	height := clienttypes.NewHeight(1, 100)
+	cases := []struct {
+		name      string
+		packet    chantypes.Packet
+		remaining time.Duration
+		ok        bool
+	}{
+		{"no timeout", chantypes.Packet{}, 0, false},
+		{"timeout height", chantypes.Packet{TimeoutHeight: clienttypes.NewHeight(1, 110)}, 60 * time.Second, true},
+		{"timeout height of a later revision", chantypes.Packet{TimeoutHeight: clienttypes.NewHeight(2, 1)}, 0, false},
+		{"timeout timestamp", chantypes.Packet{TimeoutTimestamp: uint64(now.Add(time.Minute).UnixNano())}, time.Minute, true},
+		{"earlier of both", chantypes.Packet{TimeoutHeight: clienttypes.NewHeight(1, 110), TimeoutTimestamp: uint64(now.Add(time.Hour).UnixNano())}, 60 * time.Second, true},
+		{"timed out", chantypes.Packet{TimeoutHeight: clienttypes.NewHeight(1, 100)}, 0, true},
+	}
+	for _, c := range cases {
+		remaining, ok := timeToTimeout(c.packet, height, now, 6*time.Second)
+		if remaining != c.remaining || ok != c.ok {
+			t.Errorf("%s: unexpected result: actual=(%v, %v), expected=(%v, %v)", c.name, remaining, ok, c.remaining, c.ok)
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	// dst is at height 100 with the block time of a second
+	packet := func(seq uint64, timeoutHeight uint64) *PacketInfo {
+		return &PacketInfo{Packet: chantypes.Packet{Sequence: seq, TimeoutHeight: clienttypes.NewHeight(0, timeoutHeight)}}
+	}
+	packets := PacketInfoList{packet(1, 0), packet(2, 400), packet(3, 50), packet(4, 200), packet(5, 100)}
+
+	cases := []struct {
+		name           string
+		order          string
+		maxPackets     uint64
+		timeoutUrgency time.Duration
+		expected       []uint64
+		urgent         bool
+	}{
+		{"ordered by deadline with timed-out packets first", "unordered", 0, 0, []uint64{3, 5, 4, 2, 1}, false},
+		{"capped without dropping timed-out packets", "unordered", 3, 0, []uint64{3, 5, 4}, false},
+		{"ordered channel", "ordered", 2, 0, []uint64{1, 2}, false},
+		{"urgent", "unordered", 0, 100 * time.Second, []uint64{3, 5, 4, 2, 1}, true},
+		{"not urgent", "unordered", 0, 99 * time.Second, []uint64{3, 5, 4, 2, 1}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, d := newStateChainPair()
+			s.path.Order = c.order
+			srv := &RelayService{
+				src:           newTestProvableChain(s),
+				dst:           newTestProvableChain(d),
+				sh:            newStateSyncHeaders(s, d),
+				optimizeRelay: OptimizeRelay{maxPackets: c.maxPackets, timeoutUrgency: c.timeoutUrgency},
+			}
+
+			urgent, ret, err := srv.schedule(context.TODO(), srv.src, srv.dst, packets)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if seqs := ret.ExtractSequenceList(); !slices.Equal(seqs, c.expected) {
+				t.Errorf("unexpected packets: actual=%v, expected=%v", seqs, c.expected)
+			}
+			if urgent != c.urgent {
+				t.Errorf("unexpected urgency: %v", urgent)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+)
+
+// PacketTx is a tx in which a packet was processed
+type PacketTx struct {
+	TxHash string             `json:"tx_hash"`
+	Height clienttypes.Height `json:"height"`
+}
+
+// PacketTxQuerier is an optional interface of Chain that finds the txs in which a packet of the path end was processed.
+// Each method returns nil if the tx is not found.
+type PacketTxQuerier interface {
+	// QuerySentPacketTx returns the packet `seq` sent from the path end and the tx that sent it
+	QuerySentPacketTx(ctx QueryContext, seq uint64) (*chantypes.Packet, *PacketTx, error)
+
+	// QueryReceivedPacketTx returns the tx that received the packet `seq` on the path end
+	QueryReceivedPacketTx(ctx QueryContext, seq uint64) (*PacketTx, error)
+
+	// QueryWrittenAcknowledgementTx returns the acknowledgement written for the packet `seq` on the path end and the tx that wrote it
+	QueryWrittenAcknowledgementTx(ctx QueryContext, seq uint64) ([]byte, *PacketTx, error)
+
+	// QueryAcknowledgedPacketTx returns the tx that acknowledged the packet `seq` sent from the path end
+	QueryAcknowledgedPacketTx(ctx QueryContext, seq uint64) (*PacketTx, error)
+
+	// QueryTimedOutPacketTx returns the tx that timed out the packet `seq` sent from the path end
+	QueryTimedOutPacketTx(ctx QueryContext, seq uint64) (*PacketTx, error)
+
+	// QueryPacketCommitment returns the commitment of the packet `seq` sent from the path end, or nil if it doesn't exist
+	QueryPacketCommitment(ctx QueryContext, seq uint64) ([]byte, error)
+}
+
+// PacketStep is a step of the packet lifecycle processed in a tx
+type PacketStep struct {
+	ChainID string `json:"chain_id"`
+	PacketTx
+	// Finalized is true if the block including the tx is finalized according to the prover
+	Finalized bool `json:"finalized"`
+}
+
+// PacketTrace is the lifecycle of a packet across both chains. Steps that haven't been processed are nil.
+type PacketTrace struct {
+	Packet           chantypes.Packet `json:"packet"`
+	Send             *PacketStep      `json:"send"`
+	CommitmentExists bool             `json:"commitment_exists"`
+	Recv             *PacketStep      `json:"recv,omitempty"`
+	// TimedOut is true if the packet can no longer be received on the counterparty chain
+	TimedOut        bool        `json:"timed_out"`
+	Timeout         *PacketStep `json:"timeout,omitempty"`
+	WriteAck        *PacketStep `json:"write_ack,omitempty"`
+	Acknowledgement string      `json:"acknowledgement,omitempty"`
+	Ack             *PacketStep `json:"ack,omitempty"`
+}
+
+// TracePacket follows the packet `seq` sent from `src` to `dst` across both chains
+func TracePacket(ctx context.Context, src, dst *ProvableChain, seq uint64) (*PacketTrace, error) {
+	srcQuerier, ok := src.Chain.(PacketTxQuerier)
+	if !ok {
+		return nil, fmt.Errorf("chain %s doesn't support querying packet txs", src.ChainID())
+	}
+	dstQuerier, ok := dst.Chain.(PacketTxQuerier)
+	if !ok {
+		return nil, fmt.Errorf("chain %s doesn't support querying packet txs", dst.ChainID())
+	}
+	srcCtx, err := latestQueryContext(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	dstCtx, err := latestQueryContext(ctx, dst)
+	if err != nil {
+		return nil, err
+	}
+	srcFinalized, err := src.GetLatestFinalizedHeader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest finalized header of chain %s: %v", src.ChainID(), err)
+	}
+	dstFinalized, err := dst.GetLatestFinalizedHeader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest finalized header of chain %s: %v", dst.ChainID(), err)
+	}
+	srcStep := func(tx *PacketTx) *PacketStep {
+		return newPacketStep(src, tx, srcFinalized.GetHeight())
+	}
+	dstStep := func(tx *PacketTx) *PacketStep {
+		return newPacketStep(dst, tx, dstFinalized.GetHeight())
+	}
+
+	packet, sendTx, err := srcQuerier.QuerySentPacketTx(srcCtx, seq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the sent packet: %v", err)
+	} else if packet == nil {
+		return nil, fmt.Errorf("packet %d is not found on chain %s", seq, src.ChainID())
+	}
+	trace := &PacketTrace{Packet: *packet, Send: srcStep(sendTx)}
+
+	if commitment, err := srcQuerier.QueryPacketCommitment(srcCtx, seq); err != nil {
+		return nil, err
+	} else {
+		trace.CommitmentExists = len(commitment) > 0
+	}
+
+	recvTx, err := dstQuerier.QueryReceivedPacketTx(dstCtx, seq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the received packet: %v", err)
+	}
+	if recvTx == nil {
+		timestamp, err := dst.Timestamp(ctx, dstCtx.Height())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the timestamp of chain %s: %v", dst.ChainID(), err)
+		}
+		trace.TimedOut = packetTimedOut(*packet, dstCtx.Height(), timestamp)
+		if timeoutTx, err := srcQuerier.QueryTimedOutPacketTx(srcCtx, seq); err != nil {
+			return nil, fmt.Errorf("failed to query the timed-out packet: %v", err)
+		} else if timeoutTx != nil {
+			trace.Timeout = srcStep(timeoutTx)
+		}
+		return trace, nil
+	}
+	trace.Recv = dstStep(recvTx)
+
+	ack, writeAckTx, err := dstQuerier.QueryWrittenAcknowledgementTx(dstCtx, seq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the written acknowledgement: %v", err)
+	} else if writeAckTx == nil {
+		return trace, nil
+	}
+	trace.WriteAck, trace.Acknowledgement = dstStep(writeAckTx), string(ack)
+
+	if ackTx, err := srcQuerier.QueryAcknowledgedPacketTx(srcCtx, seq); err != nil {
+		return nil, fmt.Errorf("failed to query the acknowledged packet: %v", err)
+	} else if ackTx != nil {
+		trace.Ack = srcStep(ackTx)
+	}
+	return trace, nil
+}
+
+func newPacketStep(chain *ProvableChain, tx *PacketTx, finalizedHeight ibcexported.Height) *PacketStep {
+	return &PacketStep{
+		ChainID:   chain.ChainID(),
+		PacketTx:  *tx,
+		Finalized: tx.Height.LTE(finalizedHeight),
+	}
+}
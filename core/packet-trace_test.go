@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+)
+
+// traceChain is a stateChain that has the txs processing the packet of sequence 1
+type traceChain struct {
+	*stateChain
+	packet     *chantypes.Packet
+	commitment []byte
+	sendTx     *PacketTx
+	recvTx     *PacketTx
+	ack        []byte
+	writeAckTx *PacketTx
+	ackTx      *PacketTx
+	timeoutTx  *PacketTx
+}
+
+func (c *traceChain) QuerySentPacketTx(ctx QueryContext, seq uint64) (*chantypes.Packet, *PacketTx, error) {
+	if c.packet == nil || c.packet.Sequence != seq {
+		return nil, nil, nil
+	}
+	return c.packet, c.sendTx, nil
+}
+
+func (c *traceChain) QueryReceivedPacketTx(ctx QueryContext, seq uint64) (*PacketTx, error) {
+	return c.recvTx, nil
+}
+
+func (c *traceChain) QueryWrittenAcknowledgementTx(ctx QueryContext, seq uint64) ([]byte, *PacketTx, error) {
+	return c.ack, c.writeAckTx, nil
+}
+
+func (c *traceChain) QueryAcknowledgedPacketTx(ctx QueryContext, seq uint64) (*PacketTx, error) {
+	return c.ackTx, nil
+}
+
+func (c *traceChain) QueryTimedOutPacketTx(ctx QueryContext, seq uint64) (*PacketTx, error) {
+	return c.timeoutTx, nil
+}
+
+func (c *traceChain) QueryPacketCommitment(ctx QueryContext, seq uint64) ([]byte, error) {
+	return c.commitment, nil
+}
+
+// finalizedProver is a stateProver whose latest finalized header is at `height`
+type finalizedProver struct {
+	stateProver
+	height clienttypes.Height
+}
+
+func (p finalizedProver) GetLatestFinalizedHeader(ctx context.Context) (Header, error) {
+	return &testHeader{height: p.height}, nil
+}
+
+func TestTracePacket(t *testing.T) {
+	packetTx := func(hash string, height uint64) *PacketTx {
+		return &PacketTx{TxHash: hash, Height: clienttypes.NewHeight(0, height)}
+	}
+	// the latest finalized height is 95 on both chains
+	cases := []struct {
+		name     string
+		setup    func(src, dst *traceChain)
+		validate func(t *testing.T, trace *PacketTrace)
+	}{
+		{"sent", func(src, dst *traceChain) {}, func(t *testing.T, trace *PacketTrace) {
+			if !trace.CommitmentExists || trace.TimedOut || trace.Recv != nil || trace.Timeout != nil {
+				t.Errorf("unexpected trace: %+v", trace)
+			}
+		}},
+		{"timed out", func(src, dst *traceChain) {
+			src.packet.TimeoutHeight = clienttypes.NewHeight(0, 50)
+		}, func(t *testing.T, trace *PacketTrace) {
+			if !trace.TimedOut || trace.Timeout != nil || trace.Recv != nil {
+				t.Errorf("unexpected trace: %+v", trace)
+			}
+		}},
+		{"timeout relayed", func(src, dst *traceChain) {
+			src.packet.TimeoutHeight = clienttypes.NewHeight(0, 50)
+			src.commitment = nil
+			src.timeoutTx = packetTx("timeout", 98)
+		}, func(t *testing.T, trace *PacketTrace) {
+			if !trace.TimedOut || trace.CommitmentExists {
+				t.Errorf("unexpected trace: %+v", trace)
+			}
+			if trace.Timeout == nil || trace.Timeout.ChainID != "ibc0" || trace.Timeout.TxHash != "timeout" || trace.Timeout.Finalized {
+				t.Errorf("unexpected timeout step: %+v", trace.Timeout)
+			}
+		}},
+		{"received without acknowledgement", func(src, dst *traceChain) {
+			dst.recvTx = packetTx("recv", 20)
+		}, func(t *testing.T, trace *PacketTrace) {
+			if trace.Recv == nil || trace.Recv.ChainID != "ibc1" || !trace.Recv.Finalized {
+				t.Errorf("unexpected recv step: %+v", trace.Recv)
+			}
+			if trace.WriteAck != nil || trace.Ack != nil || trace.TimedOut {
+				t.Errorf("unexpected trace: %+v", trace)
+			}
+		}},
+		{"acknowledgement written", func(src, dst *traceChain) {
+			dst.recvTx = packetTx("recv", 20)
+			dst.ack, dst.writeAckTx = []byte(`{"result":"AQ=="}`), packetTx("recv", 20)
+		}, func(t *testing.T, trace *PacketTrace) {
+			if trace.WriteAck == nil || trace.Acknowledgement != `{"result":"AQ=="}` || trace.Ack != nil {
+				t.Errorf("unexpected trace: %+v", trace)
+			}
+		}},
+		{"acknowledged", func(src, dst *traceChain) {
+			src.commitment = nil
+			dst.recvTx = packetTx("recv", 20)
+			dst.ack, dst.writeAckTx = []byte(`{"result":"AQ=="}`), packetTx("recv", 20)
+			src.ackTx = packetTx("ack", 95)
+		}, func(t *testing.T, trace *PacketTrace) {
+			if trace.CommitmentExists {
+				t.Errorf("unexpected commitment: %+v", trace)
+			}
+			if trace.Ack == nil || trace.Ack.ChainID != "ibc0" || trace.Ack.TxHash != "ack" || !trace.Ack.Finalized {
+				t.Errorf("unexpected ack step: %+v", trace.Ack)
+			}
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, d := newStateChainPair()
+			src := &traceChain{
+				stateChain: s,
+				packet:     &chantypes.Packet{Sequence: 1, SourcePort: "transfer", SourceChannel: "channel-0", TimeoutHeight: clienttypes.NewHeight(0, 1000)},
+				commitment: []byte("commitment"),
+				sendTx:     packetTx("send", 10),
+			}
+			dst := &traceChain{stateChain: d}
+			c.setup(src, dst)
+			finalized := clienttypes.NewHeight(0, 95)
+
+			trace, err := TracePacket(context.TODO(), NewProvableChain(src, finalizedProver{height: finalized}), NewProvableChain(dst, finalizedProver{height: finalized}), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if trace.Packet.Sequence != 1 || trace.Send == nil || trace.Send.ChainID != "ibc0" || trace.Send.TxHash != "send" || !trace.Send.Finalized {
+				t.Errorf("unexpected send step: %+v", trace.Send)
+			}
+			c.validate(t, trace)
+		})
+	}
+}
+
+func TestTracePacketNotFound(t *testing.T) {
+	s, d := newStateChainPair()
+	src, dst := &traceChain{stateChain: s}, &traceChain{stateChain: d}
+	finalized := clienttypes.NewHeight(0, 95)
+	if _, err := TracePacket(context.TODO(), NewProvableChain(src, finalizedProver{height: finalized}), NewProvableChain(dst, finalizedProver{height: finalized}), 1); err == nil {
+		t.Error("expected an error for the packet not found")
+	}
+	if _, err := TracePacket(context.TODO(), newTestProvableChain(s), newTestProvableChain(d), 1); err == nil {
+		t.Error("expected an error for the chains without PacketTxQuerier")
+	}
+}
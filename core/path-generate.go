@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+)
+
+// GeneratedPath is a path found on chains with the states of its ends
+type GeneratedPath struct {
+	Path *Path `json:"path" yaml:"path"`
+	// SrcState and DstState are the states of the channel ends, or the connection ends if the path has no channel
+	SrcState string `json:"src-state" yaml:"src-state"`
+	DstState string `json:"dst-state" yaml:"dst-state"`
+}
+
+// GeneratePaths enumerates the clients, connections and channels on `src` and `dst`, and returns the paths made of the ones referencing each other.
+// A connection pair without any channel pair is returned as a path without channel.
+func GeneratePaths(ctx context.Context, src, dst *ProvableChain) ([]*GeneratedPath, error) {
+	srcQuerier, ok := src.Chain.(LinkQuerier)
+	if !ok {
+		return nil, fmt.Errorf("chain %s doesn't support listing IBC objects", src.ChainID())
+	}
+	dstQuerier, ok := dst.Chain.(LinkQuerier)
+	if !ok {
+		return nil, fmt.Errorf("chain %s doesn't support listing IBC objects", dst.ChainID())
+	}
+	srcCtx, err := latestQueryContext(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	dstCtx, err := latestQueryContext(ctx, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	srcClients, err := clientsTracking(srcCtx, srcQuerier, dst.ChainID())
+	if err != nil {
+		return nil, err
+	}
+	dstClients, err := clientsTracking(dstCtx, dstQuerier, src.ChainID())
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []*GeneratedPath
+	for _, srcClientID := range srcClients {
+		connectionIDs, err := srcQuerier.QueryClientConnections(srcCtx, srcClientID)
+		if err != nil {
+			return nil, err
+		}
+		for _, srcConnID := range connectionIDs {
+			srcConn, err := src.QueryConnection(srcCtx, srcConnID)
+			if err != nil {
+				return nil, err
+			}
+			dstClientID, dstConnID := srcConn.Connection.Counterparty.ClientId, srcConn.Connection.Counterparty.ConnectionId
+			if dstConnID == "" || !slices.Contains(dstClients, dstClientID) {
+				continue
+			}
+			dstConn, err := dst.QueryConnection(dstCtx, dstConnID)
+			if err != nil {
+				return nil, err
+			} else if dstConn.Connection.ClientId != dstClientID ||
+				dstConn.Connection.Counterparty.ConnectionId != srcConnID ||
+				dstConn.Connection.Counterparty.ClientId != srcClientID {
+				continue
+			}
+
+			srcEnd := &PathEnd{ChainID: src.ChainID(), ClientID: srcClientID, ConnectionID: srcConnID}
+			dstEnd := &PathEnd{ChainID: dst.ChainID(), ClientID: dstClientID, ConnectionID: dstConnID}
+			channelPaths, err := generateChannelPaths(srcCtx, dstCtx, srcQuerier, dstQuerier, srcEnd, dstEnd)
+			if err != nil {
+				return nil, err
+			}
+			if len(channelPaths) == 0 {
+				channelPaths = append(channelPaths, &GeneratedPath{
+					Path:     &Path{Src: srcEnd, Dst: dstEnd, Strategy: &StrategyCfg{Type: (&NaiveStrategy{}).GetType()}},
+					SrcState: srcConn.Connection.State.String(),
+					DstState: dstConn.Connection.State.String(),
+				})
+			}
+			paths = append(paths, channelPaths...)
+		}
+	}
+	return paths, nil
+}
+
+// clientsTracking returns the identifiers of the clients that track the chain `chainID`
+func clientsTracking(ctx QueryContext, querier LinkQuerier, chainID string) ([]string, error) {
+	states, err := querier.QueryClientStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, s := range states {
+		cs, err := clienttypes.UnpackClientState(s.ClientState)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack the client state of %s: %v", s.ClientId, err)
+		}
+		if trackedChainID(cs) == chainID {
+			ids = append(ids, s.ClientId)
+		}
+	}
+	return ids, nil
+}
+
+// generateChannelPaths returns the paths of the channel pairs over the connection pair of `srcEnd` and `dstEnd`
+func generateChannelPaths(srcCtx, dstCtx QueryContext, srcQuerier, dstQuerier LinkQuerier, srcEnd, dstEnd *PathEnd) ([]*GeneratedPath, error) {
+	srcChannels, err := srcQuerier.QueryConnectionChannels(srcCtx, srcEnd.ConnectionID)
+	if err != nil {
+		return nil, err
+	}
+	dstChannels, err := dstQuerier.QueryConnectionChannels(dstCtx, dstEnd.ConnectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []*GeneratedPath
+	for _, srcChan := range srcChannels {
+		for _, dstChan := range dstChannels {
+			if srcChan.Counterparty.PortId != dstChan.PortId || srcChan.Counterparty.ChannelId != dstChan.ChannelId ||
+				dstChan.Counterparty.PortId != srcChan.PortId || dstChan.Counterparty.ChannelId != srcChan.ChannelId {
+				continue
+			}
+			src, dst := *srcEnd, *dstEnd
+			src.PortID, src.ChannelID = srcChan.PortId, srcChan.ChannelId
+			src.Order, src.Version = orderString(srcChan.Ordering), srcChan.Version
+			dst.PortID, dst.ChannelID = dstChan.PortId, dstChan.ChannelId
+			dst.Order, dst.Version = orderString(dstChan.Ordering), dstChan.Version
+			paths = append(paths, &GeneratedPath{
+				Path:     &Path{Src: &src, Dst: &dst, Strategy: &StrategyCfg{Type: (&NaiveStrategy{}).GetType()}},
+				SrcState: srcChan.State.String(),
+				DstState: dstChan.State.String(),
+			})
+		}
+	}
+	return paths, nil
+}
+
+func orderString(order chantypes.Order) string {
+	switch order {
+	case chantypes.ORDERED:
+		return "ORDERED"
+	case chantypes.UNORDERED:
+		return "UNORDERED"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+)
+
+func TestClientsTracking(t *testing.T) {
+	src, _, _, _ := newLinkChainPair(t)
+	src.addClient(t, "07-tendermint-0", "ibc1", 100, "")
+	src.addClient(t, "07-tendermint-1", "ibc2", 100, "")
+	src.addClient(t, "07-tendermint-2", "ibc1", 50, "")
+
+	ids, err := clientsTracking(NewQueryContext(context.TODO(), src.height), src, "ibc1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != "07-tendermint-0" || ids[1] != "07-tendermint-2" {
+		t.Errorf("unexpected clients: %v", ids)
+	}
+}
+
+func TestGeneratePaths(t *testing.T) {
+	src, dst, srcPC, dstPC := newLinkChainPair(t)
+	src.addClient(t, "07-tendermint-0", "ibc1", 100, "")
+	src.addClient(t, "07-tendermint-2", "ibc2", 100, "")
+	dst.addClient(t, "07-tendermint-1", "ibc0", 100, "")
+
+	// the pair with a channel pair and a channel without the counterparty
+	src.addConnection("connection-0", "07-tendermint-0", conntypes.OPEN, "07-tendermint-1", "connection-1")
+	dst.addConnection("connection-1", "07-tendermint-1", conntypes.OPEN, "07-tendermint-0", "connection-0")
+	src.addChannel("channel-0", "connection-0", chantypes.OPEN, "transfer", "channel-1")
+	src.addChannel("channel-2", "connection-0", chantypes.OPEN, "transfer", "channel-9")
+	dst.addChannel("channel-1", "connection-1", chantypes.OPEN, "transfer", "channel-0")
+	// the connection whose counterparty is not known yet
+	src.addConnection("connection-2", "07-tendermint-0", conntypes.INIT, "07-tendermint-1", "")
+	// the counterparty connection referencing another client
+	src.addConnection("connection-3", "07-tendermint-0", conntypes.OPEN, "07-tendermint-1", "connection-3")
+	dst.addConnection("connection-3", "07-tendermint-1", conntypes.OPEN, "07-tendermint-7", "connection-3")
+	// the connection of the client tracking another chain
+	src.addConnection("connection-4", "07-tendermint-2", conntypes.OPEN, "07-tendermint-1", "connection-4")
+	dst.addConnection("connection-4", "07-tendermint-1", conntypes.OPEN, "07-tendermint-2", "connection-4")
+	// the pair without channel
+	src.addConnection("connection-5", "07-tendermint-0", conntypes.OPEN, "07-tendermint-1", "connection-6")
+	dst.addConnection("connection-6", "07-tendermint-1", conntypes.TRYOPEN, "07-tendermint-0", "connection-5")
+
+	paths, err := GeneratePaths(context.TODO(), srcPC, dstPC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		src, dst           PathEnd
+		srcState, dstState string
+	}{
+		{
+			PathEnd{ChainID: "ibc0", ClientID: "07-tendermint-0", ConnectionID: "connection-0", ChannelID: "channel-0", PortID: "transfer", Order: "UNORDERED", Version: "ics20-1"},
+			PathEnd{ChainID: "ibc1", ClientID: "07-tendermint-1", ConnectionID: "connection-1", ChannelID: "channel-1", PortID: "transfer", Order: "UNORDERED", Version: "ics20-1"},
+			"STATE_OPEN", "STATE_OPEN",
+		},
+		{
+			PathEnd{ChainID: "ibc0", ClientID: "07-tendermint-0", ConnectionID: "connection-5"},
+			PathEnd{ChainID: "ibc1", ClientID: "07-tendermint-1", ConnectionID: "connection-6"},
+			"STATE_OPEN", "STATE_TRYOPEN",
+		},
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("unexpected number of paths: %d", len(paths))
+	}
+	for i, e := range expected {
+		p := paths[i]
+		if !reflect.DeepEqual(*p.Path.Src, e.src) || !reflect.DeepEqual(*p.Path.Dst, e.dst) {
+			t.Errorf("unexpected path %d: src=%+v, dst=%+v", i, p.Path.Src, p.Path.Dst)
+		}
+		if p.SrcState != e.srcState || p.DstState != e.dstState {
+			t.Errorf("unexpected states of path %d: %s, %s", i, p.SrcState, p.DstState)
+		}
+	}
+}
+
+func TestGeneratePathsWithoutQuerier(t *testing.T) {
+	src, dst := newStateChainPair()
+	if _, err := GeneratePaths(context.TODO(), newTestProvableChain(src), newTestProvableChain(dst)); err == nil {
+		t.Error("expected an error for the chains without LinkQuerier")
+	}
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	conntypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+)
+
+// ClientStatusQuerier is an optional interface of Chain that queries the status of the light client of the path end.
+// If a chain doesn't implement it, the client is regarded as active as long as its client state exists.
+type ClientStatusQuerier interface {
+	QueryClientStatus(ctx QueryContext) (ibcexported.Status, error)
+}
+
+// PathStatus represents the health of a path
+type PathStatus struct {
+	// Chains is true if both chains are reachable
+	Chains bool `json:"chains" yaml:"chains"`
+	// Clients is true if both clients exist and are active
+	Clients bool `json:"clients" yaml:"clients"`
+	// Connection is true if the connection is OPEN on both chains with the matching counterparty IDs
+	Connection bool `json:"connection" yaml:"connection"`
+	// Channel is true if the channel is OPEN on both chains with the matching counterparty IDs
+	Channel bool `json:"channel" yaml:"channel"`
+	// Upgrading is true if a channel upgrade is in progress
+	Upgrading bool `json:"upgrading" yaml:"upgrading"`
+	// Errors describes why the checks failed
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// PathWithStatus is a path with its status
+type PathWithStatus struct {
+	Path   *Path      `json:"path" yaml:"path"`
+	Status PathStatus `json:"status" yaml:"status"`
+}
+
+// QueryPathStatus checks the health of the path between `src` and `dst`.
+// The checks are performed in order of chains, clients, connection and channel, and stop at the first failure.
+func (p *Path) QueryPathStatus(ctx context.Context, src, dst *ProvableChain) *PathWithStatus {
+	out := &PathWithStatus{Path: p}
+	st := &out.Status
+	addError := func(format string, args ...any) {
+		st.Errors = append(st.Errors, fmt.Sprintf(format, args...))
+	}
+
+	srcH, err := src.LatestHeight(ctx)
+	if err != nil {
+		addError("chain %s is unreachable: %v", src.ChainID(), err)
+	}
+	dstH, dstErr := dst.LatestHeight(ctx)
+	if dstErr != nil {
+		addError("chain %s is unreachable: %v", dst.ChainID(), dstErr)
+	}
+	if err != nil || dstErr != nil {
+		return out
+	}
+	st.Chains = true
+	srcCtx, dstCtx := NewQueryContext(ctx, srcH), NewQueryContext(ctx, dstH)
+
+	if _, _, err := QueryClientStatePair(srcCtx, dstCtx, src, dst, false); err != nil {
+		addError("failed to query client states: %v", err)
+		return out
+	}
+	st.Clients = true
+	for _, chain := range []*ProvableChain{src, dst} {
+		querier, ok := chain.Chain.(ClientStatusQuerier)
+		if !ok {
+			continue
+		}
+		queryCtx := srcCtx
+		if chain == dst {
+			queryCtx = dstCtx
+		}
+		if status, err := querier.QueryClientStatus(queryCtx); err != nil {
+			st.Clients = false
+			addError("failed to query the status of client %s on %s: %v", chain.Path().ClientID, chain.ChainID(), err)
+		} else if status != ibcexported.Active {
+			st.Clients = false
+			addError("client %s on %s is %s", chain.Path().ClientID, chain.ChainID(), status)
+		}
+	}
+	if !st.Clients {
+		return out
+	}
+
+	srcConn, dstConn, err := QueryConnectionPair(srcCtx, dstCtx, src, dst, false)
+	if err != nil {
+		addError("failed to query connections: %v", err)
+		return out
+	}
+	st.Connection = true
+	for _, c := range []struct {
+		chain, counterparty *ProvableChain
+		conn                *conntypes.ConnectionEnd
+	}{
+		{src, dst, srcConn.Connection},
+		{dst, src, dstConn.Connection},
+	} {
+		if c.conn.State != conntypes.OPEN {
+			st.Connection = false
+			addError("connection %s on %s is %s", c.chain.Path().ConnectionID, c.chain.ChainID(), c.conn.State)
+		} else if c.conn.ClientId != c.chain.Path().ClientID ||
+			c.conn.Counterparty.ClientId != c.counterparty.Path().ClientID ||
+			c.conn.Counterparty.ConnectionId != c.counterparty.Path().ConnectionID {
+			st.Connection = false
+			addError("connection %s on %s doesn't match the path: client_id=%s, counterparty_client_id=%s, counterparty_connection_id=%s",
+				c.chain.Path().ConnectionID, c.chain.ChainID(), c.conn.ClientId, c.conn.Counterparty.ClientId, c.conn.Counterparty.ConnectionId)
+		}
+	}
+	if !st.Connection {
+		return out
+	}
+
+	srcChan, dstChan, err := QueryChannelPair(srcCtx, dstCtx, src, dst, false)
+	if err != nil {
+		addError("failed to query channels: %v", err)
+		return out
+	}
+	st.Channel = true
+	for _, c := range []struct {
+		chain, counterparty *ProvableChain
+		channel             *chantypes.Channel
+	}{
+		{src, dst, srcChan.Channel},
+		{dst, src, dstChan.Channel},
+	} {
+		switch c.channel.State {
+		case chantypes.FLUSHING, chantypes.FLUSHCOMPLETE:
+			st.Upgrading = true
+		}
+		if c.channel.State == chantypes.CLOSED && c.channel.Ordering == chantypes.ORDERED {
+			// an ORDERED channel (e.g. an ICA channel) is closed by a packet timeout and can be reopened on the same ports
+			st.Channel = false
+			addError("channel %s on %s is %s and can be reopened by `tx channel --reopen`", c.chain.Path().ChannelID, c.chain.ChainID(), c.channel.State)
+		} else if c.channel.State != chantypes.OPEN {
+			st.Channel = false
+			addError("channel %s on %s is %s", c.chain.Path().ChannelID, c.chain.ChainID(), c.channel.State)
+		} else if c.channel.Counterparty.PortId != c.counterparty.Path().PortID ||
+			c.channel.Counterparty.ChannelId != c.counterparty.Path().ChannelID {
+			st.Channel = false
+			addError("channel %s on %s doesn't match the path: counterparty_port_id=%s, counterparty_channel_id=%s",
+				c.chain.Path().ChannelID, c.chain.ChainID(), c.channel.Counterparty.PortId, c.channel.Counterparty.ChannelId)
+		}
+	}
+
+	// an upgrade may have been initialized on either chain while both channel ends are still OPEN
+	if !st.Upgrading {
+		srcUpg, dstUpg, err := QueryChannelUpgradePair(srcCtx, dstCtx, src, dst, false)
+		if err != nil {
+			addError("failed to query channel upgrades: %v", err)
+		} else if srcUpg != nil || dstUpg != nil {
+			st.Upgrading = true
+		}
+	}
+
+	return out
+}
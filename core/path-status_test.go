@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+)
+
+func TestQueryPathStatus(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(src, dst *stateChain)
+		expected PathStatus
+		errorMsg string
+	}{
+		{"healthy", func(src, dst *stateChain) {}, PathStatus{Chains: true, Clients: true, Connection: true, Channel: true}, ""},
+		{"unreachable", func(src, dst *stateChain) {
+			dst.err = fmt.Errorf("connection refused")
+		}, PathStatus{}, "chain ibc1 is unreachable"},
+		{"client not found", func(src, dst *stateChain) {
+			src.clientState = nil
+		}, PathStatus{Chains: true}, "failed to query client states"},
+		{"client expired", func(src, dst *stateChain) {
+			dst.clientStatus = ibcexported.Expired
+		}, PathStatus{Chains: true}, "client 07-tendermint-1 on ibc1 is Expired"},
+		{"connection counterparty mismatch", func(src, dst *stateChain) {
+			src.connection.Counterparty.ConnectionId = "connection-9"
+		}, PathStatus{Chains: true, Clients: true}, "connection connection-0 on ibc0 doesn't match the path"},
+		{"channel not open", func(src, dst *stateChain) {
+			dst.channel.State = chantypes.TRYOPEN
+		}, PathStatus{Chains: true, Clients: true, Connection: true}, "channel channel-1 on ibc1 is STATE_TRYOPEN"},
+		{"channel counterparty mismatch", func(src, dst *stateChain) {
+			dst.channel.Counterparty.ChannelId = "channel-9"
+		}, PathStatus{Chains: true, Clients: true, Connection: true}, "channel channel-1 on ibc1 doesn't match the path"},
+		{"ordered channel closed", func(src, dst *stateChain) {
+			src.channel.Ordering, src.channel.State = chantypes.ORDERED, chantypes.CLOSED
+		}, PathStatus{Chains: true, Clients: true, Connection: true}, "can be reopened by `tx channel --reopen`"},
+		{"upgrade initialized", func(src, dst *stateChain) {
+			src.upgrade = &chantypes.Upgrade{}
+		}, PathStatus{Chains: true, Clients: true, Connection: true, Channel: true, Upgrading: true}, ""},
+		{"flushing", func(src, dst *stateChain) {
+			src.channel.State = chantypes.FLUSHING
+		}, PathStatus{Chains: true, Clients: true, Connection: true, Upgrading: true}, "channel channel-0 on ibc0 is STATE_FLUSHING"},
+	}
+	for _, c := range cases {
+		src, dst := newStateChainPair()
+		c.modify(src, dst)
+		path := &Path{Src: src.path, Dst: dst.path}
+		st := path.QueryPathStatus(context.TODO(), NewProvableChain(src, nil), NewProvableChain(dst, nil)).Status
+
+		errs := st.Errors
+		st.Errors = nil
+		if fmt.Sprint(st) != fmt.Sprint(c.expected) {
+			t.Errorf("%s: unexpected status: actual=%+v, expected=%+v", c.name, st, c.expected)
+		}
+		if c.errorMsg == "" && len(errs) > 0 {
+			t.Errorf("%s: unexpected errors: %v", c.name, errs)
+		} else if c.errorMsg != "" && (len(errs) == 0 || !strings.Contains(errs[0], c.errorMsg)) {
+			t.Errorf("%s: expected error %q: %v", c.name, c.errorMsg, errs)
+		}
+	}
+}
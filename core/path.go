@@ -92,6 +92,10 @@ type Path struct {
 	Src      *PathEnd     `yaml:"src" json:"src"`
 	Dst      *PathEnd     `yaml:"dst" json:"dst"`
 	Strategy *StrategyCfg `yaml:"strategy" json:"strategy"`
+
+	// ChannelUpgradePolicy decides whether the relay service steps channel upgrades initialized on either chain.
+	// If nil, channel upgrades are executed only by `tx channel-upgrade execute`.
+	ChannelUpgradePolicy *ChannelUpgradePolicy `yaml:"channel-upgrade-policy,omitempty" json:"channel-upgrade-policy,omitempty"`
 }
 
 // Ordered returns true if the path is ordered and false if otherwise
@@ -117,6 +121,9 @@ func (p *Path) Validate() (err error) {
 		return fmt.Errorf("both sides must have same order ('ORDERED' or 'UNORDERED'), got src(%s) and dst(%s)",
 			p.Src.Order, p.Dst.Order)
 	}
+	if err = p.ChannelUpgradePolicy.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -16,7 +16,7 @@ var (
 )
 
 const (
-	// TODO make it to be configurable
+	// DefaultDelayPeriod is the delay period of connections created by paths without `delay-period`
 	DefaultDelayPeriod uint64 = 0
 )
 
@@ -30,6 +30,12 @@ type PathEnd struct {
 	PortID       string `yaml:"port-id,omitempty" json:"port-id,omitempty"`
 	Order        string `yaml:"order,omitempty" json:"order,omitempty"`
 	Version      string `yaml:"version,omitempty" json:"version,omitempty"`
+	// DelayPeriod is the delay period in nanoseconds of the connection initialized by this path end
+	DelayPeriod uint64 `yaml:"delay-period,omitempty" json:"delay-period,omitempty"`
+	// SubstituteClientID is the substitute client created by the last `tx recover-client` to replace the client of this path end
+	SubstituteClientID string `yaml:"substitute-client-id,omitempty" json:"substitute-client-id,omitempty"`
+	// Quarantine is the packets whose msgs made txs fail on this chain, which are not relayed until released
+	Quarantine []*QuarantinedPacket `yaml:"quarantine,omitempty" json:"quarantine,omitempty"`
 }
 
 // OrderFromString parses a string into a channel order byte
@@ -72,6 +78,22 @@ func (pe *PathEnd) UpdateClients(dstHeaders []Header, signer sdk.AccAddress) []s
 	return msgs
 }
 
+// UpgradeClient creates a MsgUpgradeClient to upgrade the client on src with the upgraded states of dst
+func (pe *PathEnd) UpgradeClient(upgraded *UpgradedClient, signer sdk.AccAddress) sdk.Msg {
+	msg, err := clienttypes.NewMsgUpgradeClient(
+		pe.ClientID,
+		upgraded.ClientState,
+		upgraded.ConsensusState,
+		upgraded.ProofUpgradeClient,
+		upgraded.ProofUpgradeConsensusState,
+		signer.String(),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return msg
+}
+
 // ConnInit creates a MsgConnectionOpenInit
 func (pe *PathEnd) ConnInit(dst *PathEnd, signer sdk.AccAddress) sdk.Msg {
 	var version *conntypes.Version
@@ -80,7 +102,7 @@ func (pe *PathEnd) ConnInit(dst *PathEnd, signer sdk.AccAddress) sdk.Msg {
 		dst.ClientID,
 		DefaultChainPrefix,
 		version,
-		DefaultDelayPeriod,
+		pe.DelayPeriod,
 		signer.String(),
 	)
 }
@@ -106,7 +128,7 @@ func (pe *PathEnd) ConnTry(
 		cs,
 		DefaultChainPrefix,
 		conntypes.GetCompatibleVersions(),
-		DefaultDelayPeriod,
+		dstConnState.Connection.DelayPeriod,
 		dstConnState.Proof,
 		dstClientState.Proof,
 		dstConsState.Proof,
@@ -169,7 +191,7 @@ func (pe *PathEnd) ConnConfirm(dstConnState *conntypes.QueryConnectionResponse,
 func (pe *PathEnd) ChanInit(dst *PathEnd, signer sdk.AccAddress) sdk.Msg {
 	return chantypes.NewMsgChannelOpenInit(
 		pe.PortID,
-		pe.Version,
+		pe.chanInitVersion(dst),
 		pe.GetOrder(),
 		[]string{pe.ConnectionID},
 		dst.PortID,
@@ -181,7 +203,7 @@ func (pe *PathEnd) ChanInit(dst *PathEnd, signer sdk.AccAddress) sdk.Msg {
 func (pe *PathEnd) ChanTry(dst *PathEnd, dstChanState *chantypes.QueryChannelResponse, signer sdk.AccAddress) sdk.Msg {
 	return chantypes.NewMsgChannelOpenTry(
 		pe.PortID,
-		pe.Version,
+		pe.chanTryVersion(dstChanState.Channel.Version),
 		dstChanState.Channel.Ordering,
 		[]string{pe.ConnectionID},
 		dst.PortID,
@@ -71,3 +71,25 @@ type ChainInfoICS02Querier interface {
 	ChainInfo
 	ICS02Querier
 }
+
+// ClientUpgrader is an optional interface of Prover that supports upgrading the light client on the counterparty chain
+// when the self chain performs a planned upgrade that changes its chain-id or revision.
+type ClientUpgrader interface {
+	// QueryUpgradeHeight returns the height at which the self chain halts for the planned upgrade that upgrades IBC clients.
+	// It returns nil if no such upgrade is scheduled.
+	QueryUpgradeHeight(ctx context.Context) (exported.Height, error)
+
+	// ProveUpgradedClient returns the upgraded client and consensus states committed for the upgrade at `ctx.Height()`
+	// with their proofs verifiable at that height.
+	ProveUpgradedClient(ctx QueryContext) (*UpgradedClient, error)
+}
+
+// UpgradedClient is the states to be submitted to the counterparty chain as MsgUpgradeClient
+type UpgradedClient struct {
+	// Header is the header at the upgrade height, which the client on the counterparty chain needs to be updated to before the upgrade
+	Header                     Header
+	ClientState                exported.ClientState
+	ConsensusState             exported.ConsensusState
+	ProofUpgradeClient         []byte
+	ProofUpgradeConsensusState []byte
+}
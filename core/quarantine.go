@@ -0,0 +1,184 @@
+package core
+
+import (
+	"context"
+	"math"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// QuarantineConfig is an optional interface of ConfigI to save the quarantine in the path config.
+// If the config doesn't implement it, the quarantine is kept only in memory.
+type QuarantineConfig interface {
+	UpdatePathQuarantine(pathName string, chainID string, quarantine []*QuarantinedPacket) error
+}
+
+// QuarantinedPacket is a packet whose msg of `MsgType` made a tx fail on the chain of the path end.
+// The msg is no longer relayed to the chain until it is released from the quarantine.
+type QuarantinedPacket struct {
+	SourcePort    string    `yaml:"source-port" json:"source-port"`
+	SourceChannel string    `yaml:"source-channel" json:"source-channel"`
+	Sequence      uint64    `yaml:"sequence" json:"sequence"`
+	MsgType       string    `yaml:"msg-type" json:"msg-type"`
+	Reason        string    `yaml:"reason" json:"reason"`
+	QuarantinedAt time.Time `yaml:"quarantined-at" json:"quarantined-at"`
+}
+
+// IsQuarantined returns true if the msg of `msgType` for the packet is quarantined on the path end
+func (pe *PathEnd) IsQuarantined(sourcePort, sourceChannel string, seq uint64, msgType string) bool {
+	for _, q := range pe.Quarantine {
+		if q.SourcePort == sourcePort && q.SourceChannel == sourceChannel && q.Sequence == seq && q.MsgType == msgType {
+			return true
+		}
+	}
+	return false
+}
+
+// ReleasePacket removes the packets of sequence `seq` from the quarantine of the path end, and returns the number of the released packets
+func (pe *PathEnd) ReleasePacket(seq uint64) int {
+	var quarantine []*QuarantinedPacket
+	for _, q := range pe.Quarantine {
+		if q.Sequence != seq {
+			quarantine = append(quarantine, q)
+		}
+	}
+	released := len(pe.Quarantine) - len(quarantine)
+	pe.Quarantine = quarantine
+	return released
+}
+
+// nondeterministicErrors are the errors of packet msgs that depend on the state of the client or the time the tx is executed,
+// so that the msgs may succeed when they are relayed again
+var nondeterministicErrors = []error{
+	chantypes.ErrPacketTimeout,
+	clienttypes.ErrClientNotActive,
+	clienttypes.ErrConsensusStateNotFound,
+	sdkerrors.ErrOutOfGas,
+	sdkerrors.ErrInsufficientFee,
+	sdkerrors.ErrWrongSequence,
+}
+
+// isDeterministicAppError returns true if `err` shows that a msg was rejected by the application in CheckTx or DeliverTx,
+// and it would be rejected again whenever it is relayed
+func isDeterministicAppError(err error) bool {
+	if reason := GetTxFailureReason(err); reason != TxFailureReasonCheckTx && reason != TxFailureReasonDeliverTx {
+		return false
+	}
+	// only the failure of a msg handler reports the index of the msg
+	if _, ok := failingMsgIndex(err, math.MaxInt); !ok {
+		return false
+	}
+	for _, e := range nondeterministicErrors {
+		if isChainError(err, e) {
+			return false
+		}
+	}
+	return true
+}
+
+// isTimeoutMsg returns true if `msg` times out a packet, which is never quarantined
+// because the packet can't be relayed in any other way (and an ORDERED channel is blocked until the packet is timed out)
+func isTimeoutMsg(msg sdk.Msg) bool {
+	switch msg.(type) {
+	case *chantypes.MsgTimeout, *chantypes.MsgTimeoutOnClose:
+		return true
+	default:
+		return false
+	}
+}
+
+// filterQuarantined removes the packets from `rp` whose msgs to be submitted are quarantined.
+// The packets in `rp` are received on the counterparty chain if `recv` is true, or their acknowledgements are relayed otherwise.
+// A packet whose MsgRecvPacket is quarantined is kept once it has timed out on the counterparty chain so that it can be timed out.
+func filterQuarantined(ctx context.Context, src, dst *ProvableChain, sh SyncHeaders, rp *RelayPackets, recv bool) error {
+	msgType := sdk.MsgTypeURL(&chantypes.MsgAcknowledgement{})
+	if recv {
+		msgType = sdk.MsgTypeURL(&chantypes.MsgRecvPacket{})
+	}
+	filter := func(packets PacketInfoList, receiver *ProvableChain) (PacketInfoList, error) {
+		var (
+			ret       PacketInfoList
+			queryCtx  QueryContext
+			timestamp time.Time
+		)
+		for _, p := range packets {
+			if !receiver.Path().IsQuarantined(p.SourcePort, p.SourceChannel, p.Sequence, msgType) {
+				ret = append(ret, p)
+				continue
+			}
+			if !recv {
+				continue
+			}
+			// the latest timestamp of the receiver is queried once at the first quarantined packet
+			if queryCtx == nil {
+				queryCtx = sh.GetQueryContext(ctx, receiver.ChainID())
+				var err error
+				if timestamp, err = receiver.Timestamp(ctx, queryCtx.Height()); err != nil {
+					return nil, err
+				}
+			}
+			if packetTimedOut(p.Packet, queryCtx.Height(), timestamp) {
+				ret = append(ret, p)
+			}
+		}
+		return ret, nil
+	}
+	var err error
+	if rp.Src, err = filter(rp.Src, dst); err != nil {
+		return err
+	}
+	if rp.Dst, err = filter(rp.Dst, src); err != nil {
+		return err
+	}
+	return nil
+}
+
+// quarantinePackets quarantines the msgs rejected by `src` or `dst` because of deterministic errors of the application, and saves the quarantine in the path config.
+// The other rejected msgs are relayed again in a later cycle.
+func (srv *RelayService) quarantinePackets(ctx context.Context, msgs *RelayMsgs) {
+	if len(msgs.Rejected) == 0 {
+		return
+	}
+	for _, chain := range []*ProvableChain{srv.src, srv.dst} {
+		logger := GetChannelLogger(chain).WithSpanContext(ctx)
+		quarantine := chain.Path().Quarantine
+		for _, m := range msgs.Rejected {
+			if m.ChainID != chain.ChainID() || isTimeoutMsg(m.Msg) || !isDeterministicAppError(m.Err) {
+				continue
+			}
+			packet, msgType := packetFromMsg(m.Msg), sdk.MsgTypeURL(m.Msg)
+			if chain.Path().IsQuarantined(packet.SourcePort, packet.SourceChannel, packet.Sequence, msgType) {
+				continue
+			}
+			logger.Warn("quarantine the packet", "source_port", packet.SourcePort, "source_channel", packet.SourceChannel, "sequence", packet.Sequence, "msg_type", msgType)
+			quarantine = append(quarantine, &QuarantinedPacket{
+				SourcePort:    packet.SourcePort,
+				SourceChannel: packet.SourceChannel,
+				Sequence:      packet.Sequence,
+				MsgType:       msgType,
+				Reason:        m.Err.Error(),
+				QuarantinedAt: time.Now(),
+			})
+		}
+		if len(quarantine) == len(chain.Path().Quarantine) {
+			continue
+		}
+		chain.Path().Quarantine = quarantine
+		if qc, ok := config.(QuarantineConfig); ok {
+			if err := qc.UpdatePathQuarantine(srv.pathName, chain.ChainID(), quarantine); err != nil {
+				logger.Error("failed to save the quarantine in the path config", err)
+			}
+		}
+		updateQuarantineMetrics(chain)
+	}
+}
+
+func updateQuarantineMetrics(chain *ProvableChain) {
+	metrics.QuarantinedPacketsGauge.Set(int64(len(chain.Path().Quarantine)), attribute.Key("chain_id").String(chain.ChainID()))
+}
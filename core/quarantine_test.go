@@ -0,0 +1,233 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+// isolatingChain is a stateChain that fails the txs for which `fail` returns an error
+type isolatingChain struct {
+	*stateChain
+	fail func(msgs []sdk.Msg) error
+}
+
+func (c *isolatingChain) SendMsgs(ctx context.Context, msgs []sdk.Msg) ([]MsgID, error) {
+	if err := c.fail(msgs); err != nil {
+		return nil, err
+	}
+	return c.stateChain.SendMsgs(ctx, msgs)
+}
+
+func TestSendIsolating(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	recv := func(seq uint64) sdk.Msg {
+		return &chantypes.MsgRecvPacket{Packet: chantypes.Packet{Sequence: seq, SourcePort: "transfer", SourceChannel: "channel-1"}}
+	}
+	isBad := func(msg sdk.Msg) bool {
+		packet := packetFromMsg(msg)
+		return packet != nil && packet.Sequence == 2
+	}
+	isUpdate := func(msg sdk.Msg) bool {
+		_, ok := msg.(*clienttypes.MsgUpdateClient)
+		return ok
+	}
+	// failAt fails the txs including the msg for which `f` returns true with the index of the msg reported
+	failAt := func(f func(sdk.Msg) bool) func([]sdk.Msg) error {
+		return func(msgs []sdk.Msg) error {
+			if i := slices.IndexFunc(msgs, f); i >= 0 {
+				return NewTxError(TxFailureReasonDeliverTx, fmt.Errorf("DeliverTx failed: failed to execute message; message index: %d: packet failed", i))
+			}
+			return nil
+		}
+	}
+	// failWithout fails the txs including the msg for which `f` returns true without the index of the msg
+	failWithout := func(f func(sdk.Msg) bool) func([]sdk.Msg) error {
+		return func(msgs []sdk.Msg) error {
+			if slices.IndexFunc(msgs, f) >= 0 {
+				return NewTxError(TxFailureReasonDeliverTx, fmt.Errorf("DeliverTx failed: out of gas"))
+			}
+			return nil
+		}
+	}
+
+	cases := []struct {
+		name      string
+		fail      func([]sdk.Msg) error
+		succeeded bool
+		sent      []int
+		failed    []int
+		rejected  bool
+	}{
+		{"failing msg index", failAt(isBad), true, []int{4}, []int{2}, true},
+		{"bisect", failWithout(isBad), true, []int{2, 2}, []int{2}, true},
+		{"failing client update", failAt(isUpdate), false, nil, []int{0, 1, 2, 3, 4}, false},
+		{"bisect a failing client update", failWithout(isUpdate), false, nil, []int{0, 1, 2, 3, 4}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, d := newStateChainPair()
+			src := &isolatingChain{stateChain: s, fail: c.fail}
+			msgs := &RelayMsgs{
+				Src: []sdk.Msg{&clienttypes.MsgUpdateClient{ClientId: s.path.ClientID}, recv(1), recv(2), recv(3), recv(4)},
+				Dst: []sdk.Msg{},
+			}
+
+			msgs.Send(context.TODO(), src, d)
+			if msgs.Succeeded != c.succeeded {
+				t.Errorf("unexpected succeeded: %v", msgs.Succeeded)
+			}
+			var sent []int
+			for _, tx := range s.sent {
+				sent = append(sent, len(tx))
+			}
+			if !slices.Equal(sent, c.sent) {
+				t.Errorf("unexpected txs: actual=%v, expected=%v", sent, c.sent)
+			}
+			var failed []int
+			for i, id := range msgs.SrcMsgIDs {
+				if id == nil {
+					failed = append(failed, i)
+				}
+			}
+			if len(msgs.SrcMsgIDs) != len(msgs.Src) || !slices.Equal(failed, c.failed) {
+				t.Errorf("unexpected failed msgs: actual=%v, expected=%v", failed, c.failed)
+			}
+			if rejected := len(msgs.Rejected) > 0; rejected != c.rejected {
+				t.Fatalf("unexpected rejected msgs: %v", msgs.Rejected)
+			} else if rejected && (len(msgs.Rejected) != 1 || !isBad(msgs.Rejected[0].Msg) || msgs.Rejected[0].ChainID != "ibc0") {
+				t.Errorf("unexpected rejected msgs: %v", msgs.Rejected)
+			}
+		})
+	}
+}
+
+func TestIsDeterministicAppError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"app error", NewTxError(TxFailureReasonDeliverTx, fmt.Errorf("failed to execute message; message index: 1: %v", chantypes.ErrInvalidPacket)), true},
+		{"app error in CheckTx", NewTxError(TxFailureReasonCheckTx, fmt.Errorf("failed to execute message; message index: 0: %v", chantypes.ErrInvalidPacket)), true},
+		{"without msg index", NewTxError(TxFailureReasonDeliverTx, fmt.Errorf("DeliverTx failed: %v", sdkerrors.ErrOutOfGas)), false},
+		{"packet timeout", NewTxError(TxFailureReasonDeliverTx, fmt.Errorf("failed to execute message; message index: 1: %v", chantypes.ErrPacketTimeout)), false},
+		{"inactive client", NewTxError(TxFailureReasonDeliverTx, fmt.Errorf("failed to execute message; message index: 1: %v", clienttypes.ErrClientNotActive)), false},
+		{"rpc error", NewTxError(TxFailureReasonRPC, fmt.Errorf("message index: 1: connection refused")), false},
+	}
+	for _, c := range cases {
+		if actual := isDeterministicAppError(c.err); actual != c.expected {
+			t.Errorf("%s: unexpected result: actual=%v, expected=%v", c.name, actual, c.expected)
+		}
+	}
+}
+
+func TestFilterQuarantined(t *testing.T) {
+	s, d := newStateChainPair()
+	packet := func(seq uint64, timeoutHeight uint64) *PacketInfo {
+		return &PacketInfo{Packet: chantypes.Packet{Sequence: seq, SourcePort: "transfer", SourceChannel: "channel-0", TimeoutHeight: clienttypes.NewHeight(0, timeoutHeight)}}
+	}
+	quarantined := func(seq uint64, msg sdk.Msg) *QuarantinedPacket {
+		return &QuarantinedPacket{SourcePort: "transfer", SourceChannel: "channel-0", Sequence: seq, MsgType: sdk.MsgTypeURL(msg)}
+	}
+	// the packets sent from src are received and acknowledged on dst at height 100
+	d.path.Quarantine = []*QuarantinedPacket{
+		quarantined(1, &chantypes.MsgRecvPacket{}),
+		quarantined(2, &chantypes.MsgRecvPacket{}),
+		quarantined(3, &chantypes.MsgAcknowledgement{}),
+	}
+	sh := newStateSyncHeaders(s, d)
+	newPackets := func() *RelayPackets {
+		return &RelayPackets{Src: PacketInfoList{packet(1, 1000), packet(2, 50), packet(3, 1000)}, Dst: PacketInfoList{packet(1, 1000)}}
+	}
+
+	cases := []struct {
+		name        string
+		recv        bool
+		expectedSrc []uint64
+	}{
+		// packet 2 has timed out on dst, so it is kept to be timed out
+		{"recv", true, []uint64{2, 3}},
+		{"ack", false, []uint64{1, 2}},
+	}
+	for _, c := range cases {
+		rp := newPackets()
+		if err := filterQuarantined(context.TODO(), newTestProvableChain(s), newTestProvableChain(d), sh, rp, c.recv); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if seqs := rp.Src.ExtractSequenceList(); !slices.Equal(seqs, c.expectedSrc) {
+			t.Errorf("%s: unexpected packets: actual=%v, expected=%v", c.name, seqs, c.expectedSrc)
+		}
+		// the packets sent from dst are relayed to src, which has nothing quarantined
+		if len(rp.Dst) != 1 {
+			t.Errorf("%s: unexpected packets from dst: %v", c.name, rp.Dst)
+		}
+	}
+}
+
+func TestQuarantinePackets(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	appErr := NewTxError(TxFailureReasonDeliverTx, fmt.Errorf("failed to execute message; message index: 0: %v", chantypes.ErrInvalidPacket))
+	packet := func(seq uint64) chantypes.Packet {
+		return chantypes.Packet{Sequence: seq, SourcePort: "transfer", SourceChannel: "channel-0"}
+	}
+
+	for _, persisted := range []bool{true, false} {
+		t.Run(fmt.Sprintf("persisted=%v", persisted), func(t *testing.T) {
+			s, d := newStateChainPair()
+			cfg := setTestConfig(t, s.path, d.path)
+			if !persisted {
+				// a config without QuarantineConfig keeps the quarantine in memory
+				config = struct{ ConfigI }{cfg}
+			}
+			srv := &RelayService{src: newTestProvableChain(s), dst: newTestProvableChain(d), pathName: "path"}
+			msgs := NewRelayMsgs()
+			msgs.Rejected = []*RejectedMsg{
+				{ChainID: "ibc1", Msg: &chantypes.MsgRecvPacket{Packet: packet(1)}, Err: appErr},
+				{ChainID: "ibc1", Msg: &chantypes.MsgAcknowledgement{Packet: packet(1)}, Err: appErr},
+				{ChainID: "ibc1", Msg: &chantypes.MsgRecvPacket{Packet: packet(2)}, Err: NewTxError(TxFailureReasonDeliverTx, fmt.Errorf("out of gas"))},
+				{ChainID: "ibc0", Msg: &chantypes.MsgTimeout{Packet: packet(3)}, Err: appErr},
+			}
+
+			srv.quarantinePackets(context.TODO(), msgs)
+			if len(s.path.Quarantine) != 0 {
+				t.Errorf("MsgTimeout is quarantined: %v", s.path.Quarantine)
+			}
+			if len(d.path.Quarantine) != 2 {
+				t.Fatalf("unexpected quarantine: %v", d.path.Quarantine)
+			}
+			for _, msg := range []sdk.Msg{&chantypes.MsgRecvPacket{}, &chantypes.MsgAcknowledgement{}} {
+				if !d.path.IsQuarantined("transfer", "channel-0", 1, sdk.MsgTypeURL(msg)) {
+					t.Errorf("%s of packet 1 is not quarantined", sdk.MsgTypeURL(msg))
+				}
+			}
+			if saved := len(cfg.quarantine["ibc1"]) > 0; saved != persisted {
+				t.Errorf("unexpected quarantine in the path config: %v", cfg.quarantine)
+			}
+
+			// the msgs already quarantined are not quarantined again
+			srv.quarantinePackets(context.TODO(), msgs)
+			if len(d.path.Quarantine) != 2 {
+				t.Errorf("unexpected quarantine: %v", d.path.Quarantine)
+			}
+		})
+	}
+}
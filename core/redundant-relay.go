@@ -0,0 +1,130 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+// redundantRelayErrors are the errors returned for packet msgs of which the packets have already been relayed by another relayer
+var redundantRelayErrors = []error{
+	chantypes.ErrRedundantTx,
+	chantypes.ErrNoOpMsg,
+	chantypes.ErrPacketReceived,
+	chantypes.ErrPacketCommitmentNotFound,
+}
+
+// isRedundantRelayError returns true if `err` shows that the packets have already been relayed
+func isRedundantRelayError(err error) bool {
+	for _, e := range redundantRelayErrors {
+		if isChainError(err, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// isChainError returns true if `err` is the registered error `target`.
+// The error message is also checked because the error returned by a chain may not wrap the registered error.
+func isChainError(err, target error) bool {
+	return errors.Is(err, target) || strings.Contains(err.Error(), target.Error())
+}
+
+// RecordRedundantRelays counts the packet msgs in `msgs` as no-ops that were redundant on `chain`
+func RecordRedundantRelays(ctx context.Context, chain ChainInfo, msgs []sdk.Msg) {
+	for _, msg := range msgs {
+		if packetFromMsg(msg) == nil {
+			continue
+		}
+		metrics.RedundantRelaysCounter.Add(ctx, 1, api.WithAttributes(
+			attribute.Key("chain_id").String(chain.ChainID()),
+			attribute.Key("msg_type").String(sdk.MsgTypeURL(msg)),
+		))
+	}
+}
+
+// stripRelayedPackets removes the MsgRecvPacket and MsgAcknowledgement of the packets that have already been relayed at the latest heights,
+// e.g. by another relayer after the unrelayed packets were queried.
+func stripRelayedPackets(ctx context.Context, src, dst *ProvableChain, msgs *RelayMsgs) error {
+	var err error
+	if msgs.Src, err = stripRelayed(ctx, src, dst, msgs.Src); err != nil {
+		return err
+	}
+	if msgs.Dst, err = stripRelayed(ctx, dst, src, msgs.Dst); err != nil {
+		return err
+	}
+	return nil
+}
+
+// stripRelayed removes the packet msgs in `msgs` to be submitted to `chain` of which the packets have already been received or acknowledged.
+// If all the msgs but MsgUpdateClient are removed, the client updates are also removed unless the client on `chain` tracking `counterparty` needs to be refreshed.
+func stripRelayed(ctx context.Context, chain, counterparty *ProvableChain, msgs []sdk.Msg) ([]sdk.Msg, error) {
+	var recvSeqs, ackSeqs []uint64
+	for _, msg := range msgs {
+		switch msg := msg.(type) {
+		case *chantypes.MsgRecvPacket:
+			recvSeqs = append(recvSeqs, msg.Packet.Sequence)
+		case *chantypes.MsgAcknowledgement:
+			ackSeqs = append(ackSeqs, msg.Packet.Sequence)
+		}
+	}
+	if len(recvSeqs) == 0 && len(ackSeqs) == 0 {
+		return msgs, nil
+	}
+
+	queryCtx, err := latestQueryContext(ctx, chain)
+	if err != nil {
+		return nil, err
+	}
+	var unreceived, unacked []uint64
+	if len(recvSeqs) > 0 {
+		if unreceived, err = chain.QueryUnreceivedPackets(queryCtx, recvSeqs); err != nil {
+			return nil, err
+		}
+	}
+	if len(ackSeqs) > 0 {
+		if unacked, err = chain.QueryUnreceivedAcknowledgements(queryCtx, ackSeqs); err != nil {
+			return nil, err
+		}
+	}
+
+	ret := slices.DeleteFunc(slices.Clone(msgs), func(msg sdk.Msg) bool {
+		switch msg := msg.(type) {
+		case *chantypes.MsgRecvPacket:
+			return !slices.Contains(unreceived, msg.Packet.Sequence)
+		case *chantypes.MsgAcknowledgement:
+			return !slices.Contains(unacked, msg.Packet.Sequence)
+		default:
+			return false
+		}
+	})
+	stripped := len(msgs) - len(ret)
+	if stripped == 0 {
+		return ret, nil
+	}
+	logger := GetChannelLogger(chain).WithSpanContext(ctx)
+	logger.Info("strip the msgs of packets already relayed by another relayer", "num_msgs", stripped)
+
+	if len(ret) == 0 || slices.ContainsFunc(ret, func(msg sdk.Msg) bool {
+		_, ok := msg.(*clienttypes.MsgUpdateClient)
+		return !ok
+	}) {
+		return ret, nil
+	}
+	if refresh, err := counterparty.CheckRefreshRequired(ctx, chain); err != nil {
+		return nil, fmt.Errorf("failed to check if the LC on chain %s needs to be refreshed: %v", chain.ChainID(), err)
+	} else if refresh {
+		return ret, nil
+	}
+	logger.Info("drop the client updates for the stripped msgs", "num_msgs", len(ret))
+	return ret[:0], nil
+}
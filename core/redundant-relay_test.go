@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/log"
+)
+
+// relayedChain is a stateChain on which the packets of `relayed` have already been received and acknowledged
+type relayedChain struct {
+	*stateChain
+	relayed []uint64
+}
+
+func (c *relayedChain) QueryUnreceivedPackets(ctx QueryContext, seqs []uint64) ([]uint64, error) {
+	return slices.DeleteFunc(slices.Clone(seqs), func(seq uint64) bool { return slices.Contains(c.relayed, seq) }), nil
+}
+
+func (c *relayedChain) QueryUnreceivedAcknowledgements(ctx QueryContext, seqs []uint64) ([]uint64, error) {
+	return c.QueryUnreceivedPackets(ctx, seqs)
+}
+
+// refreshProver is a stateProver that reports whether the client on the counterparty needs to be refreshed
+type refreshProver struct {
+	stateProver
+	refresh bool
+}
+
+func (p refreshProver) CheckRefreshRequired(ctx context.Context, counterparty ChainInfoICS02Querier) (bool, error) {
+	return p.refresh, nil
+}
+
+func TestStripRelayed(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	update := &clienttypes.MsgUpdateClient{ClientId: "07-tendermint-1"}
+	recv := &chantypes.MsgRecvPacket{Packet: chantypes.Packet{Sequence: 1}}
+	ack := &chantypes.MsgAcknowledgement{Packet: chantypes.Packet{Sequence: 2}}
+
+	cases := []struct {
+		name     string
+		msgs     []sdk.Msg
+		relayed  []uint64
+		refresh  bool
+		expected []sdk.Msg
+	}{
+		{"nothing relayed", []sdk.Msg{update, recv, ack}, nil, false, []sdk.Msg{update, recv, ack}},
+		{"partially relayed", []sdk.Msg{update, recv, ack}, []uint64{1}, false, []sdk.Msg{update, ack}},
+		{"all relayed", []sdk.Msg{update, recv, ack}, []uint64{1, 2}, false, []sdk.Msg{}},
+		{"all relayed with the client to be refreshed", []sdk.Msg{update, recv, ack}, []uint64{1, 2}, true, []sdk.Msg{update}},
+		{"client update only", []sdk.Msg{update}, []uint64{1}, false, []sdk.Msg{update}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, d := newStateChainPair()
+			chain := newTestProvableChain(&relayedChain{stateChain: d, relayed: c.relayed})
+			counterparty := NewProvableChain(s, refreshProver{refresh: c.refresh})
+
+			ret, err := stripRelayed(context.TODO(), chain, counterparty, c.msgs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(ret, c.expected) {
+				t.Errorf("unexpected msgs: actual=%v, expected=%v", ret, c.expected)
+			}
+		})
+	}
+}
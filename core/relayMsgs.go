@@ -3,26 +3,55 @@ package core
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/gogoproto/proto"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
 )
 
 // RelayMsgs contains the msgs that need to be sent to both a src and dst chain
-// after a given relay round. MaxTxSize and MaxMsgLength are ignored if they are
+// after a given relay round. MaxTxSize, MaxMsgLength and MaxGas are ignored if they are
 // set to zero.
 type RelayMsgs struct {
 	Src          []sdk.Msg `json:"src"`
 	Dst          []sdk.Msg `json:"dst"`
 	MaxTxSize    uint64    `json:"max_tx_size"`    // maximum permitted size of the msgs in a bundled relay transaction
 	MaxMsgLength uint64    `json:"max_msg_length"` // maximum amount of messages in a bundled relay transaction
+	MaxGas       uint64    `json:"max_gas"`        // maximum gas estimated for a bundled relay transaction
 
 	Last      bool `json:"last"`
 	Succeeded bool `json:"success"`
 
 	SrcMsgIDs []MsgID `json:"src_msg_ids"`
 	DstMsgIDs []MsgID `json:"dst_msg_ids"`
+
+	// Rejected are the msgs that made their txs fail and were excluded from the retried txs
+	Rejected []*RejectedMsg `json:"-"`
+
+	// mu guards Succeeded and Rejected while the msgs are sent to src and dst concurrently
+	mu sync.Mutex
+}
+
+// RejectedMsg is a msg that made the tx including it fail
+type RejectedMsg struct {
+	ChainID string
+	Msg     sdk.Msg
+	Err     error
+}
+
+// GasEstimator is an optional interface of Chain required to bundle msgs by gas
+type GasEstimator interface {
+	// EstimateGas simulates a tx including `msgs` and returns the gas it would consume
+	EstimateGas(ctx context.Context, msgs []sdk.Msg) (uint64, error)
 }
 
 // NewRelayMsgs returns an initialized version of relay messages
@@ -45,145 +74,329 @@ func (r *RelayMsgs) IsMaxTx(msgLen, txSize uint64) bool {
 		(r.MaxTxSize != 0 && txSize > r.MaxTxSize)
 }
 
-// Send sends the messages with appropriate output
-// TODO: Parallelize? Maybe?
+// Send sends the messages with appropriate output.
+// The msgs to src and dst are sent concurrently, while the txs to each chain are sent in order.
 func (r *RelayMsgs) Send(ctx context.Context, src, dst Chain) {
-	logger := GetChannelPairLogger(src, dst)
-	//nolint:prealloc // can not be pre allocated
-	var (
-		msgLen, txSize uint64
-		msgs           []sdk.Msg
-	)
+	logger := GetChannelPairLogger(src, dst).WithSpanContext(ctx)
 
 	r.Succeeded = true
+	r.Rejected = nil
+	if src.ChainID() == dst.ChainID() {
+		// the txs to the same chain are sent in order so as not to conflict with each other (e.g. in the account sequence)
+		r.SrcMsgIDs = r.send(ctx, logger, src, r.Src, "src")
+		r.DstMsgIDs = r.send(ctx, logger, dst, r.Dst, "dst")
+		return
+	}
 
-	srcMsgIDs := make([]MsgID, len(r.Src))
-	dstMsgIDs := make([]MsgID, len(r.Dst))
-	// submit batches of relay transactions
-	maxTxCount := 0
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		r.SrcMsgIDs = r.send(ctx, logger, src, r.Src, "src")
+	}()
+	go func() {
+		defer wg.Done()
+		r.DstMsgIDs = r.send(ctx, logger, dst, r.Dst, "dst")
+	}()
+	wg.Wait()
+}
 
-	for _, msg := range r.Src {
-		bz, err := proto.Marshal(msg)
+// fail marks the relay as failed
+func (r *RelayMsgs) fail() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Succeeded = false
+}
+
+// send submits `msgs` to `chain` in batches, and returns the ids of the msgs, which are nil for the msgs failed to be sent.
+// Each batch is built after the previous one is sent so that the gas of a batch is estimated on the state updated by the previous ones.
+func (r *RelayMsgs) send(ctx context.Context, logger *log.RelayLogger, chain Chain, msgs []sdk.Msg, side string) []MsgID {
+	msgIDs := make([]MsgID, len(msgs))
+	estimator := gasEstimator(chain)
+	for offset := 0; offset < len(msgs); {
+		n, err := r.nextBatchLen(ctx, estimator, msgs[offset:])
 		if err != nil {
-			logger.Error("failed to marshal msg", err)
+			logger.Error("failed to build a batch of msgs", err, "side", side)
 			panic(err)
 		}
+		copy(msgIDs[offset:], r.sendIsolating(ctx, logger, chain, msgs[offset:offset+n], side))
+		offset += n
+	}
+	return msgIDs
+}
 
-		msgLen++
-		txSize += uint64(len(bz))
+// sendIsolating sends `msgs` in a tx, and returns the ids of the msgs, which are nil for the msgs failed to be sent.
+// If the tx fails because of a msg for a packet, the msg is rejected and the other msgs are retried without it.
+// The failing msg is identified by the msg index reported in the error, or otherwise by bisecting `msgs` if the tx failed in DeliverTx.
+func (r *RelayMsgs) sendIsolating(ctx context.Context, logger *log.RelayLogger, chain Chain, msgs []sdk.Msg, side string) []MsgID {
+	ids, err := r.sendBatch(ctx, logger, chain, msgs, side)
+	if err == nil {
+		return ids
+	}
+	ids = make([]MsgID, len(msgs))
 
-		if r.IsMaxTx(msgLen, txSize) {
-			logger := &log.RelayLogger{Logger: logger.With(
-				"msgs", msgsToLoggable(msgs),
-				"side", "src",
-			)}
+	if isRedundantRelayError(err) {
+		// the packets have already been relayed by another relayer, which is not an error of the relay
+		if i, ok := failingMsgIndex(err, len(msgs)); ok {
+			RecordRedundantRelays(ctx, chain, msgs[i:i+1])
+			return r.sendWithout(ctx, logger, chain, msgs, i, side)
+		}
+		if isChainError(err, chantypes.ErrRedundantTx) || len(msgs) == 1 {
+			RecordRedundantRelays(ctx, chain, msgs)
+			return ids
+		}
+	}
 
-			// Submit the transactions to src chain and update its status
-			msgIDs, err := src.SendMsgs(ctx, msgs)
-			if err != nil {
-				logger.Error("failed to send msgs", err)
-			} else {
-				logger.Info("successfully sent msgs")
-			}
-			r.Succeeded = r.Succeeded && (err == nil)
-			if err == nil {
-				for i := range msgs {
-					srcMsgIDs[i+maxTxCount] = msgIDs[i]
-				}
-			}
-			// clear the current batch and reset variables
-			maxTxCount += len(msgs)
-			msgLen, txSize = 1, uint64(len(bz))
-			msgs = []sdk.Msg{}
+	if i, ok := failingMsgIndex(err, len(msgs)); ok {
+		if packetFromMsg(msgs[i]) == nil {
+			// the following msgs may rely on the failed msg (e.g. MsgUpdateClient)
+			r.fail()
+			return ids
 		}
-		msgs = append(msgs, msg)
+		r.reject(ctx, chain, msgs[i], err)
+		return r.sendWithout(ctx, logger, chain, msgs, i, side)
 	}
 
-	// submit leftover msgs
-	if len(msgs) > 0 {
-		logger := &log.RelayLogger{Logger: logger.With(
-			"msgs", msgsToLoggable(msgs),
-			"side", "src",
-		)}
+	if GetTxFailureReason(err) != TxFailureReasonDeliverTx {
+		r.fail()
+		return ids
+	}
+	mid := bisectIndex(msgs)
+	if mid == 0 {
+		// a single msg without any client update, which can be blamed for the failure
+		if len(msgs) == 1 && packetFromMsg(msgs[0]) != nil {
+			r.reject(ctx, chain, msgs[0], err)
+		} else {
+			r.fail()
+		}
+		return ids
+	}
+	copy(ids, r.sendIsolating(ctx, logger, chain, msgs[:mid], side))
+	for i, msg := range msgs[:mid] {
+		if _, ok := msg.(*clienttypes.MsgUpdateClient); ok && ids[i] == nil {
+			// the latter msgs rely on the failed client update
+			r.fail()
+			return ids
+		}
+	}
+	copy(ids[mid:], r.sendIsolating(ctx, logger, chain, msgs[mid:], side))
+	return ids
+}
 
-		msgIDs, err := src.SendMsgs(ctx, msgs)
-		if err != nil {
-			logger.Error("failed to send msgs", err)
+// sendWithout sends `msgs` except the i-th msg, and returns the ids of `msgs`, in which the i-th one is nil
+func (r *RelayMsgs) sendWithout(ctx context.Context, logger *log.RelayLogger, chain Chain, msgs []sdk.Msg, i int, side string) []MsgID {
+	ids := make([]MsgID, len(msgs))
+	rest := slices.Delete(slices.Clone(msgs), i, i+1)
+	if len(rest) > 0 {
+		restIDs := r.sendIsolating(ctx, logger, chain, rest, side)
+		copy(ids[:i], restIDs[:i])
+		copy(ids[i+1:], restIDs[i:])
+	}
+	return ids
+}
+
+// sendBatch sends `msgs` in a tx and records the result
+func (r *RelayMsgs) sendBatch(ctx context.Context, logger *log.RelayLogger, chain Chain, msgs []sdk.Msg, side string) ([]MsgID, error) {
+	logger = &log.RelayLogger{Logger: logger.With(
+		"msgs", msgsToLoggable(msgs),
+		"side", side,
+	)}
+
+	// Submit the transaction to the chain and update its status
+	msgIDs, err := chain.SendMsgs(ctx, msgs)
+	if err != nil {
+		updateTxMetrics(ctx, chain, msgs, err)
+		if isRedundantRelayError(err) {
+			logger.Info("msgs are redundant because of packets already relayed", "error", err.Error())
 		} else {
-			logger.Info("successfully sent msgs")
+			logger.Error("failed to send msgs", err)
 		}
-		r.Succeeded = r.Succeeded && (err == nil)
-		if err == nil {
-			for i := range msgs {
-				srcMsgIDs[i+maxTxCount] = msgIDs[i]
-			}
+		return nil, err
+	}
+
+	// the msgs deferred by the submission limits have nil ids, which are relayed in a later cycle
+	var sent []sdk.Msg
+	for i, id := range msgIDs {
+		if id != nil {
+			sent = append(sent, msgs[i])
 		}
 	}
+	if len(sent) > 0 {
+		updateTxMetrics(ctx, chain, sent, nil)
+	}
+	if deferred := len(msgs) - len(sent); deferred > 0 {
+		logger.Warn("msgs are deferred by the submission limits", "num_deferred", deferred, "num_sent", len(sent))
+		r.fail()
+		return msgIDs, nil
+	}
+	logger.Info("successfully sent msgs")
+	return msgIDs, nil
+}
+
+func (r *RelayMsgs) reject(ctx context.Context, chain Chain, msg sdk.Msg, err error) {
+	packet := packetFromMsg(msg)
+	GetChannelLogger(chain).WithSpanContext(ctx).Warn("reject the msg that made the tx fail",
+		"msg_type", sdk.MsgTypeURL(msg),
+		"source_port", packet.SourcePort,
+		"source_channel", packet.SourceChannel,
+		"sequence", packet.Sequence,
+		"error", err.Error(),
+	)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Rejected = append(r.Rejected, &RejectedMsg{ChainID: chain.ChainID(), Msg: msg, Err: err})
+}
 
-	// reset variables
-	msgLen, txSize = 0, 0
-	msgs = []sdk.Msg{}
-	maxTxCount = 0
+// failingMsgIndexRegexp matches the index of the failed msg reported by the cosmos-sdk (e.g. "failed to execute message; message index: 1: ...")
+var failingMsgIndexRegexp = regexp.MustCompile(`message index: (\d+)`)
 
-	for _, msg := range r.Dst {
-		bz, err := proto.Marshal(msg)
-		if err != nil {
-			logger.Error("failed to marshal msg", err)
-			panic(err)
+// failingMsgIndex returns the index of the msg that made the tx fail if it is reported in `err`
+func failingMsgIndex(err error, numMsgs int) (int, bool) {
+	m := failingMsgIndexRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0, false
+	}
+	i, convErr := strconv.Atoi(m[1])
+	if convErr != nil || i >= numMsgs {
+		return 0, false
+	}
+	return i, true
+}
+
+// bisectIndex returns the index around the middle of `msgs` at which they are split without separating a MsgUpdateClient from the msg following it.
+// It returns zero if they can't be split.
+func bisectIndex(msgs []sdk.Msg) int {
+	mid := 0
+	for i := 1; i < len(msgs); i++ {
+		if _, ok := msgs[i-1].(*clienttypes.MsgUpdateClient); ok {
+			continue
+		}
+		if mid == 0 || absDiff(2*i, len(msgs)) < absDiff(2*mid, len(msgs)) {
+			mid = i
 		}
+	}
+	return mid
+}
+
+func absDiff(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
 
-		msgLen++
-		txSize += uint64(len(bz))
+// packetFromMsg returns the packet that `msg` processes, or nil if `msg` is not a packet msg
+func packetFromMsg(msg sdk.Msg) *chantypes.Packet {
+	switch msg := msg.(type) {
+	case *chantypes.MsgRecvPacket:
+		return &msg.Packet
+	case *chantypes.MsgAcknowledgement:
+		return &msg.Packet
+	case *chantypes.MsgTimeout:
+		return &msg.Packet
+	case *chantypes.MsgTimeoutOnClose:
+		return &msg.Packet
+	default:
+		return nil
+	}
+}
 
-		if r.IsMaxTx(msgLen, txSize) {
-			logger := &log.RelayLogger{Logger: logger.With(
-				"msgs", msgsToLoggable(msgs),
-				"side", "dst",
-			)}
+// gasEstimator returns the GasEstimator implemented by `chain`, which is unwrapped if it is a ProvableChain, or nil if it is not implemented
+func gasEstimator(chain Chain) GasEstimator {
+	if pc, ok := chain.(*ProvableChain); ok {
+		chain = pc.Chain
+	}
+	estimator, _ := chain.(GasEstimator)
+	return estimator
+}
 
-			// Submit the transaction to dst chain and update its status
-			msgIDs, err := dst.SendMsgs(ctx, msgs)
+// nextBatchLen returns the number of the leading msgs of `msgs` to be bundled in a tx within the limits.
+// A MsgUpdateClient is never separated from the msg following it, which relies on the client update.
+// If `estimator` is not nil and MaxGas is set, the candidate batch is grown by doubling and then bisected
+// so that its gas is kept within MaxGas with O(log n) simulations.
+// A batch includes at least one msg (with the preceding MsgUpdateClients) even if it exceeds the limits.
+func (r *RelayMsgs) nextBatchLen(ctx context.Context, estimator GasEstimator, msgs []sdk.Msg) (int, error) {
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+	// ends are the candidate lengths of the batch within MaxMsgLength and MaxTxSize
+	var (
+		msgLen, txSize uint64
+		ends           []int
+	)
+	for end := 0; end < len(msgs); {
+		next := end
+		for next < len(msgs) {
+			bz, err := proto.Marshal(msgs[next])
 			if err != nil {
-				logger.Error("failed to send msgs", err)
-			} else {
-				logger.Info("successfully sent msgs")
+				return 0, fmt.Errorf("failed to marshal msg: %v", err)
 			}
-			r.Succeeded = r.Succeeded && (err == nil)
-			if err == nil {
-				for i := range msgs {
-					dstMsgIDs[i+maxTxCount] = msgIDs[i]
-				}
+			msgLen++
+			txSize += uint64(len(bz))
+			_, isUpdate := msgs[next].(*clienttypes.MsgUpdateClient)
+			next++
+			if !isUpdate {
+				break
 			}
-			// clear the current batch and reset variables
-			maxTxCount += len(msgs)
-			msgLen, txSize = 1, uint64(len(bz))
-			msgs = []sdk.Msg{}
 		}
-		msgs = append(msgs, msg)
+		if end > 0 && r.IsMaxTx(msgLen, txSize) {
+			break
+		}
+		ends = append(ends, next)
+		end = next
+	}
+	if estimator == nil || r.MaxGas == 0 {
+		return ends[len(ends)-1], nil
 	}
 
-	// submit leftover msgs
-	if len(msgs) > 0 {
-		logger := &log.RelayLogger{Logger: logger.With(
-			"msgs", msgsToLoggable(msgs),
-			"side", "dst",
-		)}
-
-		msgIDs, err := dst.SendMsgs(ctx, msgs)
-		if err != nil {
-			logger.Error("failed to send msgs", err)
+	// a candidate batch that fails to be simulated is not bundled, so as not to make the preceding msgs fail with it
+	withinGas := func(i int) bool {
+		gas, err := estimator.EstimateGas(ctx, msgs[:ends[i]])
+		return err == nil && gas <= r.MaxGas
+	}
+	// ends[lo] is bundled, and ends[hi] exceeds MaxGas unless hi is len(ends)
+	lo, hi := 0, len(ends)
+	for i := 1; i < len(ends); i *= 2 {
+		if !withinGas(i) {
+			hi = i
+			break
+		}
+		lo = i
+	}
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		if withinGas(mid) {
+			lo = mid
 		} else {
-			logger.Info("successfully sent msgs")
+			hi = mid
+		}
+	}
+	return ends[lo], nil
+}
+
+// updateTxMetrics counts the tx that includes `msgs` for each msg type according to the result of `SendMsgs`.
+// A tx rejected as a redundant relay is counted as submitted but not failed.
+func updateTxMetrics(ctx context.Context, chain ChainInfo, msgs []sdk.Msg, err error) {
+	var msgTypes []string
+	for _, msg := range msgs {
+		if msgType := sdk.MsgTypeURL(msg); !slices.Contains(msgTypes, msgType) {
+			msgTypes = append(msgTypes, msgType)
 		}
-		r.Succeeded = r.Succeeded && (err == nil)
+	}
+
+	for _, msgType := range msgTypes {
+		attrs := []attribute.KeyValue{
+			attribute.Key("chain_id").String(chain.ChainID()),
+			attribute.Key("msg_type").String(msgType),
+		}
+		metrics.TxsSubmittedCounter.Add(ctx, 1, api.WithAttributes(attrs...))
 		if err == nil {
-			for i := range msgs {
-				dstMsgIDs[i+maxTxCount] = msgIDs[i]
-			}
+			metrics.TxsSucceededCounter.Add(ctx, 1, api.WithAttributes(attrs...))
+		} else if !isRedundantRelayError(err) {
+			attrs = append(attrs, attribute.Key("reason").String(string(GetTxFailureReason(err))))
+			metrics.TxsFailedCounter.Add(ctx, 1, api.WithAttributes(attrs...))
 		}
 	}
-	r.SrcMsgIDs = srcMsgIDs
-	r.DstMsgIDs = dstMsgIDs
 }
 
 func msgsToLoggable(msgs []sdk.Msg) []string {
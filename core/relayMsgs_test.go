@@ -0,0 +1,345 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// newTestMeter returns a meter whose instruments are collected by the returned reader
+func newTestMeter() (api.Meter, *sdkmetric.ManualReader) {
+	reader := sdkmetric.NewManualReader()
+	return sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"), reader
+}
+
+// counterValue returns the value of the counter `name` with exactly `attrs` collected by `reader`
+func counterValue(t *testing.T, reader *sdkmetric.ManualReader, name string, attrs ...attribute.KeyValue) int64 {
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.TODO(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	set := attribute.NewSet(attrs...)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if m.Name != name || !ok {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				if dp.Attributes.Equals(&set) {
+					return dp.Value
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// gasPerMsg estimates the gas of msgs as 100 per msg
+type gasPerMsg struct{}
+
+func (gasPerMsg) EstimateGas(ctx context.Context, msgs []sdk.Msg) (uint64, error) {
+	return uint64(len(msgs)) * 100, nil
+}
+
+func TestNextBatchLen(t *testing.T) {
+	update := func() sdk.Msg { return &clienttypes.MsgUpdateClient{ClientId: "07-tendermint-0"} }
+	recv := func() sdk.Msg { return &chantypes.MsgRecvPacket{} }
+
+	cases := []struct {
+		name      string
+		msgs      *RelayMsgs
+		estimator GasEstimator
+		input     []sdk.Msg
+		expected  []int
+	}{
+		{"no limit", &RelayMsgs{}, nil, []sdk.Msg{update(), recv(), recv(), recv()}, []int{4}},
+		{"msg length", &RelayMsgs{MaxMsgLength: 2}, nil, []sdk.Msg{recv(), recv(), recv()}, []int{2, 1}},
+		{"update client with the next msg", &RelayMsgs{MaxMsgLength: 1}, nil, []sdk.Msg{update(), recv(), recv()}, []int{2, 1}},
+		{"update client in the middle", &RelayMsgs{MaxMsgLength: 2}, nil, []sdk.Msg{recv(), update(), recv(), recv()}, []int{1, 2, 1}},
+		{"gas", &RelayMsgs{MaxGas: 300}, gasPerMsg{}, []sdk.Msg{update(), recv(), recv(), update(), recv(), recv()}, []int{3, 3}},
+		{"gas without estimator", &RelayMsgs{MaxGas: 300}, nil, []sdk.Msg{update(), recv(), recv(), recv()}, []int{4}},
+	}
+	for _, c := range cases {
+		var actual []int
+		for offset := 0; offset < len(c.input); {
+			n, err := c.msgs.nextBatchLen(context.TODO(), c.estimator, c.input[offset:])
+			if err != nil {
+				t.Fatalf("%s: %v", c.name, err)
+			} else if n == 0 {
+				t.Fatalf("%s: empty batch", c.name)
+			}
+			actual = append(actual, n)
+			offset += n
+		}
+		if len(actual) != len(c.expected) {
+			t.Errorf("%s: unexpected batches: actual=%v, expected=%v", c.name, actual, c.expected)
+			continue
+		}
+		for i := range actual {
+			if actual[i] != c.expected[i] {
+				t.Errorf("%s: unexpected batches: actual=%v, expected=%v", c.name, actual, c.expected)
+				break
+			}
+		}
+	}
+}
+
+// countingEstimator estimates the gas of msgs as 100 per msg and counts the simulations
+type countingEstimator struct {
+	simulated int
+}
+
+func (e *countingEstimator) EstimateGas(ctx context.Context, msgs []sdk.Msg) (uint64, error) {
+	e.simulated++
+	return uint64(len(msgs)) * 100, nil
+}
+
+func TestNextBatchLenSimulations(t *testing.T) {
+	msgs := make([]sdk.Msg, 64)
+	for i := range msgs {
+		msgs[i] = &chantypes.MsgRecvPacket{}
+	}
+	cases := []struct {
+		name     string
+		maxGas   uint64
+		expected int
+	}{
+		{"first msg only", 100, 1},
+		{"middle", 3700, 37},
+		{"all", 6400, 64},
+	}
+	for _, c := range cases {
+		estimator := &countingEstimator{}
+		n, err := (&RelayMsgs{MaxGas: c.maxGas}).nextBatchLen(context.TODO(), estimator, msgs)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if n != c.expected {
+			t.Errorf("%s: unexpected batch: actual=%d, expected=%d", c.name, n, c.expected)
+		}
+		// the batch is grown by doubling and then bisected
+		if estimator.simulated > 13 {
+			t.Errorf("%s: too many simulations: %d", c.name, estimator.simulated)
+		}
+	}
+}
+
+// gasChain is a stateChain that estimates the gas of msgs as 100 per msg
+type gasChain struct {
+	*stateChain
+	gasPerMsg
+}
+
+func TestSendWithGasEstimator(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	s, d := newStateChainPair()
+	recv := func() sdk.Msg { return &chantypes.MsgRecvPacket{} }
+	msgs := &RelayMsgs{
+		Src:    []sdk.Msg{recv(), recv(), recv()},
+		Dst:    []sdk.Msg{},
+		MaxGas: 200,
+	}
+
+	// the GasEstimator is found through the ProvableChain wrapping the chain
+	msgs.Send(context.TODO(), newTestProvableChain(&gasChain{stateChain: s}), newTestProvableChain(&gasChain{stateChain: d}))
+	if !msgs.Succeeded {
+		t.Fatal("failed to send msgs")
+	}
+	if len(s.sent) != 2 || len(s.sent[0]) != 2 || len(s.sent[1]) != 1 {
+		t.Errorf("unexpected txs: %v", sentMsgTypes(s))
+	}
+}
+
+func TestFailingMsgIndex(t *testing.T) {
+	cases := []struct {
+		name  string
+		err   error
+		index int
+		ok    bool
+	}{
+		{"reported", fmt.Errorf("rpc error: code = Unknown desc = failed to execute message; message index: 2: packet receive failed"), 2, true},
+		{"out of range", fmt.Errorf("failed to execute message; message index: 3: packet receive failed"), 0, false},
+		{"not reported", fmt.Errorf("account sequence mismatch"), 0, false},
+	}
+	for _, c := range cases {
+		index, ok := failingMsgIndex(c.err, 3)
+		if index != c.index || ok != c.ok {
+			t.Errorf("%s: unexpected result: actual=(%d, %v), expected=(%d, %v)", c.name, index, ok, c.index, c.ok)
+		}
+	}
+}
+
+func TestBisectIndex(t *testing.T) {
+	update := func() sdk.Msg { return &clienttypes.MsgUpdateClient{ClientId: "07-tendermint-0"} }
+	recv := func() sdk.Msg { return &chantypes.MsgRecvPacket{} }
+
+	cases := []struct {
+		name     string
+		msgs     []sdk.Msg
+		expected int
+	}{
+		{"single msg", []sdk.Msg{recv()}, 0},
+		{"update client with a msg", []sdk.Msg{update(), recv()}, 0},
+		{"even", []sdk.Msg{recv(), recv(), recv(), recv()}, 2},
+		{"update client at the middle", []sdk.Msg{recv(), update(), recv(), recv()}, 1},
+		{"leading update client", []sdk.Msg{update(), recv(), recv(), recv()}, 2},
+	}
+	for _, c := range cases {
+		if actual := bisectIndex(c.msgs); actual != c.expected {
+			t.Errorf("%s: unexpected index: actual=%d, expected=%d", c.name, actual, c.expected)
+		}
+	}
+}
+
+func TestIsRedundantRelayError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"redundant tx", chantypes.ErrRedundantTx, true},
+		{"redundant tx in CheckTx", fmt.Errorf("CheckTx failed: %v", chantypes.ErrRedundantTx), true},
+		{"packet already received", fmt.Errorf("failed to execute message; message index: 1: %v", chantypes.ErrPacketReceived), true},
+		{"other error", fmt.Errorf("failed to execute message; message index: 1: %v", chantypes.ErrInvalidPacket), false},
+	}
+	for _, c := range cases {
+		if actual := isRedundantRelayError(c.err); actual != c.expected {
+			t.Errorf("%s: unexpected result: actual=%v, expected=%v", c.name, actual, c.expected)
+		}
+	}
+}
+
+// barrierChain is a Chain whose SendMsgs waits until SendMsgs of the other chains sharing `barrier` are called
+type barrierChain struct {
+	Chain
+	chainID string
+	barrier *sync.WaitGroup
+	sent    []int
+}
+
+func (c *barrierChain) ChainID() string { return c.chainID }
+
+func (c *barrierChain) Path() *PathEnd { return &PathEnd{ChainID: c.chainID} }
+
+func (c *barrierChain) SendMsgs(ctx context.Context, msgs []sdk.Msg) ([]MsgID, error) {
+	if len(c.sent) == 0 {
+		c.barrier.Done()
+		c.barrier.Wait()
+	}
+	c.sent = append(c.sent, len(msgs))
+	ids := make([]MsgID, len(msgs))
+	for i, msg := range msgs {
+		ids[i] = &testMsgID{typeURL: sdk.MsgTypeURL(msg)}
+	}
+	return ids, nil
+}
+
+func TestSendConcurrently(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	recv := func() sdk.Msg { return &chantypes.MsgRecvPacket{} }
+
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+	src := &barrierChain{chainID: "ibc0", barrier: &barrier}
+	dst := &barrierChain{chainID: "ibc1", barrier: &barrier}
+	msgs := &RelayMsgs{
+		Src:          []sdk.Msg{recv(), recv(), recv()},
+		Dst:          []sdk.Msg{recv()},
+		MaxMsgLength: 2,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		msgs.Send(context.TODO(), src, dst)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the msgs to src and dst are expected to be sent concurrently")
+	}
+	if !msgs.Succeeded || len(msgs.SrcMsgIDs) != 3 || len(msgs.DstMsgIDs) != 1 {
+		t.Errorf("unexpected result: succeeded=%v, src=%d, dst=%d", msgs.Succeeded, len(msgs.SrcMsgIDs), len(msgs.DstMsgIDs))
+	}
+	if len(src.sent) != 2 || src.sent[0] != 2 || src.sent[1] != 1 {
+		t.Errorf("the txs to src are expected to be sent in order: %v", src.sent)
+	}
+}
+
+func TestGetTxFailureReason(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected TxFailureReason
+	}{
+		{"tx error", NewTxError(TxFailureReasonCheckTx, fmt.Errorf("insufficient fees")), TxFailureReasonCheckTx},
+		{"wrapped tx error", fmt.Errorf("failed to send msgs: %w", NewTxError(TxFailureReasonRPC, fmt.Errorf("connection refused"))), TxFailureReasonRPC},
+		{"other error", fmt.Errorf("connection refused"), TxFailureReasonUnknown},
+	}
+	for _, c := range cases {
+		if actual := GetTxFailureReason(c.err); actual != c.expected {
+			t.Errorf("%s: unexpected reason: actual=%s, expected=%s", c.name, actual, c.expected)
+		}
+	}
+}
+
+func TestUpdateTxMetrics(t *testing.T) {
+	meter, reader := newTestMeter()
+	metrics.TxsSubmittedCounter, _ = meter.Int64Counter("relayer.txs_submitted")
+	metrics.TxsSucceededCounter, _ = meter.Int64Counter("relayer.txs_succeeded")
+	metrics.TxsFailedCounter, _ = meter.Int64Counter("relayer.txs_failed")
+	t.Cleanup(func() {
+		if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	chain := &barrierChain{chainID: "ibc0"}
+	chainID := attribute.Key("chain_id").String("ibc0")
+	updateType := attribute.Key("msg_type").String(sdk.MsgTypeURL(&clienttypes.MsgUpdateClient{}))
+	recvType := attribute.Key("msg_type").String(sdk.MsgTypeURL(&chantypes.MsgRecvPacket{}))
+	msgs := []sdk.Msg{&clienttypes.MsgUpdateClient{}, &chantypes.MsgRecvPacket{}, &chantypes.MsgRecvPacket{}}
+
+	updateTxMetrics(context.TODO(), chain, msgs, nil)
+	updateTxMetrics(context.TODO(), chain, msgs, NewTxError(TxFailureReasonDeliverTx, fmt.Errorf("packet receive failed")))
+	updateTxMetrics(context.TODO(), chain, msgs, chantypes.ErrRedundantTx)
+
+	// each tx is counted once per msg type
+	if v := counterValue(t, reader, "relayer.txs_submitted", chainID, recvType); v != 3 {
+		t.Errorf("unexpected txs submitted: %d", v)
+	}
+	if v := counterValue(t, reader, "relayer.txs_succeeded", chainID, updateType); v != 1 {
+		t.Errorf("unexpected txs succeeded: %d", v)
+	}
+	reason := attribute.Key("reason").String(string(TxFailureReasonDeliverTx))
+	if v := counterValue(t, reader, "relayer.txs_failed", chainID, recvType, reason); v != 1 {
+		t.Errorf("unexpected txs failed: %d", v)
+	}
+	// a redundant relay is not counted as a failure
+	if v := counterValue(t, reader, "relayer.txs_failed", chainID, recvType, attribute.Key("reason").String(string(TxFailureReasonUnknown))); v != 0 {
+		t.Errorf("a redundant relay is counted as failed: %d", v)
+	}
+}
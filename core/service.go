@@ -2,11 +2,43 @@ package core
 
 import (
 	"context"
+	"math/rand/v2"
 	"time"
 
 	retry "github.com/avast/retry-go"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"github.com/hyperledger-labs/yui-relayer/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
 )
 
+// RelayServiceOptions is the set of options of a relay service, of which the zero values disable the optional features
+type RelayServiceOptions struct {
+	// RelayInterval is the time interval to perform relays
+	RelayInterval time.Duration
+	// SrcRelayOptimizeInterval and SrcRelayOptimizeCount are the maximum time interval and number of relays to delay for optimization on src
+	SrcRelayOptimizeInterval time.Duration
+	SrcRelayOptimizeCount    uint64
+	// DstRelayOptimizeInterval and DstRelayOptimizeCount are the maximum time interval and number of relays to delay for optimization on dst
+	DstRelayOptimizeInterval time.Duration
+	DstRelayOptimizeCount    uint64
+	// BalanceMonitor monitors the balances of the relayer accounts, which is disabled if nil
+	BalanceMonitor *BalanceMonitor
+	// PathName is the name of the path in the config, which is required to update the path config
+	PathName string
+	// UpgradePolicy is the policy to step forward channel upgrades, which is disabled if nil
+	UpgradePolicy *ChannelUpgradePolicy
+	// MaxRelayBackoff is the maximum random delay before sending msgs, which reduces collisions with other relayers
+	MaxRelayBackoff time.Duration
+	// TimeoutUrgency is the time remaining until timeout within which packets are relayed without the delays for optimization
+	TimeoutUrgency time.Duration
+	// MaxPackets is the maximum number of packets relayed in each direction in a cycle, which is unlimited if zero
+	MaxPackets uint64
+}
+
 // StartService starts a relay service
 func StartService(
 	ctx context.Context,
@@ -18,21 +50,22 @@ func StartService(
 	dstRelayOptimizaInterval time.Duration,
 	dstRelayOptimizeCount uint64,
 ) error {
+	return StartServiceWithOptions(ctx, st, src, dst, RelayServiceOptions{
+		RelayInterval:            relayInterval,
+		SrcRelayOptimizeInterval: srcRelayOptimizeInterval,
+		SrcRelayOptimizeCount:    srcRelayOptimizeCount,
+		DstRelayOptimizeInterval: dstRelayOptimizaInterval,
+		DstRelayOptimizeCount:    dstRelayOptimizeCount,
+	})
+}
+
+// StartServiceWithOptions starts a relay service with `opts`
+func StartServiceWithOptions(ctx context.Context, st StrategyI, src, dst *ProvableChain, opts RelayServiceOptions) error {
 	sh, err := NewSyncHeaders(ctx, src, dst)
 	if err != nil {
 		return err
 	}
-	srv := NewRelayService(
-		st,
-		src,
-		dst,
-		sh,
-		relayInterval,
-		srcRelayOptimizeInterval,
-		srcRelayOptimizeCount,
-		dstRelayOptimizaInterval,
-		dstRelayOptimizeCount,
-	)
+	srv := NewRelayServiceWithOptions(st, src, dst, sh, opts)
 	return srv.Start(ctx)
 }
 
@@ -43,13 +76,33 @@ type RelayService struct {
 	sh            SyncHeaders
 	interval      time.Duration
 	optimizeRelay OptimizeRelay
+	bm            *BalanceMonitor
+	pathName      string
+	upgradePolicy *ChannelUpgradePolicy
+	// maxRelayBackoff is the maximum random delay before sending msgs, which reduces collisions with other relayers
+	maxRelayBackoff time.Duration
+	// upgrading is true if a channel upgrade was in progress at the last cycle
+	upgrading bool
+	// clientsCheckedAt is the time when the health of the clients was checked last
+	clientsCheckedAt time.Time
+	// srcHalted and dstHalted are true if the chains were halted for planned upgrades at the last check of the clients
+	srcHalted, dstHalted bool
+	// balancesCheckedAt is the time when the balances of the relayer accounts were checked last
+	balancesCheckedAt time.Time
 }
 
+// clientHealthCheckInterval is the interval to check if the clients have expired or been frozen
+const clientHealthCheckInterval = time.Minute
+
 type OptimizeRelay struct {
 	srcOptimizeInterval time.Duration
 	srcOptimizeCount    uint64
 	dstOptimizeInterval time.Duration
 	dstOptimizeCount    uint64
+	// timeoutUrgency is the time remaining until timeout within which packets are relayed without the delays for optimization
+	timeoutUrgency time.Duration
+	// maxPackets is the maximum number of packets relayed in each direction in a cycle, which is unlimited if zero
+	maxPackets uint64
 }
 
 // NewRelayService returns a new service
@@ -63,24 +116,43 @@ func NewRelayService(
 	dstOptimizeInterval time.Duration,
 	dstOptimizeCount uint64,
 ) *RelayService {
+	return NewRelayServiceWithOptions(st, src, dst, sh, RelayServiceOptions{
+		RelayInterval:            interval,
+		SrcRelayOptimizeInterval: srcOptimizeInterval,
+		SrcRelayOptimizeCount:    srcOptimizeCount,
+		DstRelayOptimizeInterval: dstOptimizeInterval,
+		DstRelayOptimizeCount:    dstOptimizeCount,
+	})
+}
+
+// NewRelayServiceWithOptions returns a new service with `opts`
+func NewRelayServiceWithOptions(st StrategyI, src, dst *ProvableChain, sh SyncHeaders, opts RelayServiceOptions) *RelayService {
 	return &RelayService{
 		src:      src,
 		dst:      dst,
 		st:       st,
 		sh:       sh,
-		interval: interval,
+		interval: opts.RelayInterval,
 		optimizeRelay: OptimizeRelay{
-			srcOptimizeInterval: srcOptimizeInterval,
-			srcOptimizeCount:    srcOptimizeCount,
-			dstOptimizeInterval: dstOptimizeInterval,
-			dstOptimizeCount:    dstOptimizeCount,
+			srcOptimizeInterval: opts.SrcRelayOptimizeInterval,
+			srcOptimizeCount:    opts.SrcRelayOptimizeCount,
+			dstOptimizeInterval: opts.DstRelayOptimizeInterval,
+			dstOptimizeCount:    opts.DstRelayOptimizeCount,
+			timeoutUrgency:      opts.TimeoutUrgency,
+			maxPackets:          opts.MaxPackets,
 		},
+		bm:              opts.BalanceMonitor,
+		pathName:        opts.PathName,
+		upgradePolicy:   opts.UpgradePolicy,
+		maxRelayBackoff: opts.MaxRelayBackoff,
 	}
 }
 
 // Start starts a relay service
 func (srv *RelayService) Start(ctx context.Context) error {
 	logger := GetChannelPairLogger(srv.src, srv.dst)
+	updateQuarantineMetrics(srv.src)
+	updateQuarantineMetrics(srv.dst)
 	for {
 		if err := retry.Do(func() error {
 			return srv.Serve(ctx)
@@ -103,35 +175,92 @@ func (srv *RelayService) Start(ctx context.Context) error {
 }
 
 // Serve performs packet-relay
-func (srv *RelayService) Serve(ctx context.Context) error {
-	logger := GetChannelPairLogger(srv.src, srv.dst)
+func (srv *RelayService) Serve(ctx context.Context) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "RelayService.Serve", channelPairAttributes(srv.src, srv.dst)...)
+	defer func() { tracing.EndSpan(span, err) }()
+	defer srv.updateServeDurationMetrics(ctx, time.Now(), &err)
+
+	logger := GetChannelPairLogger(srv.src, srv.dst).WithSpanContext(ctx)
+
+	// upgrade the clients tracking the chains halted for planned upgrades,
+	// and report expired or frozen clients early since they can't be fixed by the relay
+	if time.Since(srv.clientsCheckedAt) >= clientHealthCheckInterval {
+		srv.upgradeClients(ctx)
+		srv.checkClients(ctx)
+	}
+
+	// the balances are checked periodically for the metrics and the balance monitor, regardless of how many txs are sent
+	if srv.bm.due(srv.balancesCheckedAt) {
+		srv.checkBalances(ctx)
+	}
 
 	// First, update the latest headers for src and dst
-	if err := srv.sh.Updates(ctx, srv.src, srv.dst); err != nil {
+	if err := srv.updateHeaders(ctx); err != nil {
 		logger.Error("failed to update headers", err)
 		return err
 	}
 
 	// get unrelayed packets
-	pseqs, err := srv.st.UnrelayedPackets(ctx, srv.src, srv.dst, srv.sh, false)
+	pseqs, err := srv.unrelayedPackets(ctx)
 	if err != nil {
 		logger.Error("failed to get unrelayed packets", err)
 		return err
 	}
 
 	// get unrelayed acks
-	aseqs, err := srv.st.UnrelayedAcknowledgements(ctx, srv.src, srv.dst, srv.sh, false)
+	aseqs, err := srv.unrelayedAcknowledgements(ctx)
 	if err != nil {
 		logger.Error("failed to get unrelayed acknowledgements", err)
 		return err
 	}
 
+	// quarantined packets are not relayed until released
+	if err := filterQuarantined(ctx, srv.src, srv.dst, srv.sh, pseqs, true); err != nil {
+		logger.Error("failed to filter quarantined packets", err)
+		return err
+	}
+	if err := filterQuarantined(ctx, srv.src, srv.dst, srv.sh, aseqs, false); err != nil {
+		logger.Error("failed to filter quarantined acknowledgements", err)
+		return err
+	}
+
+	// packets close to timeout are relayed first
+	urgentSrc, urgentDst, err := srv.schedulePackets(ctx, pseqs)
+	if err != nil {
+		logger.Error("failed to schedule packets", err)
+		return err
+	}
+	srv.capAcknowledgements(aseqs)
+
 	msgs := NewRelayMsgs()
 
 	doExecuteRelaySrc, doExecuteRelayDst := srv.shouldExecuteRelay(ctx, pseqs)
 	doExecuteAckSrc, doExecuteAckDst := srv.shouldExecuteRelay(ctx, aseqs)
+	// packets about to time out can't wait for the delays for optimization
+	doExecuteRelaySrc, doExecuteRelayDst = doExecuteRelaySrc || urgentSrc, doExecuteRelayDst || urgentDst
+	// in-flight packets are flushed without delay so that the channel upgrade can proceed
+	if srv.upgrading {
+		doExecuteRelaySrc, doExecuteRelayDst = len(pseqs.Dst) > 0, len(pseqs.Src) > 0
+		doExecuteAckSrc, doExecuteAckDst = len(aseqs.Dst) > 0, len(aseqs.Src) > 0
+	}
+	// no txs can be submitted to a chain halted for a planned upgrade, while the other direction is still relayed
+	if srv.srcHalted {
+		doExecuteRelaySrc, doExecuteAckSrc = false, false
+	}
+	if srv.dstHalted {
+		doExecuteRelayDst, doExecuteAckDst = false, false
+	}
+	// acks are not essential for the liveness of the path, so they are paused to save funds while the balance is low
+	if doExecuteAckSrc && srv.bm.ShouldPauseAcks(srv.src.ChainID()) {
+		logger.Warn("pause relaying acknowledgements due to low balance", "chain_id", srv.src.ChainID())
+		doExecuteAckSrc = false
+	}
+	if doExecuteAckDst && srv.bm.ShouldPauseAcks(srv.dst.ChainID()) {
+		logger.Warn("pause relaying acknowledgements due to low balance", "chain_id", srv.dst.ChainID())
+		doExecuteAckDst = false
+	}
 	// update clients
-	if m, err := srv.st.UpdateClients(ctx, srv.src, srv.dst, doExecuteRelaySrc, doExecuteRelayDst, doExecuteAckSrc, doExecuteAckDst, srv.sh, true); err != nil {
+	if m, err := srv.updateClients(ctx, doExecuteRelaySrc, doExecuteRelayDst, doExecuteAckSrc, doExecuteAckDst); err != nil {
 		logger.Error("failed to update clients", err)
 		return err
 	} else {
@@ -139,7 +268,7 @@ func (srv *RelayService) Serve(ctx context.Context) error {
 	}
 
 	// relay packets if unrelayed seqs exist
-	if m, err := srv.st.RelayPackets(ctx, srv.src, srv.dst, pseqs, srv.sh, doExecuteRelaySrc, doExecuteRelayDst); err != nil {
+	if m, err := srv.relayPackets(ctx, pseqs, doExecuteRelaySrc, doExecuteRelayDst); err != nil {
 		logger.Error("failed to relay packets", err)
 		return err
 	} else {
@@ -147,27 +276,212 @@ func (srv *RelayService) Serve(ctx context.Context) error {
 	}
 
 	// relay acks if unrelayed seqs exist
-	if m, err := srv.st.RelayAcknowledgements(ctx, srv.src, srv.dst, aseqs, srv.sh, doExecuteAckSrc, doExecuteAckDst); err != nil {
+	if m, err := srv.relayAcknowledgements(ctx, aseqs, doExecuteAckSrc, doExecuteAckDst); err != nil {
 		logger.Error("failed to relay acknowledgements", err)
 		return err
 	} else {
 		msgs.Merge(m)
 	}
 
+	if msgs.Ready() && srv.maxRelayBackoff > 0 {
+		// a random delay makes it unlikely that relayers competing on the path send the same packets at the same time
+		if err := wait(ctx, rand.N(srv.maxRelayBackoff)); err != nil {
+			return err
+		}
+	}
+	// the packets may have been relayed by another relayer since the unrelayed packets were queried
+	if err := stripRelayedPackets(ctx, srv.src, srv.dst, msgs); err != nil {
+		logger.Error("failed to recheck the relayed packets", err)
+	}
+
+	// the clients may be refreshed regardless of the relay, which can't be done on a halted chain either
+	if srv.srcHalted {
+		msgs.Src = nil
+	}
+	if srv.dstHalted {
+		msgs.Dst = nil
+	}
+
 	// send all msgs to src/dst chains
-	srv.st.Send(ctx, srv.src, srv.dst, msgs)
+	srv.send(ctx, msgs)
+	srv.quarantinePackets(ctx, msgs)
+
+	if msgs.Ready() {
+		srv.updateTimeToRelayMetrics(ctx, pseqs, msgs)
+		recordFeesEarned(ctx, srv.src, srv.dst, []PacketInfoList{pseqs.Src, pseqs.Dst, aseqs.Src, aseqs.Dst}, msgs)
+	}
+
+	// step forward the channel upgrade initialized on either chain if any
+	if srv.upgradePolicy.Enabled() {
+		if err := srv.stepChannelUpgrade(ctx); err != nil {
+			logger.Error("failed to step the channel upgrade", err)
+		}
+	}
 
 	return nil
 }
 
+func (srv *RelayService) stepChannelUpgrade(ctx context.Context) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "StepChannelUpgrade")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	srv.upgrading, err = StepChannelUpgrade(ctx, srv.pathName, srv.src, srv.dst, srv.upgradePolicy)
+	span.SetAttributes(attribute.Bool("upgrading", srv.upgrading))
+	return err
+}
+
+// upgradeClients upgrades the client tracking src or dst if it is halted for a planned upgrade, and records which chains are halted.
+// Each direction is handled separately, and errors are only logged because the upgrade is retried at the next check.
+func (srv *RelayService) upgradeClients(ctx context.Context) {
+	for _, c := range []struct {
+		chain, cp *ProvableChain
+		halted    *bool
+	}{
+		{srv.src, srv.dst, &srv.srcHalted},
+		{srv.dst, srv.src, &srv.dstHalted},
+	} {
+		halted, err := srv.upgradeClient(ctx, c.chain, c.cp)
+		if err != nil {
+			GetClientPairLogger(c.chain, c.cp).WithSpanContext(ctx).Error("failed to upgrade client", err)
+		}
+		*c.halted = halted
+	}
+}
+
+func (srv *RelayService) upgradeClient(ctx context.Context, chain, cp *ProvableChain) (halted bool, err error) {
+	ctx, span := tracing.StartSpan(ctx, "UpgradeClient", chainAttributes(chain)...)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	halted, err = UpgradeClientIfHalted(ctx, chain, cp)
+	span.SetAttributes(attribute.Bool("halted", halted))
+	return halted, err
+}
+
+func (srv *RelayService) checkClients(ctx context.Context) {
+	ctx, span := tracing.StartSpan(ctx, "CheckClients")
+	defer span.End()
+
+	for _, pair := range [][2]*ProvableChain{{srv.src, srv.dst}, {srv.dst, srv.src}} {
+		chain, cp := pair[0], pair[1]
+		if err := CheckClientHealth(ctx, chain, cp); err != nil {
+			GetClientPairLogger(chain, cp).WithSpanContext(ctx).Error("failed to check the client health", err)
+		}
+	}
+	srv.checkChannel(ctx)
+	srv.clientsCheckedAt = time.Now()
+}
+
+// checkBalances queries the balances of the relayer accounts on both chains, which feed both the balance metrics and the balance monitor
+func (srv *RelayService) checkBalances(ctx context.Context) {
+	srv.bm.Check(ctx, srv.src, srv.dst)
+	srv.balancesCheckedAt = time.Now()
+}
+
+// checkChannel reports the channel closed by a packet timeout, which can't be relayed until it is reopened by `tx channel --reopen`
+func (srv *RelayService) checkChannel(ctx context.Context) {
+	logger := GetChannelPairLogger(srv.src, srv.dst).WithSpanContext(ctx)
+	srcCtx, err := latestQueryContext(ctx, srv.src)
+	if err != nil {
+		logger.Error("failed to check the channel", err)
+		return
+	}
+	dstCtx, err := latestQueryContext(ctx, srv.dst)
+	if err != nil {
+		logger.Error("failed to check the channel", err)
+		return
+	}
+	srcChan, dstChan, err := QueryChannelPair(srcCtx, dstCtx, srv.src, srv.dst, false)
+	if err != nil {
+		logger.Error("failed to check the channel", err)
+		return
+	}
+	if srcChan.Channel.State == chantypes.CLOSED || dstChan.Channel.State == chantypes.CLOSED {
+		logger.Warn("channel is closed and needs to be reopened by `tx channel --reopen`",
+			"ica", isICAControllerPort(srv.src.Path().PortID) || isICAControllerPort(srv.dst.Path().PortID),
+			"src_state", srcChan.Channel.State.String(),
+			"dst_state", dstChan.Channel.State.String(),
+		)
+	}
+}
+
+func (srv *RelayService) updateHeaders(ctx context.Context) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "SyncHeaders.Updates")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return srv.sh.Updates(ctx, srv.src, srv.dst)
+}
+
+func (srv *RelayService) unrelayedPackets(ctx context.Context) (rp *RelayPackets, err error) {
+	ctx, span := tracing.StartSpan(ctx, "UnrelayedPackets")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	rp, err = srv.st.UnrelayedPackets(ctx, srv.src, srv.dst, srv.sh, false)
+	if err == nil {
+		span.SetAttributes(relayPacketsAttributes(rp)...)
+	}
+	return rp, err
+}
+
+func (srv *RelayService) unrelayedAcknowledgements(ctx context.Context) (rp *RelayPackets, err error) {
+	ctx, span := tracing.StartSpan(ctx, "UnrelayedAcknowledgements")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	rp, err = srv.st.UnrelayedAcknowledgements(ctx, srv.src, srv.dst, srv.sh, false)
+	if err == nil {
+		span.SetAttributes(relayPacketsAttributes(rp)...)
+	}
+	return rp, err
+}
+
+func (srv *RelayService) updateClients(ctx context.Context, doExecuteRelaySrc, doExecuteRelayDst, doExecuteAckSrc, doExecuteAckDst bool) (msgs *RelayMsgs, err error) {
+	ctx, span := tracing.StartSpan(ctx, "UpdateClients")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	msgs, err = srv.st.UpdateClients(ctx, srv.src, srv.dst, doExecuteRelaySrc, doExecuteRelayDst, doExecuteAckSrc, doExecuteAckDst, srv.sh, true)
+	if err == nil {
+		span.SetAttributes(relayMsgsAttributes(msgs)...)
+	}
+	return msgs, err
+}
+
+func (srv *RelayService) relayPackets(ctx context.Context, rp *RelayPackets, doExecuteRelaySrc, doExecuteRelayDst bool) (msgs *RelayMsgs, err error) {
+	ctx, span := tracing.StartSpan(ctx, "RelayPackets", relayPacketsAttributes(rp)...)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	msgs, err = srv.st.RelayPackets(ctx, srv.src, srv.dst, rp, srv.sh, doExecuteRelaySrc, doExecuteRelayDst)
+	if err == nil {
+		span.SetAttributes(relayMsgsAttributes(msgs)...)
+	}
+	return msgs, err
+}
+
+func (srv *RelayService) relayAcknowledgements(ctx context.Context, rp *RelayPackets, doExecuteAckSrc, doExecuteAckDst bool) (msgs *RelayMsgs, err error) {
+	ctx, span := tracing.StartSpan(ctx, "RelayAcknowledgements", relayPacketsAttributes(rp)...)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	msgs, err = srv.st.RelayAcknowledgements(ctx, srv.src, srv.dst, rp, srv.sh, doExecuteAckSrc, doExecuteAckDst)
+	if err == nil {
+		span.SetAttributes(relayMsgsAttributes(msgs)...)
+	}
+	return msgs, err
+}
+
+func (srv *RelayService) send(ctx context.Context, msgs *RelayMsgs) {
+	ctx, span := tracing.StartSpan(ctx, "Send", relayMsgsAttributes(msgs)...)
+	defer span.End()
+
+	srv.st.Send(ctx, srv.src, srv.dst, msgs)
+	span.SetAttributes(attribute.Bool("succeeded", msgs.Succeeded))
+}
+
 func (srv *RelayService) shouldExecuteRelay(ctx context.Context, seqs *RelayPackets) (bool, bool) {
-	logger := GetChannelPairLogger(srv.src, srv.dst)
+	logger := GetChannelPairLogger(srv.src, srv.dst).WithSpanContext(ctx)
 
 	srcRelay := false
 	dstRelay := false
 
 	if len(seqs.Src) > 0 {
-		tsDst, err := srv.src.Timestamp(ctx, seqs.Src[0].EventHeight)
+		tsDst, err := srv.src.Timestamp(ctx, oldestEventHeight(seqs.Src))
 		if err != nil {
 			return false, false
 		}
@@ -177,7 +491,7 @@ func (srv *RelayService) shouldExecuteRelay(ctx context.Context, seqs *RelayPack
 	}
 
 	if len(seqs.Dst) > 0 {
-		tsSrc, err := srv.dst.Timestamp(ctx, seqs.Dst[0].EventHeight)
+		tsSrc, err := srv.dst.Timestamp(ctx, oldestEventHeight(seqs.Dst))
 		if err != nil {
 			return false, false
 		}
@@ -201,3 +515,53 @@ func (srv *RelayService) shouldExecuteRelay(ctx context.Context, seqs *RelayPack
 
 	return srcRelay, dstRelay
 }
+
+func (srv *RelayService) updateServeDurationMetrics(ctx context.Context, start time.Time, err *error) {
+	metrics.ServeDurationHistogram.Record(ctx, time.Since(start).Seconds(), api.WithAttributes(
+		attribute.Key("src_chain_id").String(srv.src.ChainID()),
+		attribute.Key("dst_chain_id").String(srv.dst.ChainID()),
+		attribute.Key("succeeded").Bool(*err == nil),
+	))
+}
+
+// updateTimeToRelayMetrics records the time from when each packet was sent to when its recvPacket was committed
+func (srv *RelayService) updateTimeToRelayMetrics(ctx context.Context, rp *RelayPackets, msgs *RelayMsgs) {
+	now := time.Now()
+	recordTimeToRelay(ctx, srv.src, "src", rp.Src, msgs.Dst, msgs.DstMsgIDs, now)
+	recordTimeToRelay(ctx, srv.dst, "dst", rp.Dst, msgs.Src, msgs.SrcMsgIDs, now)
+}
+
+func recordTimeToRelay(ctx context.Context, chain Chain, direction string, packets PacketInfoList, msgs []sdk.Msg, msgIDs []MsgID, committedAt time.Time) {
+	logger := GetChannelLogger(chain).WithSpanContext(ctx)
+
+	packetsBySeq := make(map[uint64]*PacketInfo)
+	for _, p := range packets {
+		packetsBySeq[p.Sequence] = p
+	}
+
+	// timestamps of the blocks in which packets were sent
+	timestamps := make(map[clienttypes.Height]time.Time)
+	for i, msg := range msgs {
+		recvMsg, ok := msg.(*chantypes.MsgRecvPacket)
+		if !ok || i >= len(msgIDs) || msgIDs[i] == nil {
+			continue
+		}
+		p, ok := packetsBySeq[recvMsg.Packet.Sequence]
+		if !ok {
+			continue
+		}
+		sentAt, ok := timestamps[p.EventHeight]
+		if !ok {
+			var err error
+			if sentAt, err = chain.Timestamp(ctx, p.EventHeight); err != nil {
+				logger.Error("failed to get the timestamp of the block in which the packet was sent", err, "height", p.EventHeight)
+				continue
+			}
+			timestamps[p.EventHeight] = sentAt
+		}
+		metrics.TimeToRelayHistogram.Record(ctx, committedAt.Sub(sentAt).Seconds(), api.WithAttributes(
+			attribute.Key("chain_id").String(chain.ChainID()),
+			attribute.Key("direction").String(direction),
+		))
+	}
+}
@@ -45,12 +45,29 @@ type StrategyCfg struct {
 	// If set, executions of acknowledgePacket are always skipped on the dst chain
 	// Also `UnrelayedAcknowledgements` returns zero packets for the dst chain.
 	DstNoack bool `json:"dst-noack" yaml:"dst-noack"`
+
+	// FeePolicy is how packets with escrowed ICS-29 fees are treated.
+	// "prioritize" relays them before the others, and "only" relays only them. By default, all packets are relayed in order.
+	FeePolicy string `json:"fee-policy,omitempty" yaml:"fee-policy,omitempty"`
+
+	// MaxTxSize and MaxMsgLength are the maximum size and number of msgs bundled in a relay tx.
+	MaxTxSize    uint64 `json:"max-tx-size,omitempty" yaml:"max-tx-size,omitempty"`
+	MaxMsgLength uint64 `json:"max-msg-length,omitempty" yaml:"max-msg-length,omitempty"`
+
+	// MaxGas is the maximum gas of a relay tx, which is estimated by simulating each candidate bundle of msgs.
+	// It is ignored on chains that don't support gas estimation. Zero values of the limits mean no limit.
+	MaxGas uint64 `json:"max-gas,omitempty" yaml:"max-gas,omitempty"`
 }
 
 func GetStrategy(cfg StrategyCfg) (StrategyI, error) {
 	switch cfg.Type {
 	case "naive":
-		return NewNaiveStrategy(cfg.SrcNoack, cfg.DstNoack), nil
+		st := NewNaiveStrategy(cfg.SrcNoack, cfg.DstNoack)
+		st.FeePolicy = cfg.FeePolicy
+		st.MaxTxSize = cfg.MaxTxSize
+		st.MaxMsgLength = cfg.MaxMsgLength
+		st.MaxGas = cfg.MaxGas
+		return st, nil
 	default:
 		return nil, fmt.Errorf("unknown strategy type '%v'", cfg.Type)
 	}
@@ -60,7 +77,7 @@ func GetStrategy(cfg StrategyCfg) (StrategyI, error) {
 func (p *Path) ValidateStrategy() error {
 	switch p.Strategy.Type {
 	case (&NaiveStrategy{}).GetType():
-		return nil
+		return validateFeePolicy(p.Strategy.FeePolicy)
 	default:
 		return fmt.Errorf("invalid strategy: %s", p.Strategy.Type)
 	}
@@ -0,0 +1,294 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	api "go.opentelemetry.io/otel/metric"
+)
+
+const defaultCriticalTimeout = 5 * time.Minute
+
+// SubmissionLimit caps how often and how expensively the relayer submits txs to the chain `ChainID`.
+// A limit set to zero or empty is disabled. When a limit is hit, MsgRecvPacket and MsgAcknowledgement are deferred
+// except MsgRecvPacket of packets about to time out, while the other msgs (e.g. MsgTimeout and handshakes) are still submitted.
+type SubmissionLimit struct {
+	ChainID         string `json:"chain-id" yaml:"chain-id"`
+	MaxTxsPerMinute uint64 `json:"max-txs-per-minute" yaml:"max-txs-per-minute"`
+	MaxGasPerHour   uint64 `json:"max-gas-per-hour" yaml:"max-gas-per-hour"`
+
+	// MaxFeePerDay is the maximum amount of fees paid in 24 hours (e.g. "1000000stake")
+	MaxFeePerDay string `json:"max-fee-per-day" yaml:"max-fee-per-day"`
+
+	// CriticalTimeout is the time remaining until timeout (e.g. "5m") within which a packet is relayed even if a limit is hit.
+	// If empty, 5m is used.
+	CriticalTimeout string `json:"critical-timeout" yaml:"critical-timeout"`
+}
+
+// txCost is the gas used and the fees paid by a committed tx
+type txCost struct {
+	committedAt time.Time
+	gas         uint64
+	fees        sdk.Coins
+}
+
+// submissionLimiter tracks the submissions to a chain against its SubmissionLimit
+type submissionLimiter struct {
+	maxTxsPerMinute uint64
+	maxGasPerHour   uint64
+	maxFeePerDay    sdk.Coins
+	criticalTimeout time.Duration
+
+	mutex       sync.Mutex
+	submittedAt []time.Time // submissions in the last minute
+	costs       []txCost    // committed txs in the last day
+}
+
+// newSubmissionLimiter returns a submissionLimiter that tracks the submissions against `limit`
+func newSubmissionLimiter(limit SubmissionLimit) (*submissionLimiter, error) {
+	l := &submissionLimiter{
+		maxTxsPerMinute: limit.MaxTxsPerMinute,
+		maxGasPerHour:   limit.MaxGasPerHour,
+		criticalTimeout: defaultCriticalTimeout,
+	}
+	if limit.MaxFeePerDay != "" {
+		fee, err := sdk.ParseCoinsNormalized(limit.MaxFeePerDay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max fee per day for %s: %v", limit.ChainID, err)
+		}
+		l.maxFeePerDay = fee
+	}
+	if limit.CriticalTimeout != "" {
+		d, err := time.ParseDuration(limit.CriticalTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid critical timeout for %s: %v", limit.ChainID, err)
+		}
+		l.criticalTimeout = d
+	}
+	return l, nil
+}
+
+// SetSubmissionLimit sets up the limit of the submissions to the chain, which is applied to every tx sent by SendMsgs
+func (pc *ProvableChain) SetSubmissionLimit(limit SubmissionLimit) error {
+	if limit.ChainID != pc.ChainID() {
+		return fmt.Errorf("submission limit for %s is set to %s", limit.ChainID, pc.ChainID())
+	}
+	l, err := newSubmissionLimiter(limit)
+	if err != nil {
+		return err
+	}
+	pc.limiter = l
+	return nil
+}
+
+// TxCostReporter is an optional interface of Chain that reports the cost of the tx sent by SendMsgs,
+// which counts toward the submission limits of the chain.
+// If a chain with a gas or fee limit doesn't implement it, only the number of the txs is limited.
+type TxCostReporter interface {
+	// SendMsgsWithCost sends `msgs` as SendMsgs does, and returns the gas used and the fees paid by the tx.
+	// The cost is returned even with an error if the tx was committed despite the failure (e.g. in DeliverTx), or nil otherwise.
+	SendMsgsWithCost(ctx context.Context, msgs []sdk.Msg) ([]MsgID, *TxCost, error)
+}
+
+// sendWithinLimits sends the msgs of `msgs` that can be submitted to `chain` within the limits of `l` in a tx, and records the submission and its cost.
+// The ids of the deferred msgs are nil, and no tx is sent if all of them are deferred. If `l` is nil, `msgs` are sent as they are.
+func sendWithinLimits(ctx context.Context, l *submissionLimiter, chain Chain, msgs []sdk.Msg) ([]MsgID, error) {
+	if l == nil {
+		return chain.SendMsgs(ctx, msgs)
+	}
+
+	kept := l.limit(ctx, chain, msgs)
+	ids := make([]MsgID, len(msgs))
+	if len(kept) == 0 {
+		return ids, nil
+	}
+	keptMsgs := make([]sdk.Msg, len(kept))
+	for j, i := range kept {
+		keptMsgs[j] = msgs[i]
+	}
+
+	l.recordSubmission(time.Now())
+	var (
+		keptIDs []MsgID
+		cost    *TxCost
+		err     error
+	)
+	if reporter, ok := chain.(TxCostReporter); ok {
+		keptIDs, cost, err = reporter.SendMsgsWithCost(ctx, keptMsgs)
+	} else {
+		keptIDs, err = chain.SendMsgs(ctx, keptMsgs)
+	}
+	if cost != nil {
+		l.recordCost(time.Now(), *cost)
+	}
+	if err != nil {
+		if len(kept) < len(msgs) {
+			return nil, &remappedMsgIndexError{err: err, indexes: kept}
+		}
+		return nil, err
+	}
+	for j, i := range kept {
+		ids[i] = keptIDs[j]
+	}
+	return ids, nil
+}
+
+// remappedMsgIndexError is an error of the tx that contained only some of the msgs,
+// of which the index of the failed msg is remapped to the index in all the msgs.
+type remappedMsgIndexError struct {
+	err error
+	// indexes are the indexes in all the msgs of the msgs contained in the tx
+	indexes []int
+}
+
+func (e *remappedMsgIndexError) Error() string {
+	return failingMsgIndexRegexp.ReplaceAllStringFunc(e.err.Error(), func(m string) string {
+		i, err := strconv.Atoi(failingMsgIndexRegexp.FindStringSubmatch(m)[1])
+		if err != nil || i >= len(e.indexes) {
+			return m
+		}
+		return fmt.Sprintf("message index: %d", e.indexes[i])
+	})
+}
+
+func (e *remappedMsgIndexError) Unwrap() error {
+	return e.err
+}
+
+// recordSubmission records a tx submitted at `now`
+func (l *submissionLimiter) recordSubmission(now time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.submittedAt = append(l.submittedAt, now)
+}
+
+// recordCost records the cost of a tx committed at `now`
+func (l *submissionLimiter) recordCost(now time.Time, cost TxCost) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.costs = append(l.costs, txCost{committedAt: now, gas: cost.GasUsed, fees: cost.FeesPaid})
+}
+
+// exceeded returns the name of the limit that has been hit at `now`, or an empty string if no limit has been hit
+func (l *submissionLimiter) exceeded(now time.Time) string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for len(l.submittedAt) > 0 && now.Sub(l.submittedAt[0]) >= time.Minute {
+		l.submittedAt = l.submittedAt[1:]
+	}
+	for len(l.costs) > 0 && now.Sub(l.costs[0].committedAt) >= 24*time.Hour {
+		l.costs = l.costs[1:]
+	}
+
+	if l.maxTxsPerMinute > 0 && uint64(len(l.submittedAt)) >= l.maxTxsPerMinute {
+		return "max_txs_per_minute"
+	}
+	var (
+		gas  uint64
+		fees sdk.Coins
+	)
+	for _, c := range l.costs {
+		if now.Sub(c.committedAt) < time.Hour {
+			gas += c.gas
+		}
+		fees = fees.Add(c.fees...)
+	}
+	if l.maxGasPerHour > 0 && gas >= l.maxGasPerHour {
+		return "max_gas_per_hour"
+	}
+	if !l.maxFeePerDay.IsZero() && fees.IsAnyGTE(l.maxFeePerDay) {
+		return "max_fee_per_day"
+	}
+	return ""
+}
+
+// limit returns the indexes of `msgs` that can be submitted to `chain` within the limits.
+// If a limit has been hit, it defers the MsgRecvPacket of the packets not about to time out and the MsgAcknowledgement.
+// A MsgUpdateClient is kept if it precedes a msg kept, or if `msgs` consist only of MsgUpdateClient refreshing the client.
+func (l *submissionLimiter) limit(ctx context.Context, chain Chain, msgs []sdk.Msg) []int {
+	limit := l.exceeded(time.Now())
+	if limit == "" {
+		all := make([]int, len(msgs))
+		for i := range msgs {
+			all[i] = i
+		}
+		return all
+	}
+
+	logger := GetChannelLogger(chain).WithSpanContext(ctx)
+	var (
+		ret     []int
+		updates []int
+		refresh = true
+
+		// the latest height and timestamp of `chain` are queried once at the first MsgRecvPacket
+		height    ibcexported.Height
+		timestamp time.Time
+		queried   bool
+		queryErr  error
+	)
+	for i, msg := range msgs {
+		critical := true
+		switch msg := msg.(type) {
+		case *clienttypes.MsgUpdateClient:
+			updates = append(updates, i)
+			continue
+		case *chantypes.MsgAcknowledgement:
+			critical = false
+		case *chantypes.MsgRecvPacket:
+			if !queried {
+				height, timestamp, queryErr = latestHeightAndTimestamp(ctx, chain)
+				if queryErr != nil {
+					logger.Error("failed to check if the packets are about to time out", queryErr)
+				}
+				queried = true
+			}
+			if queryErr != nil {
+				critical = false
+			} else {
+				remaining, ok := timeToTimeout(msg.Packet, height, timestamp, chain.AverageBlockTime())
+				critical = ok && remaining > 0 && remaining <= l.criticalTimeout
+			}
+		}
+		refresh = false
+		if critical {
+			// the client updates preceding the msg are required to verify it
+			ret, updates = append(ret, updates...), nil
+			ret = append(ret, i)
+		}
+	}
+	if refresh {
+		ret = updates
+	}
+
+	if deferred := len(msgs) - len(ret); deferred > 0 {
+		logger.Warn("submission limit has been hit, so non-critical msgs are deferred", "limit", limit, "num_deferred", deferred, "num_critical", len(ret))
+		metrics.DeferredMsgsCounter.Add(ctx, int64(deferred), api.WithAttributes(
+			attribute.Key("chain_id").String(chain.ChainID()),
+			attribute.Key("limit").String(limit),
+		))
+	}
+	return ret
+}
+
+func latestHeightAndTimestamp(ctx context.Context, chain Chain) (ibcexported.Height, time.Time, error) {
+	height, err := chain.LatestHeight(ctx)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to get the latest height: %v", err)
+	}
+	timestamp, err := chain.Timestamp(ctx, height)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to get the timestamp of the latest block: %v", err)
+	}
+	return height, timestamp, nil
+}
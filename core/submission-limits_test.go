@@ -0,0 +1,198 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/hyperledger-labs/yui-relayer/log"
+	"github.com/hyperledger-labs/yui-relayer/metrics"
+)
+
+func TestSubmissionLimiterExceeded(t *testing.T) {
+	l, err := newSubmissionLimiter(SubmissionLimit{
+		ChainID:         "ibc0",
+		MaxTxsPerMinute: 2,
+		MaxGasPerHour:   1000,
+		MaxFeePerDay:    "100stake",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	if limit := l.exceeded(now); limit != "" {
+		t.Fatalf("no limit is expected to be hit: %s", limit)
+	}
+
+	l.submittedAt = []time.Time{now.Add(-2 * time.Minute), now.Add(-30 * time.Second), now.Add(-10 * time.Second)}
+	if limit := l.exceeded(now); limit != "max_txs_per_minute" {
+		t.Errorf("unexpected limit: %s", limit)
+	}
+	if limit := l.exceeded(now.Add(40 * time.Second)); limit != "" {
+		t.Errorf("the submissions older than a minute are expected to be dropped: %s", limit)
+	}
+
+	l.costs = []txCost{
+		{committedAt: now.Add(-2 * time.Hour), gas: 900},
+		{committedAt: now.Add(-10 * time.Minute), gas: 600},
+	}
+	if limit := l.exceeded(now.Add(40 * time.Second)); limit != "" {
+		t.Errorf("the gas used more than an hour ago is not expected to count: %s", limit)
+	}
+	l.recordCost(now, TxCost{GasUsed: 400, FeesPaid: sdk.NewCoins(sdk.NewInt64Coin("stake", 100))})
+	if limit := l.exceeded(now.Add(40 * time.Second)); limit != "max_gas_per_hour" {
+		t.Errorf("unexpected limit: %s", limit)
+	}
+	if limit := l.exceeded(now.Add(2 * time.Hour)); limit != "max_fee_per_day" {
+		t.Errorf("unexpected limit: %s", limit)
+	}
+	if limit := l.exceeded(now.Add(25 * time.Hour)); limit != "" {
+		t.Errorf("the fees paid more than a day ago are not expected to count: %s", limit)
+	}
+
+	if _, err := newSubmissionLimiter(SubmissionLimit{ChainID: "ibc0", MaxFeePerDay: "invalid"}); err == nil {
+		t.Error("an invalid fee is expected to be rejected")
+	}
+}
+
+func TestLimitSubmission(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	l, err := newSubmissionLimiter(SubmissionLimit{ChainID: "ibc0", MaxTxsPerMinute: 1, CriticalTimeout: "1m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the chain is at height 100 with the block time of a second
+	update := &clienttypes.MsgUpdateClient{}
+	recv := func(timeoutHeight uint64) sdk.Msg {
+		return &chantypes.MsgRecvPacket{Packet: chantypes.Packet{TimeoutHeight: clienttypes.NewHeight(0, timeoutHeight)}}
+	}
+	ack := &chantypes.MsgAcknowledgement{}
+	timeout := &chantypes.MsgTimeout{}
+	openTry := &chantypes.MsgChannelOpenTry{}
+
+	cases := []struct {
+		name     string
+		hit      bool
+		msgs     []sdk.Msg
+		expected []int
+	}{
+		{"no limit hit", false, []sdk.Msg{update, recv(1000), ack}, []int{0, 1, 2}},
+		{"packet about to time out", true, []sdk.Msg{update, recv(150), update, recv(1000), ack}, []int{0, 1}},
+		{"client updates preceding a critical msg", true, []sdk.Msg{update, recv(1000), recv(150)}, []int{0, 2}},
+		{"packet timed out or without timeout", true, []sdk.Msg{update, recv(50), recv(0)}, nil},
+		{"trailing client update", true, []sdk.Msg{update, recv(150), update}, []int{0, 1}},
+		{"timeout", true, []sdk.Msg{update, ack, update, timeout}, []int{0, 2, 3}},
+		{"handshake", true, []sdk.Msg{update, openTry}, []int{0, 1}},
+		{"client refresh", true, []sdk.Msg{update, update}, []int{0, 1}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, _ := newStateChainPair()
+			l.submittedAt = nil
+			if c.hit {
+				l.recordSubmission(time.Now())
+			}
+
+			if kept := l.limit(context.TODO(), s, c.msgs); !slices.Equal(kept, c.expected) {
+				t.Errorf("unexpected msgs kept: actual=%v, expected=%v", kept, c.expected)
+			}
+		})
+	}
+}
+
+// costChain is a stateChain of which each tx uses `gas`, and fails in DeliverTx with `err` if it is not nil
+type costChain struct {
+	*stateChain
+	gas uint64
+	err error
+}
+
+var _ TxCostReporter = (*costChain)(nil)
+
+func (c *costChain) SendMsgsWithCost(ctx context.Context, msgs []sdk.Msg) ([]MsgID, *TxCost, error) {
+	cost := &TxCost{GasUsed: c.gas}
+	if c.err != nil {
+		c.sent = append(c.sent, msgs)
+		return nil, cost, c.err
+	}
+	ids, err := c.stateChain.SendMsgs(ctx, msgs)
+	return ids, cost, err
+}
+
+func TestSendWithinLimits(t *testing.T) {
+	if err := log.InitLogger("DEBUG", "text", "stderr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.InitializeMetrics(metrics.ExporterNull{}); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := newStateChainPair()
+	chain := &costChain{stateChain: s, gas: 600}
+	pc := newTestProvableChain(chain)
+	if err := pc.SetSubmissionLimit(SubmissionLimit{ChainID: "ibc1", MaxTxsPerMinute: 3}); err == nil {
+		t.Error("a limit of another chain is expected to be rejected")
+	}
+	if err := pc.SetSubmissionLimit(SubmissionLimit{ChainID: "ibc0", MaxTxsPerMinute: 3, MaxGasPerHour: 1000}); err != nil {
+		t.Fatal(err)
+	}
+	update := &clienttypes.MsgUpdateClient{}
+	ack := &chantypes.MsgAcknowledgement{}
+	timeout := &chantypes.MsgTimeout{}
+
+	// the limits are checked on each tx
+	for i := 0; i < 2; i++ {
+		ids, err := pc.SendMsgs(context.TODO(), []sdk.Msg{update, ack})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if slices.Contains(ids, nil) {
+			t.Fatalf("unexpected msgs deferred in tx %d: %v", i, ids)
+		}
+	}
+	// the gas used by the txs has hit the limit
+	ids, err := pc.SendMsgs(context.TODO(), []sdk.Msg{update, ack, timeout})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids[0] == nil || ids[1] != nil || ids[2] == nil {
+		t.Errorf("unexpected ids: %v", ids)
+	}
+	if last := s.sent[len(s.sent)-1]; !slices.Equal(last, []sdk.Msg{update, timeout}) {
+		t.Errorf("unexpected msgs sent: %v", last)
+	}
+	// no tx is sent if all the msgs are deferred
+	if ids, err := pc.SendMsgs(context.TODO(), []sdk.Msg{ack}); err != nil || len(ids) != 1 || ids[0] != nil {
+		t.Errorf("unexpected result: ids=%v, err=%v", ids, err)
+	}
+	if len(s.sent) != 3 || len(pc.limiter.submittedAt) != 3 {
+		t.Errorf("unexpected txs: sent=%d, submitted=%d", len(s.sent), len(pc.limiter.submittedAt))
+	}
+
+	// the index of the failed msg is reported in all the msgs, and the cost of the failed tx is recorded
+	chain.err = &TxError{
+		Reason: TxFailureReasonDeliverTx,
+		Err:    fmt.Errorf("failed to execute message; message index: 1: %v", chantypes.ErrInvalidPacket),
+	}
+	numCosts := len(pc.limiter.costs)
+	_, err = pc.SendMsgs(context.TODO(), []sdk.Msg{update, ack, timeout})
+	if i, ok := failingMsgIndex(err, 3); !ok || i != 2 {
+		t.Errorf("unexpected msg index in the error: %v", err)
+	}
+	if GetTxFailureReason(err) != TxFailureReasonDeliverTx || !isChainError(err, chantypes.ErrInvalidPacket) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(pc.limiter.costs) != numCosts+1 {
+		t.Error("the cost of the failed tx is not recorded")
+	}
+}
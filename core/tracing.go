@@ -0,0 +1,36 @@
+package core
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func chainAttributes(chain ChainInfo) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("chain_id", chain.ChainID()),
+	}
+}
+
+func channelPairAttributes(src, dst Chain) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("src.chain_id", src.ChainID()),
+		attribute.String("src.port_id", src.Path().PortID),
+		attribute.String("src.channel_id", src.Path().ChannelID),
+		attribute.String("dst.chain_id", dst.ChainID()),
+		attribute.String("dst.port_id", dst.Path().PortID),
+		attribute.String("dst.channel_id", dst.Path().ChannelID),
+	}
+}
+
+func relayPacketsAttributes(rp *RelayPackets) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int("src.num_packets", len(rp.Src)),
+		attribute.Int("dst.num_packets", len(rp.Dst)),
+	}
+}
+
+func relayMsgsAttributes(msgs *RelayMsgs) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int("src.num_msgs", len(msgs.Src)),
+		attribute.Int("dst.num_msgs", len(msgs.Dst)),
+	}
+}
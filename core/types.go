@@ -1,6 +1,7 @@
 package core
 
 import (
+	feetypes "github.com/cosmos/ibc-go/v8/modules/apps/29-fee/types"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	chantypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 )
@@ -13,6 +14,8 @@ type PacketInfo struct {
 	chantypes.Packet
 	Acknowledgement []byte             `json:"acknowledgement"`
 	EventHeight     clienttypes.Height `json:"event_height"`
+	// Fee is the sum of the ICS-29 fees escrowed for the packet, which is set only if the packet is incentivized
+	Fee *feetypes.Fee `json:"fee,omitempty"`
 }
 
 // PacketInfoList represents a list of PacketInfo that is sorted in the order in which
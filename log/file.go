@@ -0,0 +1,108 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// FileConfig describes the log file and how it is rotated
+type FileConfig struct {
+	Path string
+	// MaxSize is the maximum size in megabytes of the log file before it gets rotated. If 0, it defaults to 100 megabytes.
+	MaxSize int
+	// MaxAge is the maximum number of days to retain rotated files. If 0, rotated files are not removed based on age.
+	MaxAge int
+	// MaxBackups is the maximum number of rotated files to retain. If 0, all rotated files are retained unless MaxAge removes them.
+	MaxBackups int
+	// RotationInterval is the time interval to rotate the log file regardless of its size. If 0, the file is rotated only by size.
+	RotationInterval time.Duration
+	// Compress determines if rotated files are compressed with gzip
+	Compress bool
+}
+
+var (
+	fileMutex  sync.Mutex
+	fileWriter *lumberjack.Logger
+	stopRotate chan struct{}
+	rotateDone chan struct{}
+
+	// newRotationTicker returns the channel that ticks every `interval` to rotate the log file, and the function to stop it.
+	// It is replaced in tests so that the rotation doesn't depend on the wall clock.
+	newRotationTicker = func(interval time.Duration) (<-chan time.Time, func()) {
+		ticker := time.NewTicker(interval)
+		return ticker.C, ticker.Stop
+	}
+)
+
+// InitFileLogger initializes the global logger that writes to the file rotated by `conf`
+func InitFileLogger(logLevel, format string, conf FileConfig) error {
+	if conf.Path == "" {
+		return errors.New("log file path is not specified")
+	}
+	writer := &lumberjack.Logger{
+		Filename:   conf.Path,
+		MaxSize:    conf.MaxSize,
+		MaxAge:     conf.MaxAge,
+		MaxBackups: conf.MaxBackups,
+		LocalTime:  true,
+		Compress:   conf.Compress,
+	}
+	if err := InitLoggerWithWriter(logLevel, format, writer); err != nil {
+		return err
+	}
+
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+	if err := closeFile(); err != nil {
+		return err
+	}
+	fileWriter = writer
+	if conf.RotationInterval > 0 {
+		stopRotate, rotateDone = make(chan struct{}), make(chan struct{})
+		go rotatePeriodically(writer, conf.RotationInterval, stopRotate, rotateDone)
+	}
+	return nil
+}
+
+// CloseFile closes the log file opened by InitFileLogger if exists
+func CloseFile() error {
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+	return closeFile()
+}
+
+func closeFile() error {
+	if stopRotate != nil {
+		// wait for the rotation in progress so that the file is not reopened after being closed
+		close(stopRotate)
+		<-rotateDone
+		stopRotate, rotateDone = nil, nil
+	}
+	if fileWriter != nil {
+		if err := fileWriter.Close(); err != nil {
+			return fmt.Errorf("failed to close the log file: %v", err)
+		}
+		fileWriter = nil
+	}
+	return nil
+}
+
+func rotatePeriodically(writer *lumberjack.Logger, interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticks, stopTicker := newRotationTicker(interval)
+	defer stopTicker()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticks:
+			if err := writer.Rotate(); err != nil {
+				GetLogger().Error("failed to rotate the log file", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// levels holds the global level and the per-module levels, both of which can be changed at runtime
+type levels struct {
+	mutex   sync.RWMutex
+	global  slog.Level
+	modules map[string]slog.Level
+}
+
+var logLevels = &levels{modules: make(map[string]slog.Level)}
+
+// level returns the level of `module`.
+// A module inherits the level of its nearest configured ancestor (e.g. "core" for "core.chain") or the global level.
+func (l *levels) level(module string) slog.Level {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	for m := module; m != ""; {
+		if level, ok := l.modules[m]; ok {
+			return level
+		}
+		i := strings.LastIndex(m, ".")
+		if i < 0 {
+			break
+		}
+		m = m[:i]
+	}
+	return l.global
+}
+
+// SetLevel changes the global log level at runtime
+func SetLevel(logLevel string) error {
+	level, err := parseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	logLevels.mutex.Lock()
+	defer logLevels.mutex.Unlock()
+	logLevels.global = level
+	return nil
+}
+
+// SetModuleLevels replaces the per-module log levels at runtime.
+// The keys of `moduleLevels` are module names given to `WithModule` (e.g. "core.chain") and the values are levels (e.g. "INFO").
+func SetModuleLevels(moduleLevels map[string]string) error {
+	modules := make(map[string]slog.Level)
+	for module, logLevel := range moduleLevels {
+		level, err := parseLevel(logLevel)
+		if err != nil {
+			return fmt.Errorf("invalid level of module %s: %v", module, err)
+		}
+		modules[module] = level
+	}
+	logLevels.mutex.Lock()
+	defer logLevels.mutex.Unlock()
+	logLevels.modules = modules
+	return nil
+}
+
+func parseLevel(logLevel string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+		return level, fmt.Errorf("failed to unmarshal level: %v", err)
+	}
+	return level, nil
+}
+
+// moduleLevelHandler is a slog.Handler that filters records by the level of the module set by `WithModule`
+type moduleLevelHandler struct {
+	slog.Handler
+	levels *levels
+	module string
+}
+
+var _ slog.Handler = (*moduleLevelHandler)(nil)
+
+func (h *moduleLevelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.levels.level(h.module)
+}
+
+func (h *moduleLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	module := h.module
+	for _, attr := range attrs {
+		if attr.Key == "module" {
+			module = attr.Value.String()
+		}
+	}
+	return &moduleLevelHandler{Handler: h.Handler.WithAttrs(attrs), levels: h.levels, module: module}
+}
+
+func (h *moduleLevelHandler) WithGroup(name string) slog.Handler {
+	return &moduleLevelHandler{Handler: h.Handler.WithGroup(name), levels: h.levels, module: h.module}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"os"
 	"time"
 	"runtime"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/errors/withstack"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type RelayLogger struct {
@@ -33,35 +35,40 @@ func InitLogger(logLevel, format, output string) error {
 
 func InitLoggerWithWriter(logLevel, format string, writer io.Writer) error {
 	// level
-	var slogLevel slog.Level
-	if err := slogLevel.UnmarshalText([]byte(logLevel)); err != nil {
-		return fmt.Errorf("failed to unmarshal level: %v", err)
+	slogLevel, err := parseLevel(logLevel)
+	if err != nil {
+		return err
 	}
+	// records are filtered by moduleLevelHandler, so the underlying handler accepts all levels
 	handlerOpts := &slog.HandlerOptions{
-		Level:     slogLevel,
+		Level:     slog.Level(math.MinInt),
 		AddSource: true,
 	}
 
-	var slogLogger *slog.Logger
+	var handler slog.Handler
 	// format
 	switch format {
 	case "text":
-		slogLogger = slog.New(slog.NewTextHandler(
+		handler = slog.NewTextHandler(
 			writer,
 			handlerOpts,
-		))
+		)
 	case "json":
-		slogLogger = slog.New(slog.NewJSONHandler(
+		handler = slog.NewJSONHandler(
 			writer,
 			handlerOpts,
-		))
+		)
 	default:
 		return errors.New("invalid log format")
 	}
 
+	logLevels.mutex.Lock()
+	logLevels.global = slogLevel
+	logLevels.mutex.Unlock()
+
 	// set global logger
 	relayLogger = &RelayLogger{
-		slogLogger,
+		slog.New(&moduleLevelHandler{Handler: handler, levels: logLevels}),
 	}
 	return nil
 }
@@ -211,6 +218,23 @@ func (rl *RelayLogger) WithModule(
 	}
 }
 
+// WithSpanContext returns a logger that records the trace ID and the span ID of the span in `ctx`.
+// If `ctx` has no valid span, the receiver is returned as it is.
+func (rl *RelayLogger) WithSpanContext(
+	ctx context.Context,
+) *RelayLogger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return rl
+	}
+	return &RelayLogger{
+		rl.Logger.With(
+			"trace_id", sc.TraceID().String(),
+			"span_id", sc.SpanID().String(),
+		),
+	}
+}
+
 func (rl *RelayLogger) TimeTrack(start time.Time, name string, otherArgs ...any) {
 	elapsed := time.Since(start)
 	allArgs := append([]any{"name", name, "elapsed", elapsed.Nanoseconds()}, otherArgs...)
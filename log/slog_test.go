@@ -8,6 +8,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"regexp"
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type setupType struct {
@@ -39,6 +45,8 @@ type logType struct {
 	Msg string
 	Stack string
 	Error string
+	TraceID string `json:"trace_id"`
+	SpanID string `json:"span_id"`
 }
 
 func parseResult(setup *setupType, t *testing.T) (string, logType) {
@@ -95,3 +103,108 @@ func TestLogError(t *testing.T) {
 		t.Fatalf("mismatch level: %s", raw)
 	}
 }
+
+func TestLogWithSpanContext(t *testing.T) {
+	setup := beforeEach(t)
+
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	setup.logger.WithSpanContext(ctx).Info("test")
+	raw, r := parseResult(setup, t)
+
+	if r.TraceID != traceID.String() {
+		t.Fatalf("mismatch trace_id: %s", raw)
+	}
+	if r.SpanID != spanID.String() {
+		t.Fatalf("mismatch span_id: %s", raw)
+	}
+
+	if setup.logger.WithSpanContext(context.Background()) != setup.logger {
+		t.Fatalf("logger without span context should be returned as it is")
+	}
+}
+
+func TestLogModuleLevel(t *testing.T) {
+	setup := beforeEach(t)
+	defer SetModuleLevels(nil)
+
+	if err := SetModuleLevels(map[string]string{"core": "WARN", "core.chain": "DEBUG"}); err != nil {
+		t.Fatal(err)
+	}
+
+	setup.logger.WithModule("core.channel").Info("test")
+	if 0 < setup.buffer.Len() {
+		t.Fatalf("info log of a module inheriting WARN is output: %s", setup.buffer.String())
+	}
+
+	setup.logger.WithModule("core.chain").Debug("test")
+	if raw, r := parseResult(setup, t); r.Level != "DEBUG" {
+		t.Fatalf("mismatch level: %s", raw)
+	}
+	setup.buffer.Reset()
+
+	// the level can be changed at runtime
+	if err := SetModuleLevels(map[string]string{"core": "INFO"}); err != nil {
+		t.Fatal(err)
+	}
+	setup.logger.WithModule("core.channel").Info("test")
+	if raw, r := parseResult(setup, t); r.Level != "INFO" {
+		t.Fatalf("mismatch level: %s", raw)
+	}
+	setup.buffer.Reset()
+
+	if err := SetLevel("ERROR"); err != nil {
+		t.Fatal(err)
+	}
+	setup.logger.WithModule("other").Info("test")
+	if 0 < setup.buffer.Len() {
+		t.Fatalf("info log is output with the global level ERROR: %s", setup.buffer.String())
+	}
+
+	if err := SetModuleLevels(map[string]string{"core": "VERBOSE"}); err == nil {
+		t.Fatal("invalid level should be rejected")
+	}
+}
+
+func TestFileLogger(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "relayer.log")
+
+	ticks := make(chan time.Time)
+	defer func(f func(time.Duration) (<-chan time.Time, func())) { newRotationTicker = f }(newRotationTicker)
+	newRotationTicker = func(time.Duration) (<-chan time.Time, func()) {
+		return ticks, func() {}
+	}
+
+	if err := InitFileLogger("info", "json", FileConfig{Path: path, RotationInterval: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	defer CloseFile()
+
+	GetLogger().Info("before rotation")
+	ticks <- time.Now()
+	// CloseFile waits for the rotation triggered by the tick
+	if err := CloseFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) < 2 {
+		t.Fatalf("log file is not rotated: %v", entries)
+	}
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(bz, []byte("before rotation")) {
+		t.Fatalf("the current log file is expected to be rotated: %s", bz)
+	}
+}
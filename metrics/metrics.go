@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/hyperledger-labs/yui-relayer/log"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	api "go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/sdk/metric"
@@ -25,6 +28,21 @@ var (
 	BacklogSizeGauge               *Int64SyncGauge
 	BacklogOldestTimestampGauge    *Int64SyncGauge
 	ReceivePacketsFinalizedCounter api.Int64Counter
+	TxsSubmittedCounter            api.Int64Counter
+	TxsSucceededCounter            api.Int64Counter
+	TxsFailedCounter               api.Int64Counter
+	GasUsedCounter                 api.Int64Counter
+	FeesPaidCounter                api.Int64Counter
+	FeesEarnedCounter              api.Int64Counter
+	ServeDurationHistogram         api.Float64Histogram
+	TimeToRelayHistogram           api.Float64Histogram
+	RPCCallsCounter                api.Int64Counter
+	RPCErrorsCounter               api.Int64Counter
+	AccountBalanceGauge            *Int64SyncGauge
+	QuarantinedPacketsGauge        *Int64SyncGauge
+	RedundantRelaysCounter         api.Int64Counter
+	TimeToTimeoutGauge             *Int64SyncGauge
+	DeferredMsgsCounter            api.Int64Counter
 )
 
 type ExporterConfig interface {
@@ -34,6 +52,7 @@ type ExporterConfig interface {
 var (
 	_ ExporterConfig = ExporterNull{}
 	_ ExporterConfig = ExporterProm{}
+	_ ExporterConfig = ExporterOTLP{}
 )
 
 type ExporterNull struct{}
@@ -46,6 +65,23 @@ type ExporterProm struct {
 
 func (e ExporterProm) exporterType() string { return "prometheus" }
 
+const (
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http"
+)
+
+// ExporterOTLP is the configuration of the exporter that periodically pushes metrics to an OTLP receiver
+// (e.g. OpenTelemetry Collector) over gRPC or HTTP.
+type ExporterOTLP struct {
+	Protocol string            // "grpc" or "http". If empty, "grpc" is used.
+	Endpoint string            // host:port of the receiver
+	Insecure bool              // if set, TLS is disabled
+	Headers  map[string]string // headers attached to each export request
+	Interval time.Duration     // interval between exports. If zero, the SDK default is used.
+}
+
+func (e ExporterOTLP) exporterType() string { return "otlp" }
+
 func InitializeMetrics(exporterConf ExporterConfig) error {
 	var err error
 
@@ -58,6 +94,16 @@ func InitializeMetrics(exporterConf ExporterConfig) error {
 		} else {
 			meterProvider = metric.NewMeterProvider(metric.WithReader(exporter))
 		}
+	case ExporterOTLP:
+		if exporter, err := NewOTLPExporter(context.TODO(), exporterConf); err != nil {
+			return err
+		} else {
+			var opts []metric.PeriodicReaderOption
+			if exporterConf.Interval > 0 {
+				opts = append(opts, metric.WithInterval(exporterConf.Interval))
+			}
+			meterProvider = metric.NewMeterProvider(metric.WithReader(metric.NewPeriodicReader(exporter, opts...)))
+		}
 	default:
 		panic("unexpected exporter type")
 	}
@@ -107,6 +153,159 @@ func InitializeMetrics(exporterConf ExporterConfig) error {
 		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
 	}
 
+	// create the instrument "relayer.txs_submitted"
+	name = fmt.Sprintf("%s.txs_submitted", namespaceRoot)
+	if TxsSubmittedCounter, err = meter.Int64Counter(
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of txs submitted (a tx including multiple msg types is counted for each type)"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.txs_succeeded"
+	name = fmt.Sprintf("%s.txs_succeeded", namespaceRoot)
+	if TxsSucceededCounter, err = meter.Int64Counter(
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of txs that are successfully committed (a tx including multiple msg types is counted for each type)"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.txs_failed"
+	name = fmt.Sprintf("%s.txs_failed", namespaceRoot)
+	if TxsFailedCounter, err = meter.Int64Counter(
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of txs that failed (a tx including multiple msg types is counted for each type)"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.gas_used"
+	name = fmt.Sprintf("%s.gas_used", namespaceRoot)
+	if GasUsedCounter, err = meter.Int64Counter(
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("amount of gas used by committed txs"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.fees_paid"
+	name = fmt.Sprintf("%s.fees_paid", namespaceRoot)
+	if FeesPaidCounter, err = meter.Int64Counter(
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("amount of fees paid for committed txs"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.fees_earned"
+	name = fmt.Sprintf("%s.fees_earned", namespaceRoot)
+	if FeesEarnedCounter, err = meter.Int64Counter(
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("amount of ICS-29 relayer fees earned by committed msgs"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.serve_duration"
+	name = fmt.Sprintf("%s.serve_duration", namespaceRoot)
+	if ServeDurationHistogram, err = meter.Float64Histogram(
+		name,
+		api.WithUnit("s"),
+		api.WithDescription("time taken by each cycle of the relay service"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.time_to_relay"
+	name = fmt.Sprintf("%s.time_to_relay", namespaceRoot)
+	if TimeToRelayHistogram, err = meter.Float64Histogram(
+		name,
+		api.WithUnit("s"),
+		api.WithDescription("time from when a packet was sent to when its recvPacket was committed"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.rpc_calls"
+	name = fmt.Sprintf("%s.rpc_calls", namespaceRoot)
+	if RPCCallsCounter, err = meter.Int64Counter(
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of RPC calls"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.rpc_errors"
+	name = fmt.Sprintf("%s.rpc_errors", namespaceRoot)
+	if RPCErrorsCounter, err = meter.Int64Counter(
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of RPC calls that returned an error"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.account_balance"
+	name = fmt.Sprintf("%s.account_balance", namespaceRoot)
+	if AccountBalanceGauge, err = NewInt64SyncGauge(
+		meter,
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("balance of the relayer account"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.quarantined_packets"
+	name = fmt.Sprintf("%s.quarantined_packets", namespaceRoot)
+	if QuarantinedPacketsGauge, err = NewInt64SyncGauge(
+		meter,
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of packets that are quarantined because their msgs were rejected by the chain"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.redundant_relays"
+	name = fmt.Sprintf("%s.redundant_relays", namespaceRoot)
+	if RedundantRelaysCounter, err = meter.Int64Counter(
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of packet msgs that were no-ops because the packets had already been relayed by another relayer"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.time_to_timeout"
+	name = fmt.Sprintf("%s.time_to_timeout", namespaceRoot)
+	if TimeToTimeoutGauge, err = NewInt64SyncGauge(
+		meter,
+		name,
+		api.WithUnit("s"),
+		api.WithDescription("time remaining until the most urgent unrelayed packet times out on the receiving chain"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
+	// create the instrument "relayer.deferred_msgs"
+	name = fmt.Sprintf("%s.deferred_msgs", namespaceRoot)
+	if DeferredMsgsCounter, err = meter.Int64Counter(
+		name,
+		api.WithUnit("1"),
+		api.WithDescription("number of msgs deferred because a submission limit of the chain was hit"),
+	); err != nil {
+		return fmt.Errorf("failed to create the instrument %s: %v", name, err)
+	}
+
 	return nil
 }
 
@@ -134,3 +333,36 @@ func NewPrometheusExporter(addr string) (*prometheus.Exporter, error) {
 
 	return exporter, nil
 }
+
+func NewOTLPExporter(ctx context.Context, conf ExporterOTLP) (metric.Exporter, error) {
+	switch conf.Protocol {
+	case OTLPProtocolGRPC, "":
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(conf.Endpoint)}
+		if conf.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		if len(conf.Headers) > 0 {
+			opts = append(opts, otlpmetricgrpc.WithHeaders(conf.Headers))
+		}
+		exporter, err := otlpmetricgrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the OTLP gRPC Exporter: %v", err)
+		}
+		return exporter, nil
+	case OTLPProtocolHTTP:
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(conf.Endpoint)}
+		if conf.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		if len(conf.Headers) > 0 {
+			opts = append(opts, otlpmetrichttp.WithHeaders(conf.Headers))
+		}
+		exporter, err := otlpmetrichttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the OTLP HTTP Exporter: %v", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol: %s", conf.Protocol)
+	}
+}
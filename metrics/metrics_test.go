@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpReceiver is an in-process OTLP receiver that records the names of received metrics and headers
+type otlpReceiver struct {
+	colmetricpb.UnimplementedMetricsServiceServer
+
+	mutex   sync.Mutex
+	names   map[string]bool
+	headers map[string]string
+}
+
+func newOTLPReceiver() *otlpReceiver {
+	return &otlpReceiver{names: make(map[string]bool), headers: make(map[string]string)}
+}
+
+func (r *otlpReceiver) record(req *colmetricpb.ExportMetricsServiceRequest, headers map[string]string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, rm := range req.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				r.names[m.Name] = true
+			}
+		}
+	}
+	for k, v := range headers {
+		r.headers[k] = v
+	}
+}
+
+func (r *otlpReceiver) Export(ctx context.Context, req *colmetricpb.ExportMetricsServiceRequest) (*colmetricpb.ExportMetricsServiceResponse, error) {
+	headers := make(map[string]string)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for k, vs := range md {
+			if len(vs) > 0 {
+				headers[k] = vs[0]
+			}
+		}
+	}
+	r.record(req, headers)
+	return &colmetricpb.ExportMetricsServiceResponse{}, nil
+}
+
+func (r *otlpReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	bz, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var exportReq colmetricpb.ExportMetricsServiceRequest
+	if err := proto.Unmarshal(bz, &exportReq); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	headers := make(map[string]string)
+	for k := range req.Header {
+		headers[http.CanonicalHeaderKey(k)] = req.Header.Get(k)
+	}
+	r.record(&exportReq, headers)
+
+	bz, err = proto.Marshal(&colmetricpb.ExportMetricsServiceResponse{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(bz)
+}
+
+func startGRPCReceiver(t *testing.T, r *otlpReceiver) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	colmetricpb.RegisterMetricsServiceServer(srv, r)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+func startHTTPReceiver(t *testing.T, r *otlpReceiver) string {
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv.Listener.Addr().String()
+}
+
+func TestOTLPExporter(t *testing.T) {
+	cases := []struct {
+		protocol  string
+		start     func(*testing.T, *otlpReceiver) string
+		headerKey string
+	}{
+		{OTLPProtocolGRPC, startGRPCReceiver, "x-test-header"},
+		{OTLPProtocolHTTP, startHTTPReceiver, "X-Test-Header"},
+		// gRPC is used by default
+		{"", startGRPCReceiver, "x-test-header"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.protocol, func(t *testing.T) {
+			receiver := newOTLPReceiver()
+			endpoint := c.start(t, receiver)
+
+			if err := InitializeMetrics(ExporterOTLP{
+				Protocol: c.protocol,
+				Endpoint: endpoint,
+				Insecure: true,
+				Headers:  map[string]string{"x-test-header": "test"},
+				Interval: time.Hour,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			ProcessedBlockHeightGauge.Set(100, attribute.Key("chain_id").String("ibc0"))
+			ReceivePacketsFinalizedCounter.Add(context.TODO(), 1)
+
+			// metrics are exported on shutdown even if the interval has not elapsed
+			if err := ShutdownMetrics(context.TODO()); err != nil {
+				t.Fatal(err)
+			}
+
+			receiver.mutex.Lock()
+			defer receiver.mutex.Unlock()
+			for _, name := range []string{
+				"relayer.processed_block_height",
+				"relayer.receive_packets_finalized",
+			} {
+				if !receiver.names[name] {
+					t.Errorf("metric %s was not exported: received=%v", name, receiver.names)
+				}
+			}
+			if v := receiver.headers[c.headerKey]; v != "test" {
+				t.Errorf("unexpected header value: actual=%q, expected=%q", v, "test")
+			}
+		})
+	}
+}
+
+func TestOTLPExporterInvalidProtocol(t *testing.T) {
+	if err := InitializeMetrics(ExporterOTLP{Protocol: "udp", Endpoint: "localhost:4317"}); err == nil {
+		t.Fatal("InitializeMetrics should fail with an unsupported protocol")
+	}
+}
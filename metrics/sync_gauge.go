@@ -45,3 +45,11 @@ func (g *Int64SyncGauge) Set(value int64, attr ...attribute.KeyValue) {
 	encodedAttrs := attrs.Encoded(attribute.DefaultEncoder())
 	g.attrsValueMap[encodedAttrs] = &Int64WithAttributes{value, attrs}
 }
+
+// Delete stops reporting the value for the attributes
+func (g *Int64SyncGauge) Delete(attr ...attribute.KeyValue) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	attrs := attribute.NewSet(attr...)
+	delete(g.attrsValueMap, attrs.Encoded(attribute.DefaultEncoder()))
+}
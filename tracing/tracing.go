@@ -0,0 +1,158 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/trace"
+	api "go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	tracerName = "github.com/hyperledger-labs/yui-relayer"
+)
+
+var (
+	tracerProvider *trace.TracerProvider
+	tracer         api.Tracer = noop.NewTracerProvider().Tracer(tracerName)
+
+	// file is the output of ExporterFile, which is closed on shutdown
+	file *os.File
+)
+
+type ExporterConfig interface {
+	exporterType() string
+}
+
+var (
+	_ ExporterConfig = ExporterNull{}
+	_ ExporterConfig = ExporterFile{}
+	_ ExporterConfig = ExporterOTLP{}
+)
+
+// ExporterNull is the configuration that creates spans without exporting them.
+// The trace and span IDs are still available for logging.
+type ExporterNull struct{}
+
+func (e ExporterNull) exporterType() string { return "null" }
+
+// ExporterFile is the configuration of the exporter that writes spans to a file in JSON format
+type ExporterFile struct {
+	Path string
+}
+
+func (e ExporterFile) exporterType() string { return "file" }
+
+const (
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http"
+)
+
+// ExporterOTLP is the configuration of the exporter that pushes spans to an OTLP receiver over gRPC or HTTP
+type ExporterOTLP struct {
+	Protocol string            // "grpc" or "http". If empty, "grpc" is used.
+	Endpoint string            // host:port of the receiver
+	Insecure bool              // if set, TLS is disabled
+	Headers  map[string]string // headers attached to each export request
+}
+
+func (e ExporterOTLP) exporterType() string { return "otlp" }
+
+func InitializeTracing(exporterConf ExporterConfig) error {
+	switch exporterConf := exporterConf.(type) {
+	case ExporterNull:
+		tracerProvider = trace.NewTracerProvider()
+	case ExporterFile:
+		f, err := os.OpenFile(exporterConf.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open the trace file: %v", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("failed to create the file exporter: %v", err)
+		}
+		file = f
+		tracerProvider = trace.NewTracerProvider(trace.WithBatcher(exporter))
+	case ExporterOTLP:
+		if exporter, err := NewOTLPExporter(context.TODO(), exporterConf); err != nil {
+			return err
+		} else {
+			tracerProvider = trace.NewTracerProvider(trace.WithBatcher(exporter))
+		}
+	default:
+		panic("unexpected exporter type")
+	}
+
+	tracer = tracerProvider.Tracer(tracerName)
+
+	return nil
+}
+
+func ShutdownTracing(ctx context.Context) error {
+	if err := tracerProvider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shutdown the TracerProvider: %v", err)
+	}
+	if file != nil {
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to close the trace file: %v", err)
+		}
+		file = nil
+	}
+	return nil
+}
+
+func NewOTLPExporter(ctx context.Context, conf ExporterOTLP) (trace.SpanExporter, error) {
+	switch conf.Protocol {
+	case OTLPProtocolGRPC, "":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(conf.Endpoint)}
+		if conf.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(conf.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(conf.Headers))
+		}
+		exporter, err := otlptracegrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the OTLP gRPC Exporter: %v", err)
+		}
+		return exporter, nil
+	case OTLPProtocolHTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(conf.Endpoint)}
+		if conf.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(conf.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(conf.Headers))
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the OTLP HTTP Exporter: %v", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol: %s", conf.Protocol)
+	}
+}
+
+// StartSpan starts a new span as a child of the span in `ctx` if it exists.
+// The returned context should be passed to the functions called within the span.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, api.Span) {
+	return tracer.Start(ctx, name, api.WithAttributes(attrs...))
+}
+
+// EndSpan records `err` to `span` if it is not nil and ends the span
+func EndSpan(span api.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
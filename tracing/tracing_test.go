@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.json")
+	if err := InitializeTracing(ExporterFile{Path: path}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := StartSpan(context.TODO(), "parent", attribute.String("chain_id", "ibc0"))
+	_, child := StartSpan(ctx, "child")
+	EndSpan(child, errors.New("dummy"))
+	EndSpan(parent, nil)
+
+	if err := ShutdownTracing(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type span struct {
+		Name        string
+		SpanContext struct {
+			TraceID string
+			SpanID  string
+		}
+		Parent struct {
+			SpanID string
+		}
+		Status struct {
+			Code string
+		}
+	}
+	spans := make(map[string]span)
+	dec := json.NewDecoder(strings.NewReader(string(bz)))
+	for dec.More() {
+		var s span
+		if err := dec.Decode(&s); err != nil {
+			t.Fatalf("failed to decode span: %v: %s", err, bz)
+		}
+		spans[s.Name] = s
+	}
+
+	p, ok := spans["parent"]
+	if !ok {
+		t.Fatalf("parent span not found: %s", bz)
+	}
+	c, ok := spans["child"]
+	if !ok {
+		t.Fatalf("child span not found: %s", bz)
+	}
+	if c.SpanContext.TraceID != p.SpanContext.TraceID || c.Parent.SpanID != p.SpanContext.SpanID {
+		t.Errorf("child span is not a child of parent span: %s", bz)
+	}
+	if c.Status.Code != "Error" {
+		t.Errorf("unexpected status of child span: %s", c.Status.Code)
+	}
+}